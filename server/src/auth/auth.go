@@ -0,0 +1,113 @@
+// Package auth implements the accounts of the players, whose passwords are kept hashed, and the signed session
+// tokens issued when a player logs in, which the servers verify when a connection is opened
+// The name of a player is taken from the token, so that nobody can play with the name of somebody else
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Errors returned by the accounts and by the tokens
+var (
+	ErrInvalidAccountName   = errors.New("Invalid name of the account")
+	ErrInvalidPassword      = errors.New("Invalid password")
+	ErrAccountAlreadyExists = errors.New("Account already exists")
+	ErrAccountNotFound      = errors.New("Account not found")
+	ErrInvalidCredentials   = errors.New("Invalid name or password")
+	ErrInvalidToken         = errors.New("Invalid session token")
+	ErrTokenExpired         = errors.New("Session token expired")
+	ErrNotAuthenticated     = errors.New("Not logged in")
+)
+
+const (
+	// MinPasswordLength is the minimum number of characters of a password
+	MinPasswordLength = 8
+	// maxPasswordLength is the maximum number of bytes of a password, since bcrypt ignores the following ones
+	maxPasswordLength = 72
+	// botNamePrefix is the prefix of the names of the bots, see scopone.AddBotToGame, which no account can have
+	botNamePrefix = "Bot "
+)
+
+// Account is the account of a player
+type Account struct {
+	Name         string    `json:"name"`
+	PasswordHash []byte    `json:"passwordHash"`
+	Created      time.Time `json:"created"`
+}
+
+// AccountStore keeps the accounts
+type AccountStore interface {
+	// AddAccount adds an account - it returns an error wrapping ErrAccountAlreadyExists if there is already an
+	// account with the same name
+	AddAccount(ctx context.Context, account Account) error
+	// Account returns the account with the name passed in - it returns an error wrapping ErrAccountNotFound if there
+	// is no such account
+	Account(ctx context.Context, name string) (Account, error)
+}
+
+// Accounts registers the accounts of the players and logs them in
+type Accounts struct {
+	store  AccountStore
+	tokens *Tokens
+}
+
+// NewAccounts returns the accounts kept in the store passed in, which log in with the tokens passed in
+func NewAccounts(store AccountStore, tokens *Tokens) *Accounts {
+	return &Accounts{store: store, tokens: tokens}
+}
+
+// Register creates the account of a player with the password passed in, which is kept hashed
+func (a *Accounts) Register(ctx context.Context, name string, password string) error {
+	if strings.TrimSpace(name) == "" || strings.HasPrefix(name, botNamePrefix) {
+		return fmt.Errorf("%w - \"%v\" can not be the name of an account", ErrInvalidAccountName, name)
+	}
+	if len(password) < MinPasswordLength || len(password) > maxPasswordLength {
+		return fmt.Errorf("%w - The password must have from %v to %v characters", ErrInvalidPassword,
+			MinPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return a.store.AddAccount(ctx, Account{Name: name, PasswordHash: hash, Created: time.Now()})
+}
+
+// Login checks the password of the account and returns a session token for the player of the account
+// If the name or the password are wrong an error wrapping ErrInvalidCredentials is returned, without telling which
+// one is wrong
+func (a *Accounts) Login(ctx context.Context, name string, password string) (token string, err error) {
+	account, err := a.store.Account(ctx, name)
+	if errors.Is(err, ErrAccountNotFound) {
+		return "", fmt.Errorf("%w - Login of \"%v\" failed", ErrInvalidCredentials, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	err = bcrypt.CompareHashAndPassword(account.PasswordHash, []byte(password))
+	if err != nil {
+		return "", fmt.Errorf("%w - Login of \"%v\" failed", ErrInvalidCredentials, name)
+	}
+	return a.tokens.Issue(name)
+}
+
+// Verify returns the name of the player of a session token issued by Login
+func (a *Accounts) Verify(token string) (name string, err error) {
+	return a.tokens.Verify(token)
+}
+
+// TokenFromRequest returns the session token sent with an http request, either in the query parameter "token",
+// since the browsers can not set the headers of the request opening a websocket, or as bearer token in the
+// Authorization header - it returns an empty string if there is no token
+func TokenFromRequest(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ctx is the context of the calls of the tests
+var ctx = context.Background()
+
+func newAccounts() *Accounts {
+	return NewAccounts(NewMemoryAccountStore(), NewTokens([]byte("secret"), time.Hour))
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	accounts := newAccounts()
+	if err := accounts.Register(ctx, "p1", "password1"); err != nil {
+		t.Fatalf("The account could not be registered: %v", err)
+	}
+	account, _ := accounts.store.Account(ctx, "p1")
+	if strings.Contains(string(account.PasswordHash), "password1") {
+		t.Errorf("The password should be kept hashed")
+	}
+	token, err := accounts.Login(ctx, "p1", "password1")
+	if err != nil {
+		t.Fatalf("The login failed: %v", err)
+	}
+	name, err := accounts.Verify(token)
+	if err != nil || name != "p1" {
+		t.Errorf("The token should be of p1 but is of %v with error %v", name, err)
+	}
+
+	if _, err := accounts.Login(ctx, "p1", "password2"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("A login with a wrong password should return ErrInvalidCredentials but returns %v", err)
+	}
+	if _, err := accounts.Login(ctx, "p2", "password1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("A login with an account which does not exist should return ErrInvalidCredentials but returns %v", err)
+	}
+	if err := accounts.Register(ctx, "p1", "password2"); !errors.Is(err, ErrAccountAlreadyExists) {
+		t.Errorf("Registering an account twice should return ErrAccountAlreadyExists but returns %v", err)
+	}
+}
+
+func TestInvalidAccounts(t *testing.T) {
+	accounts := newAccounts()
+	for _, name := range []string{"", " ", "Bot 1"} {
+		if err := accounts.Register(ctx, name, "password1"); !errors.Is(err, ErrInvalidAccountName) {
+			t.Errorf("Registering the account \"%v\" should return ErrInvalidAccountName but returns %v", name, err)
+		}
+	}
+	for _, password := range []string{"short", strings.Repeat("x", 73)} {
+		if err := accounts.Register(ctx, "p1", password); !errors.Is(err, ErrInvalidPassword) {
+			t.Errorf("Registering with password \"%v\" should return ErrInvalidPassword but returns %v", password, err)
+		}
+	}
+}
+
+func TestInvalidTokens(t *testing.T) {
+	tokens := NewTokens([]byte("secret"), time.Hour)
+	token, _ := tokens.Issue("p1")
+	payload, signature, _ := strings.Cut(token, ".")
+
+	forged, _ := NewTokens([]byte("another secret"), time.Hour).Issue("p2")
+	otherPayload, _, _ := strings.Cut(forged, ".")
+	for _, invalid := range []string{"", "no signature", forged, otherPayload + "." + signature, payload + ".x"} {
+		if _, err := tokens.Verify(invalid); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("The token \"%v\" should be invalid but the error is %v", invalid, err)
+		}
+	}
+
+	tokens.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := tokens.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("A token older than its lifetime should return ErrTokenExpired but returns %v", err)
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/osteria?token=abc", nil)
+	if token := TokenFromRequest(r); token != "abc" {
+		t.Errorf("The token of the query should be abc but is %v", token)
+	}
+	r = httptest.NewRequest("GET", "/osteria", nil)
+	r.Header.Set("Authorization", "Bearer def")
+	if token := TokenFromRequest(r); token != "def" {
+		t.Errorf("The token of the header should be def but is %v", token)
+	}
+	r = httptest.NewRequest("GET", "/osteria", nil)
+	if token := TokenFromRequest(r); token != "" {
+		t.Errorf("A request without token should have no token but has %v", token)
+	}
+}
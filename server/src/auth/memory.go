@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryAccountStore keeps the accounts in memory - they are lost when the program stops
+type MemoryAccountStore struct {
+	mu       sync.Mutex
+	accounts map[string]Account
+}
+
+// NewMemoryAccountStore returns a store with no accounts
+func NewMemoryAccountStore() *MemoryAccountStore {
+	return &MemoryAccountStore{accounts: make(map[string]Account)}
+}
+
+// AddAccount adds an account
+func (store *MemoryAccountStore) AddAccount(ctx context.Context, account Account) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, found := store.accounts[account.Name]; found {
+		return fmt.Errorf("%w - \"%v\"", ErrAccountAlreadyExists, account.Name)
+	}
+	store.accounts[account.Name] = account
+	return nil
+}
+
+// Account returns the account with the name passed in
+func (store *MemoryAccountStore) Account(ctx context.Context, name string) (Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	account, found := store.accounts[name]
+	if !found {
+		return account, fmt.Errorf("%w - \"%v\"", ErrAccountNotFound, name)
+	}
+	return account, nil
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTokenLifetime is the time a session token is valid
+const DefaultTokenLifetime = 24 * time.Hour
+
+// SecretEnvVar is the env var with the secret which signs the session tokens
+const SecretEnvVar = "AUTH_SECRET"
+
+// Tokens issues and verifies the session tokens, which carry the name of the player and when they expire and are
+// signed with HMAC-SHA256
+// A token is the payload and its signature, both encoded in base64 url encoding and separated by a dot
+type Tokens struct {
+	secret   []byte
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+// tokenPayload is what a token says
+type tokenPayload struct {
+	Name    string `json:"name"`
+	Expires int64  `json:"exp"`
+}
+
+// NewTokens returns the tokens signed with the secret passed in and valid for the lifetime passed in
+func NewTokens(secret []byte, lifetime time.Duration) *Tokens {
+	return &Tokens{secret: secret, lifetime: lifetime, now: time.Now}
+}
+
+// SecretFromEnv returns the secret of the env var SecretEnvVar - if it is not set a random secret is returned, so
+// that the tokens are valid only until the server is restarted, and false is returned
+func SecretFromEnv() ([]byte, bool) {
+	if secret := os.Getenv(SecretEnvVar); secret != "" {
+		return []byte(secret), true
+	}
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		log.Fatalf("The secret of the session tokens could not be generated: %v", err)
+	}
+	return secret, false
+}
+
+// Issue returns a new token for the player passed in
+func (t *Tokens) Issue(name string) (string, error) {
+	payload, err := json.Marshal(tokenPayload{Name: name, Expires: t.now().Add(t.lifetime).Unix()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.sign(encoded)), nil
+}
+
+// Verify checks the signature of a token and that it has not expired, and returns the name of its player
+func (t *Tokens) Verify(token string) (name string, err error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return "", fmt.Errorf("%w - The token has no signature", ErrInvalidToken)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, t.sign(encoded)) {
+		return "", fmt.Errorf("%w - The signature of the token is not valid", ErrInvalidToken)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w - %v", ErrInvalidToken, err)
+	}
+	var payload tokenPayload
+	err = json.Unmarshal(data, &payload)
+	if err != nil || payload.Name == "" {
+		return "", fmt.Errorf("%w - The token has no player", ErrInvalidToken)
+	}
+	if !t.now().Before(time.Unix(payload.Expires, 0)) {
+		return "", fmt.Errorf("%w - The token of %v has expired", ErrTokenExpired, payload.Name)
+	}
+	return payload.Name, nil
+}
+
+func (t *Tokens) sign(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"go-scopone/src/server/srvgorilla"
+	"go-scopone/src/store/storefile"
+)
+
+// defaultDataDir is the directory of the files of the store if the env var SCOPONE_DATA_DIR is not set
+const defaultDataDir = "scopone-data"
+
+func main() {
+	dir := os.Getenv("SCOPONE_DATA_DIR")
+	if dir == "" {
+		dir = defaultDataDir
+	}
+	fmt.Printf("Scopone with file store in directory %v started\n", dir)
+
+	store, err := storefile.Open(dir)
+	if err != nil {
+		log.Fatalf("The store in directory %v could not be opened: %v", dir, err)
+	}
+
+	srvgorilla.Start(store, store, store)
+}
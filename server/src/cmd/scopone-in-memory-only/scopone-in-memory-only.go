@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 
+	"go-scopone/src/auth"
 	"go-scopone/src/game-logic/scopone"
 	"go-scopone/src/server/srvgorilla"
 )
@@ -10,5 +11,5 @@ import (
 func main() {
 	fmt.Println("Scopone in memory (no database) started")
 
-	srvgorilla.Start(&scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	srvgorilla.Start(&scopone.DoNothingStore{}, &scopone.DoNothingStore{}, auth.NewMemoryAccountStore())
 }
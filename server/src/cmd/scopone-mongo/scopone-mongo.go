@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
+	"go-scopone/src/game-logic/scopone"
 	"go-scopone/src/server/srvgorilla"
+	"go-scopone/src/store/storeevents"
 	"go-scopone/src/store/storemongo"
 )
 
@@ -17,5 +20,13 @@ func main() {
 
 	store := storemongo.Connect(ctx)
 
-	srvgorilla.Start(store, store)
+	// the games are saved as events if the env var MONGO_EVENT_LOG is set to true, otherwise each game is saved
+	// as a whole document
+	var gameStore scopone.GameReadWriter = store
+	if os.Getenv("MONGO_EVENT_LOG") == "true" {
+		fmt.Println("Games saved as events")
+		gameStore = storeevents.New(store.EventLog(), storeevents.DefaultSnapshotInterval)
+	}
+
+	srvgorilla.Start(store, gameStore, store)
 }
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"go-scopone/src/server/srvgorilla"
+	"go-scopone/src/store/storesql"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	// SQL_DRIVER is either sqlite3 or postgres and SQL_DATA_SOURCE is the file of the SQLite db or the connection
+	// string of the PostgreSQL db
+	driver := os.Getenv("SQL_DRIVER")
+	if driver == "" {
+		driver = "sqlite3"
+	}
+	dataSource := os.Getenv("SQL_DATA_SOURCE")
+	if dataSource == "" {
+		dataSource = "scopone.db"
+	}
+	fmt.Printf("Scopone with %v store started\n", driver)
+
+	store, err := storesql.Open(driver, dataSource)
+	if err != nil {
+		log.Fatalf("The %v db could not be opened: %v", driver, err)
+	}
+	defer store.Close()
+
+	srvgorilla.Start(store, store, store)
+}
@@ -0,0 +1,230 @@
+// Package actor drives each game of the Osteria with its own goroutine, which executes the commands sent to the game
+// one at a time and tells what has happened in the game with domain events
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ErrGameStopped is returned when a command is sent to a game whose goroutine has been stopped, e.g. because the
+// game has been closed in the meantime
+var ErrGameStopped = errors.New("Game stopped")
+
+// Handler receives the events caused by a command
+// It is called by the goroutine of the game while the game is locked, so it can read the game of the event but it
+// must not send commands to the game
+type Handler func(event Event)
+
+// request is a command sent to a game together with its context, the handler of its events and the channel of its
+// result
+type request struct {
+	ctx     context.Context
+	command Command
+	handle  Handler
+	result  chan error
+}
+
+// Timers sends to a game the command of one of its timers, see Osteria.RunTimers
+type Timers func(gameName string, command Command)
+
+// Game is the actor of a game, i.e. the goroutine which executes the commands of the game
+type Game struct {
+	name     string
+	osteria  *scopone.Scopone
+	requests chan request
+	stop     chan struct{}
+	stopped  chan struct{}
+	// timers sends the commands of the timer of the turn, which is nil if the game has no timers
+	timers Timers
+	timer  *time.Timer
+	// warned is the end of the last turn whose player has been warned that the time to move is running out
+	warned time.Time
+}
+
+// newGame starts the actor of the game with the name passed in
+func newGame(osteria *scopone.Scopone, gameName string, timers Timers) *Game {
+	g := &Game{
+		name:     gameName,
+		osteria:  osteria,
+		requests: make(chan request),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		timers:   timers,
+	}
+	go g.run()
+	return g
+}
+
+// run executes the commands received by the game until the game is stopped - after each command the timer of the
+// turn is set again, since the command may have changed the turn
+func (g *Game) run() {
+	defer close(g.stopped)
+	defer g.stopTimer()
+	g.setTimer()
+	for {
+		select {
+		case req := <-g.requests:
+			req.result <- g.execute(req.ctx, req.command, req.handle)
+			g.setTimer()
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// setTimer sets the timer of the turn of the current player, if the game has timers and the turn has a time limit,
+// to send the TurnTimer command when the player has to be warned or, once the player has been warned, when the
+// turn expires
+func (g *Game) setTimer() {
+	g.stopTimer()
+	if g.timers == nil {
+		return
+	}
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return
+	}
+	_, warning, deadline, running := g.osteria.TurnTimer(game)
+	unlock()
+	if !running {
+		return
+	}
+	at := warning
+	if g.warned.Equal(deadline) {
+		at = deadline
+	}
+	g.timer = time.AfterFunc(time.Until(at), func() {
+		g.timers(g.name, TurnTimer{Deadline: deadline})
+	})
+}
+
+// stopTimer stops the timer of the turn, if any
+func (g *Game) stopTimer() {
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+}
+
+// Do sends a command to the game and waits for it to be executed - the events caused by the command are passed to
+// the handler, if any, before Do returns
+// A command which changes the game and then fails to save it returns an error wrapping ErrStoreFailure, and its
+// events are passed to the handler anyway
+// The context is passed to the store which saves the game - if it is done before the game accepts the command, the
+// command is not executed and the error of the context is returned
+func (g *Game) Do(ctx context.Context, command Command, handle Handler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	req := request{ctx: ctx, command: command, handle: handle, result: make(chan error, 1)}
+	select {
+	case g.requests <- req:
+	case <-g.stopped:
+		return fmt.Errorf("%w - Game %v does not accept commands any more", ErrGameStopped, g.name)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return <-req.result
+}
+
+// Stop stops the goroutine of the game after the command it is executing, if any
+func (g *Game) Stop() {
+	select {
+	case <-g.stop:
+	default:
+		close(g.stop)
+	}
+	<-g.stopped
+}
+
+// Osteria holds the actors of the games of an Osteria, each started the first time a command is sent to its game
+type Osteria struct {
+	osteria *scopone.Scopone
+	mu      sync.Mutex
+	games   map[string]*Game
+	timers  Timers
+}
+
+// New returns the actors of the games of the Osteria passed in
+func New(osteria *scopone.Scopone) *Osteria {
+	return &Osteria{osteria: osteria, games: make(map[string]*Game)}
+}
+
+// Do sends a command to the game with the name passed in and waits for it to be executed, see Game.Do
+// If there is no game with such name an error wrapping scopone.ErrGameNotFound is returned
+func (o *Osteria) Do(ctx context.Context, gameName string, command Command, handle Handler) error {
+	g := o.game(gameName)
+	err := g.Do(ctx, command, handle)
+	if errors.Is(err, ErrGameStopped) {
+		// the actor has been stopped after it has been found, so the command is sent to a new one
+		g = o.game(gameName)
+		err = g.Do(ctx, command, handle)
+	}
+	_, closing := command.(Close)
+	if errors.Is(err, scopone.ErrGameNotFound) || (closing && (err == nil || errors.Is(err, scopone.ErrStoreFailure))) {
+		// a game which is not in the Osteria or has been closed does not need its goroutine any more
+		o.remove(g)
+	}
+	return err
+}
+
+// RunTimers gives the games timers for the turns of the players, which send their commands with the function
+// passed in when a player has to be warned that the time to move is running out and when the turn expires - the
+// function has to send the command to the game with Do, with a handler which tells the players what has happened
+// The actors of the games of the Osteria are started, so that the turns of the games read from the store are
+// timed even if no command is sent to them
+// Without timers the turns expire only when a TurnTimer command is sent to the game, e.g. by a server which is not
+// running between the commands of the players
+func (o *Osteria) RunTimers(timers Timers) {
+	o.mu.Lock()
+	o.timers = timers
+	o.mu.Unlock()
+	unlock := o.osteria.LockOsteria()
+	names := make([]string, 0, len(o.osteria.Games))
+	for gName := range o.osteria.Games {
+		names = append(names, gName)
+	}
+	unlock()
+	for _, gName := range names {
+		o.game(gName)
+	}
+}
+
+// game returns the actor of the game, starting it if it is not running
+func (o *Osteria) game(gameName string) *Game {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	g, found := o.games[gameName]
+	if !found {
+		g = newGame(o.osteria, gameName, o.timers)
+		o.games[gameName] = g
+	}
+	return g
+}
+
+// remove stops the actor of the game and removes it
+func (o *Osteria) remove(g *Game) {
+	o.mu.Lock()
+	if o.games[g.name] == g {
+		delete(o.games, g.name)
+	}
+	o.mu.Unlock()
+	g.Stop()
+}
+
+// Stop stops the actors of all the games
+func (o *Osteria) Stop() {
+	o.mu.Lock()
+	games := o.games
+	o.games = make(map[string]*Game)
+	o.mu.Unlock()
+	for _, g := range games {
+		g.Stop()
+	}
+}
@@ -0,0 +1,505 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the commands of the tests
+var ctx = context.Background()
+
+// recorder records the events passed to its handler
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.events = append(r.events, e)
+}
+
+// count returns the number of events recorded of the same type of the event passed in
+func (r *recorder) count(of Event) int {
+	n := 0
+	for _, e := range r.events {
+		if fmt.Sprintf("%T", e) == fmt.Sprintf("%T", of) {
+			n++
+		}
+	}
+	return n
+}
+
+func newOsteria(t *testing.T, gName string) (*scopone.Scopone, *Osteria) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	if _, err := s.NewGame(ctx, gName, scopone.GameOptions{}); err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	o := New(s)
+	t.Cleanup(o.Stop)
+	return s, o
+}
+
+func TestJoinAndObserve(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	r := &recorder{}
+	for _, pName := range []string{"p1", "p2", "o1"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	for _, pName := range []string{"p1", "p2"} {
+		if err := o.Do(ctx, gName, Join{PlayerName: pName}, r.handle); err != nil {
+			t.Fatalf("Player %v could not join the game: %v", pName, err)
+		}
+	}
+	if err := o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, r.handle); err != nil {
+		t.Fatalf("The bot could not join the game: %v", err)
+	}
+	if err := o.Do(ctx, gName, Observe{PlayerName: "o1"}, r.handle); err != nil {
+		t.Fatalf("The observer could not observe the game: %v", err)
+	}
+	if len(r.events) != 4 {
+		t.Fatalf("There should be 4 events but there are %v", len(r.events))
+	}
+	if e := r.events[1].(PlayerJoined); e.PlayerName != "p2" || e.Bot || e.Game() != s.Games[gName] {
+		t.Errorf("The second event should be p2 joining the game but is %v", e)
+	}
+	if e := r.events[2].(PlayerJoined); !e.Bot {
+		t.Errorf("The third event should be the bot joining the game but is %v", e)
+	}
+	if e := r.events[3].(ObserverJoined); e.ObserverName != "o1" {
+		t.Errorf("The fourth event should be o1 observing the game but is %v", e)
+	}
+
+	err := o.Do(ctx, gName, Join{PlayerName: "p1"}, r.handle)
+	if !errors.Is(err, scopone.ErrPlayerAlreadyInGame) {
+		t.Errorf("A player joining twice should return ErrPlayerAlreadyInGame but returns %v", err)
+	}
+	if len(r.events) != 4 {
+		t.Errorf("A command which fails should not cause events but there are %v events", len(r.events))
+	}
+}
+
+func TestHandOfBots(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	for i := 0; i < 4; i++ {
+		if err := o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil); err != nil {
+			t.Fatalf("The bot could not join the game: %v", err)
+		}
+	}
+	r := &recorder{}
+	if err := o.Do(ctx, gName, NewHand{PlayerName: "Bot 1"}, r.handle); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	if _, started := r.events[0].(HandStarted); !started {
+		t.Errorf("The first event should be HandStarted but is %T", r.events[0])
+	}
+	if n := r.count(CardPlayed{}); n != 40 {
+		t.Errorf("The bots should play 40 cards but play %v", n)
+	}
+	closed, ok := r.events[len(r.events)-1].(HandClosed)
+	if !ok {
+		t.Fatalf("The last event should be HandClosed but is %T", r.events[len(r.events)-1])
+	}
+	scope := 0
+	for _, ts := range closed.Score {
+		scope = scope + len(ts.ScoreCard.Scope)
+	}
+	if n := r.count(ScopaMade{}); n != scope {
+		t.Errorf("There should be %v ScopaMade events as the Scope of the score but there are %v", scope, n)
+	}
+	if s.Games[gName].Hands[0].State != scopone.HandClosed {
+		t.Errorf("The hand should be closed but is %v", s.Games[gName].Hands[0].State)
+	}
+}
+
+func TestPlayCard(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	for i := 0; i < 3; i++ {
+		o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	}
+	o.Do(ctx, gName, Ready{PlayerName: "p1"}, nil)
+	var view scopone.HandPlayerView
+	r := &recorder{}
+	err := o.Do(ctx, gName, NewHand{PlayerName: "p1"}, func(e Event) {
+		r.handle(e)
+		if h, ok := e.(HandStarted); ok {
+			view = h.HandViews["p1"]
+		}
+	})
+	if err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	// the bots play until it is the turn of p1 again
+	for _, e := range r.events {
+		if c, ok := e.(CardPlayed); ok {
+			view = c.HandViews["p1"]
+		}
+	}
+	if view.CurrentPlayerName != "p1" {
+		t.Fatalf("It should be the turn of p1 but it is the turn of %v", view.CurrentPlayerName)
+	}
+
+	r = &recorder{}
+	move := view.LegalMoves[0]
+	err = o.Do(ctx, gName, PlayCard{PlayerName: "p1", CardPlayed: move.CardPlayed, CardsTaken: move.CardsTaken}, r.handle)
+	if err != nil {
+		t.Fatalf("The card could not be played: %v", err)
+	}
+	played := r.events[0].(CardPlayed)
+	if played.PlayerName != "p1" || played.CardPlayed != move.CardPlayed {
+		t.Errorf("The first event should be the card played by p1 but is %v", played)
+	}
+	if n := r.count(CardPlayed{}); n != 4 {
+		t.Errorf("The card of p1 and of the 3 bots should be played but %v cards are played", n)
+	}
+
+	r = &recorder{}
+	err = o.Do(ctx, gName, PlayCard{PlayerName: "p1", CardPlayed: move.CardPlayed, CardsTaken: move.CardsTaken}, r.handle)
+	if !errors.Is(err, scopone.ErrCardNotInHand) {
+		t.Errorf("Playing a card twice should return ErrCardNotInHand but returns %v", err)
+	}
+	if len(r.events) != 0 {
+		t.Errorf("A card play refused should not cause events but there are %v events", len(r.events))
+	}
+}
+
+func TestTeamsForming(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	for _, pName := range []string{"p1", "p2"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	r := &recorder{}
+	seat, team := 3, 0
+	o.Do(ctx, gName, Join{PlayerName: "p1", Seat: &seat}, r.handle)
+	o.Do(ctx, gName, Join{PlayerName: "p2", Team: &team}, r.handle)
+	if e := r.events[0].(PlayerJoined); e.Seat != 3 {
+		t.Errorf("p1 should sit in seat 3 but sits in seat %v", e.Seat)
+	}
+	if e := r.events[1].(PlayerJoined); e.Seat != 0 {
+		t.Errorf("p2 should sit in the first seat of the first team but sits in seat %v", e.Seat)
+	}
+	o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+
+	// p1 moves next to p2, the bot in that seat takes the seat of p1, and then p2 kicks out the other bot
+	r = &recorder{}
+	if err := o.Do(ctx, gName, SwapSeats{PlayerName: "p1", Seat: 1}, r.handle); err != nil {
+		t.Fatalf("p1 could not move to seat 1: %v", err)
+	}
+	if e := r.events[0].(SeatsChanged); e.Seats[0] != "p2" || e.Seats[1] != "p1" || e.Seats[3] == "" {
+		t.Errorf("p1 should sit next to p2 and the bot in seat 3 but the seats are %v", e.Seats)
+	}
+	if err := o.Do(ctx, gName, Kick{PlayerName: "p2", Seat: 2}, r.handle); err != nil {
+		t.Fatalf("p2 could not kick out the bot in seat 2: %v", err)
+	}
+	if e := r.events[1].(PlayerKicked); e.KickedBy != "p2" || e.Seats[2] != "" {
+		t.Errorf("The bot in seat 2 should be kicked out by p2 but the event is %v", e)
+	}
+	o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+
+	// the first hand starts only when p1 and p2 are both ready
+	r = &recorder{}
+	o.Do(ctx, gName, Ready{PlayerName: "p1"}, r.handle)
+	if err := o.Do(ctx, gName, NewHand{PlayerName: "p1"}, nil); !errors.Is(err, scopone.ErrPlayersNotReady) {
+		t.Errorf("The first hand should not start before all the players are ready but the error is %v", err)
+	}
+	o.Do(ctx, gName, Ready{PlayerName: "p2"}, r.handle)
+	if e := r.events[0].(PlayerReady); e.PlayerName != "p1" || e.AllReady {
+		t.Errorf("The first event should be p1 ready with p2 not ready yet but is %v", e)
+	}
+	if e := r.events[1].(PlayerReady); e.PlayerName != "p2" || !e.AllReady {
+		t.Errorf("The second event should be p2 ready with all the players ready but is %v", e)
+	}
+	if err := o.Do(ctx, gName, NewHand{PlayerName: "p1"}, nil); err != nil {
+		t.Errorf("The first hand should start when all the players are ready but the error is %v", err)
+	}
+	if err := o.Do(ctx, gName, SwapSeats{PlayerName: "p1", Seat: 0}, nil); !errors.Is(err, scopone.ErrGameStarted) {
+		t.Errorf("The seats should not change after the first hand but the error is %v", err)
+	}
+}
+
+func TestLeaveSuspendsGame(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	r := &recorder{}
+	if err := o.Do(ctx, gName, Leave{PlayerName: "p1"}, r.handle); err != nil {
+		t.Fatalf("The player could not leave: %v", err)
+	}
+	if e, ok := r.events[0].(GameSuspended); !ok || e.PlayerName != "p1" {
+		t.Errorf("The event should be the game suspended by p1 but is %v", r.events[0])
+	}
+}
+
+func TestLeaveSeatAndAbandon(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	for _, pName := range []string{"p1", "p2", "p3"} {
+		s.PlayerEnters(ctx, pName, "")
+		o.Do(ctx, gName, Join{PlayerName: pName}, nil)
+		o.Do(ctx, gName, Ready{PlayerName: pName}, nil)
+	}
+	o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	o.Do(ctx, gName, NewHand{PlayerName: "p1"}, nil)
+
+	// p3 leaves the seat and the game waits for a player who takes it
+	r := &recorder{}
+	if err := o.Do(ctx, gName, LeaveSeat{PlayerName: "p3"}, r.handle); err != nil {
+		t.Fatalf("p3 could not leave the seat: %v", err)
+	}
+	if e, ok := r.events[0].(SeatLeft); !ok || e.PlayerName != "p3" || !e.Suspended {
+		t.Errorf("The event should be the seat left by p3 with the game suspended but is %v", r.events[0])
+	}
+	r = &recorder{}
+	o.Do(ctx, gName, Join{PlayerName: "p3"}, r.handle)
+	if e := r.events[0].(PlayerJoined); e.HandViews == nil {
+		t.Errorf("p3 taking back the seat should get the views of the current hand")
+	}
+
+	// p1 and p2, the first team, abandon the game
+	r = &recorder{}
+	o.Do(ctx, gName, VoteToAbandon{PlayerName: "p1"}, r.handle)
+	if err := o.Do(ctx, gName, VoteToAbandon{PlayerName: "p2"}, r.handle); err != nil {
+		t.Fatalf("p2 could not vote to abandon the game: %v", err)
+	}
+	if e := r.events[0].(AbandonVoted); e.Abandoned {
+		t.Errorf("The game should not be abandoned after the vote of p1 only")
+	}
+	if e := r.events[1].(AbandonVoted); !e.Abandoned {
+		t.Errorf("The game should be abandoned after the vote of p1 and p2")
+	}
+	if e, ok := r.events[2].(GameFinished); !ok || e.AbandonedBy != "p1_p2" {
+		t.Errorf("The game should be finished abandoned by p1 and p2 but the event is %v", r.events[2])
+	}
+}
+
+func TestCloseStopsGame(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	g := o.game(gName)
+	r := &recorder{}
+	if err := o.Do(ctx, gName, Close{PlayerName: "p1"}, r.handle); err != nil {
+		t.Fatalf("The game could not be closed: %v", err)
+	}
+	if e, ok := r.events[0].(GameClosed); !ok || e.ClosedBy != "p1" {
+		t.Errorf("The event should be the game closed by p1 but is %v", r.events[0])
+	}
+	if err := g.Do(ctx, NewHand{PlayerName: "p1"}, nil); !errors.Is(err, ErrGameStopped) {
+		t.Errorf("A closed game should return ErrGameStopped but returns %v", err)
+	}
+	if _, running := o.games[gName]; running {
+		t.Errorf("The actor of a closed game should be removed")
+	}
+}
+
+func TestOnlyPlayersStartHandsAndClose(t *testing.T) {
+	gName := "game"
+	s, o := newOsteria(t, gName)
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	o.Do(ctx, gName, Ready{PlayerName: "p1"}, nil)
+	for i := 0; i < 3; i++ {
+		o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	}
+	s.PlayerEnters(ctx, "intruder", "")
+	if err := o.Do(ctx, gName, NewHand{PlayerName: "intruder"}, nil); !errors.Is(err, scopone.ErrPlayerNotPlaying) {
+		t.Errorf("A player who does not play the game should not start its hands but the error is %v", err)
+	}
+	if err := o.Do(ctx, gName, Close{PlayerName: "intruder"}, nil); !errors.Is(err, scopone.ErrPlayerNotPlaying) {
+		t.Errorf("A player who does not play the game should not close it but the error is %v", err)
+	}
+	if g := s.Games[gName]; len(g.Hands) != 0 || g.State == scopone.GameClosed {
+		t.Errorf("The game should be neither started nor closed but has %v hands and is %v", len(g.Hands), g.State)
+	}
+}
+
+func TestGameNotFound(t *testing.T) {
+	_, o := newOsteria(t, "game")
+	err := o.Do(ctx, "no game", NewHand{PlayerName: "p1"}, nil)
+	if !errors.Is(err, scopone.ErrGameNotFound) {
+		t.Errorf("A command for a game which does not exist should return ErrGameNotFound but returns %v", err)
+	}
+	if _, running := o.games["no game"]; running {
+		t.Errorf("The actor of a game which does not exist should be removed")
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, o := newOsteria(t, "game")
+	if err := o.Do(ctx, "game", nil, nil); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("An unknown command should return ErrUnknownCommand but returns %v", err)
+	}
+}
+
+func TestCancelledCommand(t *testing.T) {
+	s, o := newOsteria(t, "game")
+	s.PlayerEnters(ctx, "p1", "")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := o.Do(cancelled, "game", Join{PlayerName: "p1"}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("A command whose context is cancelled should return context.Canceled but returns %v", err)
+	}
+	if len(s.Games["game"].Players) != 0 {
+		t.Errorf("A command whose context is cancelled should not be executed")
+	}
+}
+
+// TestGamesInParallel plays many games of bots at the same time - to be run with the -race flag
+func TestGamesInParallel(t *testing.T) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	o := New(s)
+	defer o.Stop()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		s.NewGame(ctx, fmt.Sprintf("game %v", i), scopone.GameOptions{})
+	}
+	for i := 0; i < 8; i++ {
+		gName := fmt.Sprintf("game %v", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the hands are started on behalf of the last bot joining the game
+			var botName string
+			for j := 0; j < 4; j++ {
+				err := o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, func(e Event) {
+					botName = e.(PlayerJoined).PlayerName
+				})
+				if err != nil {
+					t.Errorf("The bot could not join game %v: %v", gName, err)
+					return
+				}
+			}
+			for h := 0; h < 2; h++ {
+				r := &recorder{}
+				if err := o.Do(ctx, gName, NewHand{PlayerName: botName}, r.handle); err != nil {
+					t.Errorf("The hand of game %v could not be started: %v", gName, err)
+					return
+				}
+				if n := r.count(HandClosed{}); n != 1 {
+					t.Errorf("The hand of game %v should be closed once but is closed %v times", gName, n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTurnTimers(t *testing.T) {
+	s, o := newOsteria(t, "game")
+	gName := "timed game"
+	if _, err := s.NewGame(ctx, gName, scopone.GameOptions{MoveTimeLimit: 1}); err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	events := make(chan Event, 100)
+	o.RunTimers(func(gameName string, command Command) {
+		o.Do(ctx, gameName, command, func(e Event) { events <- e })
+	})
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	for i := 0; i < 3; i++ {
+		o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	}
+	o.Do(ctx, gName, Ready{PlayerName: "p1"}, nil)
+	var view scopone.HandPlayerView
+	err := o.Do(ctx, gName, NewHand{PlayerName: "p1"}, func(e Event) {
+		if h, ok := e.(HandStarted); ok {
+			view = h.HandViews["p1"]
+		}
+	})
+	if err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	if view.CurrentPlayerName != "p1" || view.RemainingTime <= 0 || view.RemainingTime > 1000 {
+		t.Fatalf("It should be the turn of p1 with at most 1 second to move but the view is %v", view)
+	}
+
+	// p1 does not play and is first warned and then a card is played for p1
+	timeout := time.After(5 * time.Second)
+	next := func() Event {
+		select {
+		case e := <-events:
+			return e
+		case <-timeout:
+			t.Fatalf("The timers of the turn have not fired")
+			return nil
+		}
+	}
+	if e, ok := next().(TurnWarning); !ok || e.PlayerName != "p1" || e.RemainingTime > time.Second {
+		t.Errorf("The first event should be the warning of p1 but is %v", e)
+	}
+	if e, ok := next().(TurnTimedOut); !ok || e.PlayerName != "p1" {
+		t.Errorf("The second event should be the turn of p1 timed out but is %v", e)
+	}
+	if e, ok := next().(CardPlayed); !ok || e.PlayerName != "p1" {
+		t.Errorf("The third event should be the card played for p1 but is %v", e)
+	}
+}
+
+func TestTurnTimerCommand(t *testing.T) {
+	s, o := newOsteria(t, "game")
+	gName := "timed game"
+	s.NewGame(ctx, gName, scopone.GameOptions{MoveTimeLimit: 60})
+	s.PlayerEnters(ctx, "p1", "")
+	o.Do(ctx, gName, Join{PlayerName: "p1"}, nil)
+	for i := 0; i < 3; i++ {
+		o.Do(ctx, gName, JoinBot{Strategy: &bot.Heuristic{}}, nil)
+	}
+	o.Do(ctx, gName, Ready{PlayerName: "p1"}, nil)
+	o.Do(ctx, gName, NewHand{PlayerName: "p1"}, nil)
+	r := &recorder{}
+	// the turn has not expired, so nothing happens but the warning, which is given only once
+	for i := 0; i < 2; i++ {
+		if err := o.Do(ctx, gName, TurnTimer{}, r.handle); err != nil {
+			t.Fatalf("The timer of the turn returns an error %v", err)
+		}
+	}
+	if len(r.events) != 1 || r.count(TurnWarning{}) != 1 {
+		t.Errorf("There should be only the warning of p1 but the events are %v", r.events)
+	}
+	// a timer set for a turn which has ended does nothing
+	if err := o.Do(ctx, gName, TurnTimer{Deadline: time.Now()}, r.handle); err != nil || len(r.events) != 1 {
+		t.Errorf("The timer of a turn ended should do nothing but returns %v with events %v", err, r.events)
+	}
+}
+
+func TestJoinPrivateGame(t *testing.T) {
+	s, o := newOsteria(t, "game")
+	gName := "private game"
+	s.NewGame(ctx, gName, scopone.GameOptions{Password: "secret", Invited: []string{"p1"}})
+	for _, pName := range []string{"p1", "p2", "o1"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	r := &recorder{}
+	if err := o.Do(ctx, gName, Join{PlayerName: "p2", Password: "wrong"}, r.handle); !errors.Is(err, scopone.ErrNotInvited) {
+		t.Errorf("p2 with the wrong password should not join but the error is %v", err)
+	}
+	if err := o.Do(ctx, gName, Observe{PlayerName: "o1"}, r.handle); !errors.Is(err, scopone.ErrNotInvited) {
+		t.Errorf("o1 without the password should not observe but the error is %v", err)
+	}
+	if len(r.events) != 0 {
+		t.Errorf("The players not admitted should cause no events but there are %v", r.events)
+	}
+	if err := o.Do(ctx, gName, Join{PlayerName: "p1"}, r.handle); err != nil {
+		t.Errorf("p1 who is invited should join but the error is %v", err)
+	}
+	if err := o.Do(ctx, gName, Join{PlayerName: "p2", Password: "secret"}, r.handle); err != nil {
+		t.Errorf("p2 with the password should join but the error is %v", err)
+	}
+	if err := o.Do(ctx, gName, Observe{PlayerName: "o1", Password: "secret"}, r.handle); err != nil {
+		t.Errorf("o1 with the password should observe but the error is %v", err)
+	}
+}
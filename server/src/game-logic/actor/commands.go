@@ -0,0 +1,478 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ErrUnknownCommand is returned when a game receives a command it does not know how to execute
+var ErrUnknownCommand = errors.New("Unknown command")
+
+// Command is a request to do something in a game
+type Command interface {
+	isCommand()
+}
+
+// Join makes a player take a seat in the game - the player can choose the seat or the team, otherwise the player
+// takes the first seat free
+// A private game needs the Password unless the player is invited to it
+type Join struct {
+	PlayerName string
+	Seat       *int
+	Team       *int
+	Password   string
+}
+
+// JoinBot makes a bot, which plays with the strategy passed in, take a seat in the game
+type JoinBot struct {
+	Strategy scopone.BotStrategy
+}
+
+// Observe makes a player observe the game - a private game needs the Password unless the player is invited to it
+type Observe struct {
+	PlayerName string
+	Password   string
+}
+
+// SwapSeats moves a player to another seat while the teams are forming, swapping the seat with its player if it is
+// taken
+type SwapSeats struct {
+	PlayerName string
+	Seat       int
+}
+
+// Kick makes a seat free again while the teams are forming, sending its player out of the game
+type Kick struct {
+	PlayerName string
+	Seat       int
+}
+
+// Ready confirms that a player is ready to start the game with the teams as they are
+type Ready struct {
+	PlayerName string
+}
+
+// LeaveSeat makes a player leave the seat of the game for good, without closing the game for the other players
+type LeaveSeat struct {
+	PlayerName string
+}
+
+// VoteToAbandon records the vote of a player to abandon the game - when the whole team has voted the team loses the
+// game by forfeit
+type VoteToAbandon struct {
+	PlayerName string
+}
+
+// NewHand starts a new hand of the game on behalf of one of its players
+type NewHand struct {
+	PlayerName string
+}
+
+// PlayCard plays a card of a player with the cards taken from the table
+type PlayCard struct {
+	PlayerName string
+	CardPlayed deck.Card
+	CardsTaken []deck.Card
+}
+
+// Close closes the game
+type Close struct {
+	PlayerName string
+}
+
+// Leave is sent when a player playing the game leaves the Osteria, which suspends the game
+type Leave struct {
+	PlayerName string
+}
+
+// TurnTimer warns the current player that the time to move is running out or, if the turn has expired, plays a card
+// for the player - it is sent by the timers of the game, see Osteria.RunTimers, or by a server without timers when
+// a player asks for it
+// Deadline is the end of the turn the timer has been set for, and if the turn has changed in the meantime nothing
+// happens - if it is zero the turn of the current player is checked
+type TurnTimer struct {
+	Deadline time.Time
+}
+
+func (Join) isCommand()          {}
+func (JoinBot) isCommand()       {}
+func (Observe) isCommand()       {}
+func (SwapSeats) isCommand()     {}
+func (Kick) isCommand()          {}
+func (Ready) isCommand()         {}
+func (LeaveSeat) isCommand()     {}
+func (VoteToAbandon) isCommand() {}
+func (NewHand) isCommand()       {}
+func (PlayCard) isCommand()      {}
+func (Close) isCommand()         {}
+func (Leave) isCommand()         {}
+func (TurnTimer) isCommand()     {}
+
+// execute executes a command holding the lock it needs, see scopone.LockOsteria and scopone.LockGame, and passes
+// its events to the handler
+func (g *Game) execute(ctx context.Context, command Command, handle Handler) error {
+	if handle == nil {
+		handle = func(Event) {}
+	}
+	switch c := command.(type) {
+	case Join:
+		return g.join(ctx, c, handle)
+	case JoinBot:
+		return g.joinBot(ctx, c, handle)
+	case Observe:
+		return g.observe(ctx, c, handle)
+	case SwapSeats:
+		return g.swapSeats(ctx, c, handle)
+	case Kick:
+		return g.kick(ctx, c, handle)
+	case Ready:
+		return g.ready(ctx, c, handle)
+	case LeaveSeat:
+		return g.leaveSeat(ctx, c, handle)
+	case VoteToAbandon:
+		return g.voteToAbandon(ctx, c, handle)
+	case NewHand:
+		return g.newHand(ctx, c, handle)
+	case PlayCard:
+		return g.playCard(ctx, c, handle)
+	case Close:
+		return g.close(ctx, c, handle)
+	case Leave:
+		return g.leave(ctx, c, handle)
+	case TurnTimer:
+		return g.turnTimer(ctx, c, handle)
+	default:
+		return fmt.Errorf("%w - Game %v can not execute %T", ErrUnknownCommand, g.name, command)
+	}
+}
+
+// failed returns true if the command has failed without changing the game - a command which has changed the game
+// but has not been able to save it has not failed
+func failed(err error) bool {
+	return err != nil && !errors.Is(err, scopone.ErrStoreFailure)
+}
+
+func (g *Game) join(ctx context.Context, c Join, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	if err := admitted(g.osteria.Games[g.name], c.PlayerName, c.Password); err != nil {
+		return err
+	}
+	seated := seatedPlayers(g.osteria.Games[g.name])
+	seat, err := chosenSeat(g.osteria.Games[g.name], c)
+	if err != nil {
+		return err
+	}
+	rejoined := g.osteria.Games[g.name] != nil && g.osteria.Games[g.name].SeatsLeft[c.PlayerName]
+	err = g.osteria.AddPlayerToSeat(ctx, c.PlayerName, g.name, seat)
+	if failed(err) {
+		return err
+	}
+	game := g.osteria.Games[g.name]
+	token, _ := g.osteria.ReconnectToken(c.PlayerName)
+	seat, _ = game.SeatOf(c.PlayerName)
+	joined := g.seatTaken(PlayerJoined{event: event{game}, PlayerName: c.PlayerName, Seat: seat, ReconnectToken: token},
+		seated)
+	if rejoined && !game.SeatsLeft[c.PlayerName] {
+		// the player has taken back the seat left and goes on with its cards
+		joined.HandViews = g.osteria.CurrentHandViews(game)
+	}
+	handle(joined)
+	return err
+}
+
+// admitted checks that the game admits the player with the password passed in - the game is nil if it does not
+// exist, and the command fails then as any other command of a game not found
+func admitted(game *scopone.Game, playerName string, password string) error {
+	if game == nil {
+		return nil
+	}
+	return game.Admits(playerName, password)
+}
+
+// chosenSeat returns the seat chosen by a player joining the game, the first seat free of the team if the player
+// has chosen only the team, or AnySeat - the game is nil if it does not exist
+func chosenSeat(game *scopone.Game, c Join) (int, error) {
+	switch {
+	case c.Seat != nil:
+		return *c.Seat, nil
+	case c.Team != nil && game != nil:
+		return scopone.FreeSeatOfTeam(game, *c.Team)
+	default:
+		return scopone.AnySeat, nil
+	}
+}
+
+func (g *Game) joinBot(ctx context.Context, c JoinBot, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	seated := seatedPlayers(g.osteria.Games[g.name])
+	botName, err := g.osteria.AddBotToGame(ctx, g.name, c.Strategy)
+	if failed(err) {
+		return err
+	}
+	game := g.osteria.Games[g.name]
+	seat, _ := game.SeatOf(botName)
+	handle(g.seatTaken(PlayerJoined{event: event{game}, PlayerName: botName, Bot: true, Seat: seat}, seated))
+	return err
+}
+
+// seatedPlayers returns the names of the players of the game - the game is nil if it does not exist
+func seatedPlayers(game *scopone.Game) []string {
+	names := make([]string, 0)
+	if game == nil {
+		return names
+	}
+	for pName := range game.Players {
+		names = append(names, pName)
+	}
+	return names
+}
+
+// seatTaken completes the event of a player who has joined the game with the player replaced, if among the players
+// seated before one is no longer in the game, and with the views of the current hand the player goes on with
+func (g *Game) seatTaken(e PlayerJoined, seated []string) PlayerJoined {
+	for _, pName := range seated {
+		if _, found := e.game.Players[pName]; !found {
+			e.Replaced = pName
+			e.HandViews = g.osteria.CurrentHandViews(e.game)
+			return e
+		}
+	}
+	return e
+}
+
+func (g *Game) observe(ctx context.Context, c Observe, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	if err := admitted(g.osteria.Games[g.name], c.PlayerName, c.Password); err != nil {
+		return err
+	}
+	handViews, err := g.osteria.AddObserverToGame(ctx, c.PlayerName, g.name)
+	if failed(err) {
+		return err
+	}
+	handle(ObserverJoined{event: event{g.osteria.Games[g.name]}, ObserverName: c.PlayerName, HandViews: handViews})
+	return err
+}
+
+func (g *Game) swapSeats(ctx context.Context, c SwapSeats, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = g.osteria.SwapSeats(ctx, g.name, c.PlayerName, c.Seat)
+	if failed(err) {
+		return err
+	}
+	handle(SeatsChanged{event: event{game}, PlayerName: c.PlayerName, Seats: game.Seats()})
+	return err
+}
+
+// kick holds the lock of the Osteria since the player kicked out goes back to the Osteria, or leaves it if it is a
+// bot
+func (g *Game) kick(ctx context.Context, c Kick, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	kicked, err := g.osteria.KickFromSeat(ctx, g.name, c.PlayerName, c.Seat)
+	if failed(err) {
+		return err
+	}
+	game := g.osteria.Games[g.name]
+	handle(PlayerKicked{event: event{game}, PlayerName: kicked, KickedBy: c.PlayerName, Seats: game.Seats()})
+	return err
+}
+
+func (g *Game) ready(ctx context.Context, c Ready, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = g.osteria.PlayerReady(ctx, c.PlayerName, g.name)
+	if failed(err) {
+		return err
+	}
+	handle(PlayerReady{event: event{game}, PlayerName: c.PlayerName, AllReady: game.State == scopone.GameOpen})
+	return err
+}
+
+// leaveSeat holds the lock of the Osteria since the player who leaves the seat goes back to the Osteria
+func (g *Game) leaveSeat(ctx context.Context, c LeaveSeat, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	err := g.osteria.LeaveSeat(ctx, g.name, c.PlayerName)
+	if failed(err) {
+		return err
+	}
+	game := g.osteria.Games[g.name]
+	handle(SeatLeft{event: event{game}, PlayerName: c.PlayerName, Seats: game.Seats(),
+		Suspended: game.State == scopone.GameSuspended})
+	return err
+}
+
+func (g *Game) voteToAbandon(ctx context.Context, c VoteToAbandon, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	abandoned, err := g.osteria.VoteToAbandon(ctx, g.name, c.PlayerName)
+	if failed(err) {
+		return err
+	}
+	handle(AbandonVoted{event: event{game}, PlayerName: c.PlayerName, Abandoned: abandoned})
+	if abandoned {
+		handle(GameFinished{event: event{game}, Winners: game.Winners, AbandonedBy: game.AbandonedBy})
+	}
+	return err
+}
+
+func (g *Game) newHand(ctx context.Context, c NewHand, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := game.CheckPlaying(c.PlayerName); err != nil {
+		return err
+	}
+	_, handViews, err := g.osteria.NewHand(ctx, game)
+	if failed(err) {
+		return err
+	}
+	handle(HandStarted{event: event{game}, HandViews: handViews})
+	g.playBots(ctx, game, handle)
+	return err
+}
+
+func (g *Game) playCard(ctx context.Context, c PlayCard, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	scopeBefore := scopeCount(game, c.PlayerName)
+	handViews, finalTableTake, err := g.osteria.Play(ctx, game, c.PlayerName, c.CardPlayed, c.CardsTaken)
+	if failed(err) {
+		return err
+	}
+	cardPlayed(game, c.PlayerName, scopone.Move{CardPlayed: c.CardPlayed, CardsTaken: c.CardsTaken}, handViews,
+		finalTableTake, scopeBefore, handle)
+	g.playBots(ctx, game, handle)
+	return err
+}
+
+// playBots makes the bots of the game play as long as it is the turn of one of them - the bots which can not save
+// the game after their card is played go on playing and the failure is only logged, since no player has sent them
+// a command to be answered
+func (g *Game) playBots(ctx context.Context, game *scopone.Game, handle Handler) {
+	for g.osteria.IsBotTurn(game) {
+		hand := game.Hands[len(game.Hands)-1]
+		scopeBefore := scopeCount(game, hand.CurrentPlayer.Name)
+		botName, move, handViews, finalTableTake, err := g.osteria.PlayBot(ctx, game)
+		if failed(err) {
+			log.Printf("Bot %v of game %v could not play: %v", botName, game.Name, err)
+			return
+		}
+		cardPlayed(game, botName, move, handViews, finalTableTake, scopeBefore, handle)
+		if err != nil {
+			log.Printf("The card played by bot %v could not be saved: %v", botName, err)
+		}
+	}
+}
+
+// cardPlayed passes to the handler the events of a card played
+func cardPlayed(game *scopone.Game, pName string, move scopone.Move, handViews map[string]scopone.HandPlayerView,
+	finalTableTake scopone.FinalTableTake, scopeBefore int, handle Handler) {
+	handle(CardPlayed{
+		event:          event{game},
+		PlayerName:     pName,
+		CardPlayed:     move.CardPlayed,
+		CardsTaken:     move.CardsTaken,
+		FinalTableTake: finalTableTake,
+		HandViews:      handViews,
+	})
+	if scopeCount(game, pName) > scopeBefore {
+		handle(ScopaMade{event: event{game}, PlayerName: pName, Card: move.CardPlayed})
+	}
+	hand := game.Hands[len(game.Hands)-1]
+	if hand.State == scopone.HandClosed {
+		handle(HandClosed{event: event{game}, Score: hand.Score})
+	}
+	if game.State == scopone.GameFinished {
+		handle(GameFinished{event: event{game}, Winners: game.Winners})
+	}
+}
+
+// scopeCount returns the number of Scope made by the team of the player in the current hand
+func scopeCount(game *scopone.Game, pName string) int {
+	for _, t := range game.Teams {
+		for _, p := range t.Players {
+			if p != nil && p.Name == pName {
+				return len(t.ScopeDiScopone)
+			}
+		}
+	}
+	return 0
+}
+
+func (g *Game) turnTimer(ctx context.Context, c TurnTimer, handle Handler) error {
+	game, unlock, err := g.osteria.LockGame(g.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	pName, _, deadline, running := g.osteria.TurnTimer(game)
+	if !running || (!c.Deadline.IsZero() && !c.Deadline.Equal(deadline)) {
+		// the card has been played before the timer has fired
+		return nil
+	}
+	scopeBefore := scopeCount(game, pName)
+	_, move, handViews, finalTableTake, err := g.osteria.PlayTimedOutTurn(ctx, game)
+	if errors.Is(err, scopone.ErrTurnNotExpired) {
+		if !g.warned.Equal(deadline) {
+			g.warned = deadline
+			handle(TurnWarning{event: event{game}, PlayerName: pName, RemainingTime: time.Until(deadline)})
+		}
+		return nil
+	}
+	if failed(err) {
+		return err
+	}
+	handle(TurnTimedOut{event: event{game}, PlayerName: pName})
+	cardPlayed(game, pName, move, handViews, finalTableTake, scopeBefore, handle)
+	g.playBots(ctx, game, handle)
+	return err
+}
+
+func (g *Game) close(ctx context.Context, c Close, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	err := g.osteria.Close(ctx, g.name, c.PlayerName)
+	if failed(err) {
+		return err
+	}
+	handle(GameClosed{event: event{g.osteria.Games[g.name]}, ClosedBy: c.PlayerName})
+	return err
+}
+
+func (g *Game) leave(ctx context.Context, c Leave, handle Handler) error {
+	unlock := g.osteria.LockOsteria()
+	defer unlock()
+	_, wasPlaying, err := g.osteria.RemovePlayer(c.PlayerName)
+	if err != nil {
+		return err
+	}
+	if wasPlaying {
+		handle(GameSuspended{event: event{g.osteria.Games[g.name]}, PlayerName: c.PlayerName})
+	}
+	return nil
+}
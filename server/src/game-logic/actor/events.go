@@ -0,0 +1,154 @@
+package actor
+
+import (
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Event is something which has happened in a game because of a command
+type Event interface {
+	// Game returns the game where the event has happened - it can be read only while the event is handled
+	Game() *scopone.Game
+}
+
+// event holds what is common to all the events
+type event struct {
+	game *scopone.Game
+}
+
+// Game returns the game where the event has happened
+func (e event) Game() *scopone.Game {
+	return e.game
+}
+
+// PlayerJoined is the event of a player, or of a bot, who has taken a seat in the game
+type PlayerJoined struct {
+	event
+	PlayerName string
+	Bot        bool
+	// Seat is the seat taken, see scopone.Game.Seats
+	Seat int
+	// Replaced is the player who had left the seat taken, if the seat was left for longer than the grace period
+	Replaced string
+	// ReconnectToken is the token the player has to present to resume the game - it is sent only to the player
+	ReconnectToken string
+	// HandViews are the views of the current hand, if any, when a seat has been taken, since the player goes on
+	// with the cards of the seat
+	HandViews map[string]scopone.HandPlayerView
+}
+
+// SeatsChanged is the event of a player who has moved to another seat while the teams are forming - Seats are the
+// names of the players in the order of their seats, see scopone.Game.Seats
+type SeatsChanged struct {
+	event
+	PlayerName string
+	Seats      []string
+}
+
+// PlayerKicked is the event of a player, or of a bot, sent out of the seat by another player of the game while the
+// teams are forming
+type PlayerKicked struct {
+	event
+	PlayerName string
+	KickedBy   string
+	Seats      []string
+}
+
+// PlayerReady is the event of a player who has confirmed to be ready to start the game - AllReady is true when all
+// the players are ready and the first hand can be dealt
+type PlayerReady struct {
+	event
+	PlayerName string
+	AllReady   bool
+}
+
+// SeatLeft is the event of a player who has left the seat of the game for good - Suspended is true if the game waits
+// for a player who takes the seat, since its first hand has already been dealt
+type SeatLeft struct {
+	event
+	PlayerName string
+	Seats      []string
+	Suspended  bool
+}
+
+// AbandonVoted is the event of a player who has voted to abandon the game - Abandoned is true when the whole team has
+// voted and the game is finished, which is followed by the GameFinished event
+type AbandonVoted struct {
+	event
+	PlayerName string
+	Abandoned  bool
+}
+
+// ObserverJoined is the event of a player who has started to observe the game - the hand views are the ones of the
+// current hand of the game, if any
+type ObserverJoined struct {
+	event
+	ObserverName string
+	HandViews    map[string]scopone.HandPlayerView
+}
+
+// HandStarted is the event of a new hand dealt to the players
+type HandStarted struct {
+	event
+	HandViews map[string]scopone.HandPlayerView
+}
+
+// CardPlayed is the event of a card played by a player, or by a bot, with the cards taken from the table
+type CardPlayed struct {
+	event
+	PlayerName     string
+	CardPlayed     deck.Card
+	CardsTaken     []deck.Card
+	FinalTableTake scopone.FinalTableTake
+	HandViews      map[string]scopone.HandPlayerView
+}
+
+// ScopaMade is the event of a card which has taken all the cards from the table making a Scopa - it follows the
+// CardPlayed event of the same card
+type ScopaMade struct {
+	event
+	PlayerName string
+	Card       deck.Card
+}
+
+// HandClosed is the event of the last card of a hand played - the score has the names of the teams as keys
+type HandClosed struct {
+	event
+	Score map[string]scopone.TeamScore
+}
+
+// GameFinished is the event of a team which has reached the target score and won the game, or which has won it by
+// forfeit since the team AbandonedBy has abandoned it
+type GameFinished struct {
+	event
+	Winners     []string
+	AbandonedBy string
+}
+
+// TurnWarning is the event of the current player whose time to move is running out
+type TurnWarning struct {
+	event
+	PlayerName    string
+	RemainingTime time.Duration
+}
+
+// TurnTimedOut is the event of the current player whose time to move has expired - it is followed by the
+// CardPlayed event of the card played for the player
+type TurnTimedOut struct {
+	event
+	PlayerName string
+}
+
+// GameSuspended is the event of a player who has left the Osteria while playing the game
+type GameSuspended struct {
+	event
+	PlayerName string
+}
+
+// GameClosed is the event of a player who has closed the game
+type GameClosed struct {
+	event
+	ClosedBy string
+}
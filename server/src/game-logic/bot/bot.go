@@ -0,0 +1,55 @@
+// Package bot implements the strategies of the players controlled by the computer
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Names of the strategies available for the bots
+const (
+	RandomStrategy    = "random"
+	HeuristicStrategy = "heuristic"
+)
+
+// ErrUnknownStrategy is returned when a bot is requested with a strategy which does not exist
+var ErrUnknownStrategy = errors.New("Unknown bot strategy")
+
+// New returns the strategy with the name passed in - if the name is empty the heuristic strategy is returned
+func New(name string) (scopone.BotStrategy, error) {
+	switch name {
+	case RandomStrategy:
+		return NewRandom(time.Now().UnixNano()), nil
+	case HeuristicStrategy, "":
+		return &Heuristic{}, nil
+	case MonteCarloStrategy:
+		return NewMonteCarlo(time.Now().UnixNano(), defaultSimulations, defaultTimeLimit), nil
+	default:
+		return nil, fmt.Errorf("%w - \"%v\"", ErrUnknownStrategy, name)
+	}
+}
+
+// RestoreBots gives back their strategies to the bots of the games read from a store and sets them as playing,
+// since a bot, differently from a real player, never leaves the Osteria
+// Only the name of its strategy is stored with a bot, so a new instance of the strategy is created
+func RestoreBots(s *scopone.Scopone) {
+	for _, p := range s.Players {
+		if p.Bot == "" {
+			continue
+		}
+		if _, found := s.Bots[p.Name]; !found {
+			strategy, err := New(p.Bot)
+			if err != nil {
+				log.Printf("Bot %v can not be restored: %v", p.Name, err)
+				continue
+			}
+			s.Bots[p.Name] = strategy
+		}
+		p.Status = player.PlayerPlaying
+	}
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the commands of the tests
+var ctx = context.Background()
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{RandomStrategy, HeuristicStrategy, MonteCarloStrategy} {
+		strategy, err := New(name)
+		if err != nil || strategy.Name() != name {
+			t.Errorf("The strategy %v should be returned but %v is returned - error %v", name, strategy, err)
+		}
+	}
+	_, err := New("cheater")
+	if !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("An unknown strategy should return ErrUnknownStrategy but returns %v", err)
+	}
+}
+
+func TestHeuristicTakesSettebello(t *testing.T) {
+	view := scopone.HandPlayerView{
+		Table: []deck.Card{{Type: "Seven", Suit: deck.Denari}, {Type: "Seven", Suit: "Coppe"}},
+		LegalMoves: []scopone.Move{
+			{CardPlayed: deck.Card{Type: "Seven", Suit: "Spade"}, CardsTaken: []deck.Card{{Type: "Seven", Suit: "Coppe"}}},
+			{CardPlayed: deck.Card{Type: "Seven", Suit: "Spade"}, CardsTaken: []deck.Card{{Type: "Seven", Suit: deck.Denari}}},
+		},
+	}
+	move := (&Heuristic{}).ChooseMove(view)
+	if move.CardsTaken[0] != settebello {
+		t.Errorf("The heuristic strategy should take the Settebello but takes %v", move.CardsTaken)
+	}
+}
+
+func TestHeuristicDoesNotLeaveScopa(t *testing.T) {
+	view := scopone.HandPlayerView{
+		Table: []deck.Card{{Type: "Two", Suit: "Coppe"}},
+		LegalMoves: []scopone.Move{
+			{CardPlayed: deck.Card{Type: "Three", Suit: "Spade"}, CardsTaken: []deck.Card{}},
+			{CardPlayed: deck.Card{Type: "King", Suit: "Bastoni"}, CardsTaken: []deck.Card{}},
+		},
+	}
+	move := (&Heuristic{}).ChooseMove(view)
+	if move.CardPlayed.Type != "King" {
+		t.Errorf("The heuristic strategy should play the King not to leave Scopa but plays %v", move.CardPlayed)
+	}
+}
+
+func TestHeuristicMakesScopa(t *testing.T) {
+	three := deck.Card{Type: "Three", Suit: "Coppe"}
+	four := deck.Card{Type: "Four", Suit: "Bastoni"}
+	view := scopone.HandPlayerView{
+		Table: []deck.Card{three, four},
+		LegalMoves: []scopone.Move{
+			{CardPlayed: deck.Card{Type: "Four", Suit: "Spade"}, CardsTaken: []deck.Card{four}},
+			{CardPlayed: deck.Card{Type: "Seven", Suit: "Coppe"}, CardsTaken: []deck.Card{three, four}},
+		},
+	}
+	move := (&Heuristic{}).ChooseMove(view)
+	if move.CardPlayed.Type != "Seven" {
+		t.Errorf("The heuristic strategy should make Scopa with the Seven but plays %v", move.CardPlayed)
+	}
+}
+
+func TestRandomWithSameSeed(t *testing.T) {
+	view := scopone.HandPlayerView{}
+	for _, c := range deck.New() {
+		view.LegalMoves = append(view.LegalMoves, scopone.Move{CardPlayed: c, CardsTaken: []deck.Card{}})
+	}
+	r1 := NewRandom(7)
+	r2 := NewRandom(7)
+	for i := 0; i < 10; i++ {
+		m1 := r1.ChooseMove(view)
+		m2 := r2.ChooseMove(view)
+		if m1.CardPlayed != m2.CardPlayed {
+			t.Errorf("Random strategies with the same seed should play the same card but play %v and %v", m1.CardPlayed, m2.CardPlayed)
+		}
+	}
+}
+
+// Four bots play a whole hand
+func TestBotsPlayAHand(t *testing.T) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	gName := "TestBotsPlayAHand"
+	g, _ := s.NewGame(ctx, gName, scopone.GameOptions{})
+	for _, strategy := range []scopone.BotStrategy{&Heuristic{}, NewRandom(1), &Heuristic{}, NewRandom(2)} {
+		if _, err := s.AddBotToGame(ctx, gName, strategy); err != nil {
+			t.Fatalf("A bot could not be added to the game: %v", err)
+		}
+	}
+	s.NewHand(ctx, g)
+	cardsPlayed := 0
+	for s.IsBotTurn(g) {
+		botName, move, _, _, err := s.PlayBot(ctx, g)
+		if err != nil {
+			t.Fatalf("Bot %v has played %v which returns an error %v", botName, move, err)
+		}
+		cardsPlayed++
+	}
+	if cardsPlayed != 40 {
+		t.Errorf("The bots should have played 40 cards but have played %v", cardsPlayed)
+	}
+	if g.Hands[0].State != scopone.HandClosed {
+		t.Errorf("The hand should be closed but is %v", g.Hands[0].State)
+	}
+}
+
+func TestRestoreBots(t *testing.T) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	// a bot read from the store has only the name of its strategy and has left the Osteria as all players read
+	bot := player.New("Bot 1")
+	bot.Bot = RandomStrategy
+	bot.Status = player.PlayerLeftOsteria
+	s.Players[bot.Name] = bot
+	RestoreBots(s)
+	strategy, found := s.Bots[bot.Name]
+	if !found || strategy.Name() != RandomStrategy {
+		t.Errorf("Bot 1 should have the strategy %v but has %v", RandomStrategy, strategy)
+	}
+	if bot.Status != player.PlayerPlaying {
+		t.Errorf("Bot 1 should be playing but is %v", bot.Status)
+	}
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// weights used by the heuristic strategy to evaluate the moves
+const (
+	// every card taken counts for Carte
+	carteWeight = 1
+	// Denari count for Denari and possibly Napoli
+	denariWeight = 2
+	// the Settebello is a point on its own
+	settebelloWeight = 10
+	// a Scopa is a point on its own
+	scopaWeight = 10
+)
+
+// primieraWeights are the weights of the cards which count most for the Primiera
+var primieraWeights = map[string]int{
+	"Seven": 3, "Six": 2, "Ace": 1,
+}
+
+// Heuristic is a strategy which evaluates the legal moves with the rules of thumb of a good player
+// - take the Settebello and never leave it on the table
+// - make Scopa and never leave the table so that the others can make Scopa
+// - take as many cards, Denari and cards good for Primiera as possible, and do not give them away
+type Heuristic struct{}
+
+// Name returns the name of the strategy
+func (h *Heuristic) Name() string {
+	return HeuristicStrategy
+}
+
+// ChooseMove returns the legal move with the highest evaluation
+func (h *Heuristic) ChooseMove(view scopone.HandPlayerView) scopone.Move {
+	if len(view.LegalMoves) == 0 {
+		return scopone.Move{}
+	}
+	best := view.LegalMoves[0]
+	bestValue := evaluate(best, view.Table)
+	for _, m := range view.LegalMoves[1:] {
+		if v := evaluate(m, view.Table); v > bestValue {
+			best = m
+			bestValue = v
+		}
+	}
+	return best
+}
+
+// evaluate returns how good a move is given the cards on the table - the higher, the better
+func evaluate(move scopone.Move, table []deck.Card) (value int) {
+	tableAfter := tableAfterMove(move, table)
+	if len(move.CardsTaken) > 0 {
+		value = value + cardWeight(move.CardPlayed)
+		for _, c := range move.CardsTaken {
+			value = value + cardWeight(c)
+		}
+		if len(tableAfter) == 0 {
+			value = value + scopaWeight
+		}
+	} else {
+		// a card placed on the table can be taken by the others
+		value = value - cardWeight(move.CardPlayed)
+	}
+	if isScopaPossible(tableAfter) {
+		value = value - scopaWeight
+	}
+	if _, found := deck.Find(tableAfter, settebello); found {
+		value = value - settebelloWeight
+	}
+	return
+}
+
+var settebello = deck.Card{Type: "Seven", Suit: deck.Denari}
+
+// cardWeight returns how much a card is worth for the score
+func cardWeight(c deck.Card) int {
+	weight := carteWeight + primieraWeights[c.Type]
+	if c.Suit == deck.Denari {
+		weight = weight + denariWeight
+	}
+	if c == settebello {
+		weight = weight + settebelloWeight
+	}
+	return weight
+}
+
+// tableAfterMove returns the cards left on the table after a move
+func tableAfterMove(move scopone.Move, table []deck.Card) []deck.Card {
+	if len(move.CardsTaken) == 0 {
+		tableAfter := make([]deck.Card, len(table), len(table)+1)
+		copy(tableAfter, table)
+		return append(tableAfter, move.CardPlayed)
+	}
+	tableAfter, _ := deck.RemoveCards(table, move.CardsTaken)
+	return tableAfter
+}
+
+// isScopaPossible returns true if the cards on the table can be all taken with one card, i.e. if their sum is not
+// higher than the value of the highest card
+func isScopaPossible(table []deck.Card) bool {
+	if len(table) == 0 {
+		return false
+	}
+	sum := 0
+	for _, c := range table {
+		sum = sum + scopone.CardValue(c)
+	}
+	return sum <= 10
+}
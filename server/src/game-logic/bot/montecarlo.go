@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// MonteCarloStrategy is the name of the Monte Carlo strategy
+const MonteCarloStrategy = "montecarlo"
+
+// default limits of the Monte Carlo strategy used when the strategy is created by name
+const (
+	defaultSimulations = 200
+	defaultTimeLimit   = 2 * time.Second
+)
+
+// MonteCarlo is a strategy which, for each move, guesses many times the cards it does not see, consistently with
+// what it has seen played so far, and simulates the rest of the hand for each guess with all the players playing
+// with the heuristic strategy - the move with the best average difference between the score of its team and the
+// score of the best of the other teams is chosen
+// The strategy is deterministic given the seed, as long as all the simulations are run within the time limit
+type MonteCarlo struct {
+	rnd *rand.Rand
+	// Simulations is the number of guesses of the cards not seen for which each move is simulated
+	Simulations int
+	// TimeLimit is the maximum time spent choosing a move - when it is reached no more simulations are started
+	TimeLimit time.Duration
+}
+
+// NewMonteCarlo returns a Monte Carlo strategy which runs, for each move, the number of simulations passed in
+// unless the time limit is reached
+func NewMonteCarlo(seed int64, simulations int, timeLimit time.Duration) *MonteCarlo {
+	return &MonteCarlo{rnd: rand.New(rand.NewSource(seed)), Simulations: simulations, TimeLimit: timeLimit}
+}
+
+// Name returns the name of the strategy
+func (mc *MonteCarlo) Name() string {
+	return MonteCarloStrategy
+}
+
+// ChooseMove returns the legal move with the best expected score difference
+// If the cards not seen can not be guessed the choice is left to the heuristic strategy
+func (mc *MonteCarlo) ChooseMove(view scopone.HandPlayerView) scopone.Move {
+	moves := view.LegalMoves
+	if len(moves) <= 1 {
+		return (&Heuristic{}).ChooseMove(view)
+	}
+	ourTeam := teamIndex(view)
+	unseen := unseenCards(view)
+	if ourTeam < 0 || !canBeGuessed(view, unseen) {
+		log.Printf("The cards not seen by %v can not be guessed, the heuristic strategy is used", view.PlayerName)
+		return (&Heuristic{}).ChooseMove(view)
+	}
+
+	deadline := time.Now().Add(mc.TimeLimit)
+	totals := make([]int, len(moves))
+	for i := 0; i < mc.Simulations && (i == 0 || time.Now().Before(deadline)); i++ {
+		otherPlayersCards, cardsToDeal := mc.guess(view, unseen)
+		for j, m := range moves {
+			scores, err := simulate(view, otherPlayersCards, cardsToDeal, m)
+			if err != nil {
+				log.Printf("The simulation of move %v failed: %v", m, err)
+				return (&Heuristic{}).ChooseMove(view)
+			}
+			totals[j] = totals[j] + scoreDifference(scores, ourTeam)
+		}
+	}
+	best := 0
+	for j := range moves {
+		if totals[j] > totals[best] {
+			best = j
+		}
+	}
+	return moves[best]
+}
+
+// guess shuffles the cards not seen and gives them to the other players, as many as each of them has, and the
+// rest are the cards still to be dealt
+func (mc *MonteCarlo) guess(view scopone.HandPlayerView, unseen []deck.Card) (otherPlayersCards map[string][]deck.Card,
+	cardsToDeal []deck.Card) {
+	shuffled := make([]deck.Card, len(unseen))
+	copy(shuffled, unseen)
+	mc.rnd.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	otherPlayersCards = make(map[string][]deck.Card)
+	next := 0
+	// the players are taken in the order of the teams so that the same seed gives the same guess
+	for _, tv := range view.Teams {
+		for _, pName := range tv.Players {
+			if pName == view.PlayerName {
+				continue
+			}
+			count := view.PlayersCardsCount[pName]
+			otherPlayersCards[pName] = shuffled[next : next+count]
+			next = next + count
+		}
+	}
+	return otherPlayersCards, shuffled[next:]
+}
+
+// simulate plays the move and then the rest of the hand with the heuristic strategy and returns the scores of the
+// teams at the end of the hand
+func simulate(view scopone.HandPlayerView, otherPlayersCards map[string][]deck.Card, cardsToDeal []deck.Card,
+	move scopone.Move) ([]int, error) {
+	sim, err := scopone.NewSimulation(view, otherPlayersCards, cardsToDeal)
+	if err != nil {
+		return nil, err
+	}
+	err = sim.Play(move)
+	for err == nil && !sim.IsOver() {
+		err = sim.Play((&Heuristic{}).ChooseMove(scopone.HandPlayerView{Table: sim.Table(), LegalMoves: sim.LegalMoves()}))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sim.Scores(), nil
+}
+
+// scoreDifference returns the difference between the score of our team and the highest score of the other teams
+func scoreDifference(scores []int, ourTeam int) int {
+	bestOther := -1
+	for i, s := range scores {
+		if i != ourTeam && (bestOther < 0 || s > bestOther) {
+			bestOther = s
+		}
+	}
+	return scores[ourTeam] - bestOther
+}
+
+// teamIndex returns the index of the team of the player of the view - -1 if the player is not in any team
+func teamIndex(view scopone.HandPlayerView) int {
+	for i, tv := range view.Teams {
+		for _, pName := range tv.Players {
+			if pName == view.PlayerName {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// unseenCards returns the cards the player of the view has not seen, i.e. the cards which are not in his hands,
+// not on the table and have not been played or taken so far
+func unseenCards(view scopone.HandPlayerView) []deck.Card {
+	seen := make([]deck.Card, 0)
+	seen = append(seen, view.PlayerCards...)
+	seen = append(seen, view.Table...)
+	for _, cardPlay := range view.History.CardPlaySequence {
+		seen = append(seen, cardPlay.CardPlayed)
+		seen = append(seen, cardPlay.CardsTaken...)
+	}
+	unseen := make([]deck.Card, 0)
+	for _, c := range deck.New() {
+		if _, found := deck.Find(seen, c); !found {
+			unseen = append(unseen, c)
+		}
+	}
+	return unseen
+}
+
+// canBeGuessed returns true if the cards not seen are as many as the cards in the hands of the other players plus
+// the cards still to be dealt
+func canBeGuessed(view scopone.HandPlayerView, unseen []deck.Card) bool {
+	expected := view.CardsToDeal
+	for pName, count := range view.PlayersCardsCount {
+		if pName != view.PlayerName {
+			expected = expected + count
+		}
+	}
+	return expected == len(unseen)
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// recordingStrategy plays with the heuristic strategy and records the last view it has received
+type recordingStrategy struct {
+	Heuristic
+	lastView scopone.HandPlayerView
+}
+
+func (r *recordingStrategy) ChooseMove(view scopone.HandPlayerView) scopone.Move {
+	r.lastView = view
+	return r.Heuristic.ChooseMove(view)
+}
+
+// newBotsGame creates a game played by 4 bots with the strategies passed in and starts the first hand
+func newBotsGame(t *testing.T, gName string, strategies []scopone.BotStrategy) (*scopone.Scopone, *scopone.Game) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, _ := s.NewGame(ctx, gName, scopone.GameOptions{})
+	for _, strategy := range strategies {
+		if _, err := s.AddBotToGame(ctx, gName, strategy); err != nil {
+			t.Fatalf("A bot could not be added to the game: %v", err)
+		}
+	}
+	s.NewHand(ctx, g)
+	return s, g
+}
+
+func TestMonteCarloWithSameSeed(t *testing.T) {
+	recorder := &recordingStrategy{}
+	s, g := newBotsGame(t, "TestMonteCarloWithSameSeed",
+		[]scopone.BotStrategy{recorder, &Heuristic{}, &Heuristic{}, &Heuristic{}})
+	// a round is played so that the view of the recorder has some history and some cards on the table
+	for i := 0; i < 5; i++ {
+		s.PlayBot(ctx, g)
+	}
+	view := recorder.lastView
+	if len(view.History.CardPlaySequence) != 4 {
+		t.Fatalf("The view should have 4 cards played in its history but has %v", len(view.History.CardPlaySequence))
+	}
+	if len(unseenCards(view)) != 27 {
+		t.Errorf("After 4 cards played the cards not seen should be 27 but are %v", len(unseenCards(view)))
+	}
+
+	m1 := NewMonteCarlo(11, 20, time.Minute).ChooseMove(view)
+	m2 := NewMonteCarlo(11, 20, time.Minute).ChooseMove(view)
+	if m1.CardPlayed != m2.CardPlayed || len(m1.CardsTaken) != len(m2.CardsTaken) {
+		t.Errorf("Monte Carlo strategies with the same seed should make the same move but make %v and %v", m1, m2)
+	}
+}
+
+func TestMonteCarloPlaysAHand(t *testing.T) {
+	s, g := newBotsGame(t, "TestMonteCarloPlaysAHand",
+		[]scopone.BotStrategy{NewMonteCarlo(1, 5, time.Second), &Heuristic{}, NewMonteCarlo(2, 5, time.Second), &Heuristic{}})
+	for s.IsBotTurn(g) {
+		botName, move, _, _, err := s.PlayBot(ctx, g)
+		if err != nil {
+			t.Fatalf("Bot %v has played %v which returns an error %v", botName, move, err)
+		}
+	}
+	if g.Hands[0].State != scopone.HandClosed {
+		t.Errorf("The hand should be closed but is %v", g.Hands[0].State)
+	}
+}
+
+func TestMonteCarloWithTimeLimit(t *testing.T) {
+	recorder := &recordingStrategy{}
+	s, g := newBotsGame(t, "TestMonteCarloWithTimeLimit",
+		[]scopone.BotStrategy{recorder, &Heuristic{}, &Heuristic{}, &Heuristic{}})
+	s.PlayBot(ctx, g)
+	start := time.Now()
+	NewMonteCarlo(3, 1000000, 100*time.Millisecond).ChooseMove(recorder.lastView)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The Monte Carlo strategy should stop close to its time limit but takes %v", elapsed)
+	}
+}
+
+func TestScoreDifference(t *testing.T) {
+	if d := scoreDifference([]int{5, 2, 7}, 0); d != -2 {
+		t.Errorf("The difference with the best of the other teams should be -2 but is %v", d)
+	}
+	if d := scoreDifference([]int{5, 2}, 0); d != 3 {
+		t.Errorf("The difference with the other team should be 3 but is %v", d)
+	}
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"math/rand"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Random is a strategy which plays any of the legal moves at random
+type Random struct {
+	rnd *rand.Rand
+}
+
+// NewRandom returns a random strategy - the same seed gives the same sequence of moves
+func NewRandom(seed int64) *Random {
+	return &Random{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Name returns the name of the strategy
+func (r *Random) Name() string {
+	return RandomStrategy
+}
+
+// ChooseMove returns one of the legal moves of the view at random
+func (r *Random) ChooseMove(view scopone.HandPlayerView) scopone.Move {
+	if len(view.LegalMoves) == 0 {
+		return scopone.Move{}
+	}
+	return view.LegalMoves[r.rnd.Intn(len(view.LegalMoves))]
+}
@@ -3,10 +3,10 @@
 package deck
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
-	"time"
 )
 
 // Card holds the card suits and types in the deck
@@ -49,11 +49,20 @@ func New() (deck Deck) {
 	return
 }
 
-// Shuffle the deck
+// Shuffle the deck with swaps drawn from a crypto-secure source
 func Shuffle(d Deck) Deck {
+	cryptoShuffle(d)
+	return d
+}
+
+// ShuffleWithSeed shuffles the deck always in the same way for the same seed - the decks shuffled with a seed can
+// be predicted, since math/rand takes the seed modulo 2^31-1 and all its seeds can be tried in a short time, so it
+// has to be used only for the decks which have to be repeated, e.g. in the games with a seed
+func ShuffleWithSeed(d Deck, seed int64) Deck {
+	rnd := rand.New(rand.NewSource(seed))
 	for i := 1; i < len(d); i++ {
 		// Create a random int up to the number of cards
-		r := rand.Intn(i + 1)
+		r := rnd.Intn(i + 1)
 
 		// If the the current card doesn't match the random
 		// int we generated then we'll switch them out
@@ -92,14 +101,17 @@ func Find(deck []Card, c Card) (int, bool) {
 	return -1, false
 }
 
+// ErrCardNotInDeck is returned when trying to remove a card which is not in the deck
+var ErrCardNotInDeck = errors.New("Card not in the deck")
+
 // RemoveCard removes a Card from a deck with immutability
 // a new deck is returned and the one passed as input is left unchanged
+// If the card is not in the deck an error wrapping ErrCardNotInDeck is returned
 // https://stackoverflow.com/a/59205977/5699993
-func RemoveCard(deck []Card, c Card) (newDeck []Card) {
+func RemoveCard(deck []Card, c Card) (newDeck []Card, err error) {
 	i, found := Find(deck, c)
 	if !found {
-		fmt.Printf("Panicking! The card %v is not in the deck %v\n", c, deck)
-		panic(fmt.Sprintf("Panicking! The card %v is not in the deck %v\n", c, deck))
+		return nil, fmt.Errorf("%w: the card %v is not in the deck %v", ErrCardNotInDeck, c, deck)
 	}
 	for j := range deck {
 		if j != i {
@@ -110,17 +122,16 @@ func RemoveCard(deck []Card, c Card) (newDeck []Card) {
 }
 
 // RemoveCards removes a slice of Cards from a deck
-func RemoveCards(deck []Card, cardsToRemove []Card) (newDeck []Card) {
+// If any of the cards is not in the deck an error wrapping ErrCardNotInDeck is returned
+func RemoveCards(deck []Card, cardsToRemove []Card) (newDeck []Card, err error) {
 	// copy for immutability
 	newDeck = make([]Card, len(deck))
 	copy(newDeck, deck)
 	for _, c := range cardsToRemove {
-		newDeck = RemoveCard(newDeck, c)
+		newDeck, err = RemoveCard(newDeck, c)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return
 }
-
-// Seed our randomness with the current time
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
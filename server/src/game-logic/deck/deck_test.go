@@ -1,6 +1,7 @@
 package deck
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -42,7 +43,7 @@ func TestFindCardInTheMiddle(t *testing.T) {
 
 func TestRemoveFirstCard(t *testing.T) {
 	deck := New()
-	cardsAfter := RemoveCard(deck, deck[0])
+	cardsAfter, _ := RemoveCard(deck, deck[0])
 	if len(cardsAfter) != len(deck)-1 {
 		t.Errorf("After removing one card the cards should be %v but instead are %v", len(deck)-1, len(cardsAfter))
 	}
@@ -55,7 +56,7 @@ func TestRemoveFirstCard(t *testing.T) {
 
 func TestRemoveLastCard(t *testing.T) {
 	deck := New()
-	cardsAfter := RemoveCard(deck, deck[len(deck)-1])
+	cardsAfter, _ := RemoveCard(deck, deck[len(deck)-1])
 	if len(cardsAfter) != len(deck)-1 {
 		t.Errorf("After removing one card the cards should be %v but instead are %v", len(deck)-1, len(cardsAfter))
 	}
@@ -71,8 +72,8 @@ func TestRemoveTwoCardsInTheMiddle(t *testing.T) {
 	iRemove := 10
 	firstCardToRemove := deck[iRemove]
 	secondCardToRemove := deck[iRemove+1]
-	cardsAfter := RemoveCard(deck, firstCardToRemove)
-	cardsAfter = RemoveCard(cardsAfter, secondCardToRemove)
+	cardsAfter, _ := RemoveCard(deck, firstCardToRemove)
+	cardsAfter, _ = RemoveCard(cardsAfter, secondCardToRemove)
 	if len(cardsAfter) != len(deck)-2 {
 		t.Errorf("After removing one card the cards should be %v but instead are %v", len(deck)-2, len(cardsAfter))
 	}
@@ -91,7 +92,7 @@ func TestRemoveCards(t *testing.T) {
 	iRemove1 := 10
 	iRemove2 := 20
 	cardsToRemove := []Card{deck[iRemove1], deck[iRemove2]}
-	cardsAfter := RemoveCards(deck, cardsToRemove)
+	cardsAfter, _ := RemoveCards(deck, cardsToRemove)
 	if len(cardsAfter) != len(deck)-2 {
 		t.Errorf("After removing one card the cards should be %v but instead are %v", len(deck)-2, len(cardsAfter))
 	}
@@ -104,3 +105,17 @@ func TestRemoveCards(t *testing.T) {
 		}
 	}
 }
+
+func TestRemoveCardNotInDeck(t *testing.T) {
+	deck := New()
+	card := deck[0]
+	cardsAfter, _ := RemoveCard(deck, card)
+	_, err := RemoveCard(cardsAfter, card)
+	if !errors.Is(err, ErrCardNotInDeck) {
+		t.Errorf("Removing card %v which is not in the deck should return ErrCardNotInDeck but returns %v", card, err)
+	}
+	_, err = RemoveCards(cardsAfter, []Card{deck[1], card})
+	if !errors.Is(err, ErrCardNotInDeck) {
+		t.Errorf("Removing cards including %v which is not in the deck should return ErrCardNotInDeck but returns %v", card, err)
+	}
+}
@@ -0,0 +1,83 @@
+package deck
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+	"time"
+)
+
+// Shuffler shuffles the decks of the hands
+type Shuffler interface {
+	// Shuffle shuffles the deck and returns the seed which shuffles a new deck in the same way with ShuffleWithSeed
+	// If the deck is not shuffled with a seed, 0 is returned
+	Shuffle(d Deck) (seed int64)
+}
+
+// SeededShuffler shuffles each deck with a different seed taken from a sequence of seeds which is always the same
+// for the same initial seed - it allows to repeat the same sequence of hands, but whoever knows the initial seed, or
+// searches it from the cards dealt, can predict all the decks, see ShuffleWithSeed
+type SeededShuffler struct {
+	rnd *rand.Rand
+}
+
+// NewSeededShuffler returns a shuffler which generates the seeds of the decks starting from a seed
+func NewSeededShuffler(seed int64) *SeededShuffler {
+	return &SeededShuffler{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Shuffle shuffles the deck with the next seed of the sequence
+func (s *SeededShuffler) Shuffle(d Deck) int64 {
+	seed := s.rnd.Int63()
+	ShuffleWithSeed(d, seed)
+	return seed
+}
+
+// CryptoShuffler shuffles each deck drawing every swap from a crypto-secure source, so that the cards of the other
+// players can not be found out from the cards a player has - the deck has no seed, so the hand can be dealt again
+// only from the deck registered in its history, see scopone.HandHistory
+type CryptoShuffler struct{}
+
+// Shuffle shuffles the deck with swaps drawn from a crypto-secure source - the seed returned is always 0
+func (s *CryptoShuffler) Shuffle(d Deck) int64 {
+	cryptoShuffle(d)
+	return 0
+}
+
+// cryptoShuffle shuffles the deck with the Fisher-Yates algorithm drawing each index from a crypto-secure source - if
+// the source is not available the indexes are drawn from a source seeded with the current time
+func cryptoShuffle(d Deck) {
+	var fallback *rand.Rand
+	for i := len(d) - 1; i > 0; i-- {
+		var r int
+		n, err := crand.Int(crand.Reader, big.NewInt(int64(i+1)))
+		if err == nil {
+			r = int(n.Int64())
+		} else {
+			if fallback == nil {
+				fallback = rand.New(rand.NewSource(time.Now().UnixNano()))
+			}
+			r = fallback.Intn(i + 1)
+		}
+		d[r], d[i] = d[i], d[r]
+	}
+}
+
+// ArrangedShuffler does not shuffle the decks but replaces them with decks arranged in advance, e.g. by tests
+type ArrangedShuffler struct {
+	decks []Deck
+	next  int
+}
+
+// NewArrangedShuffler returns a shuffler which gives the decks passed in, in the same order - when all of them have
+// been given it starts again from the first one
+func NewArrangedShuffler(decks ...Deck) *ArrangedShuffler {
+	return &ArrangedShuffler{decks: decks}
+}
+
+// Shuffle replaces the cards of the deck with the cards of the next arranged deck - the seed returned is always 0
+func (s *ArrangedShuffler) Shuffle(d Deck) int64 {
+	copy(d, s.decks[s.next])
+	s.next = (s.next + 1) % len(s.decks)
+	return 0
+}
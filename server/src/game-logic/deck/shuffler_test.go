@@ -0,0 +1,69 @@
+package deck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShuffleWithSeed(t *testing.T) {
+	d1 := ShuffleWithSeed(New(), 42)
+	d2 := ShuffleWithSeed(New(), 42)
+	if !reflect.DeepEqual(d1, d2) {
+		t.Errorf("Two decks shuffled with the same seed should be equal but are %v and %v", d1, d2)
+	}
+	d3 := ShuffleWithSeed(New(), 43)
+	if reflect.DeepEqual(d1, d3) {
+		t.Errorf("Two decks shuffled with different seeds should be different but are both %v", d1)
+	}
+}
+
+func TestSeededShuffler(t *testing.T) {
+	s1 := NewSeededShuffler(7)
+	s2 := NewSeededShuffler(7)
+	for i := 0; i < 3; i++ {
+		d1 := New()
+		d2 := New()
+		seed1 := s1.Shuffle(d1)
+		seed2 := s2.Shuffle(d2)
+		if seed1 != seed2 || !reflect.DeepEqual(d1, d2) {
+			t.Errorf("Shufflers with the same seed should shuffle deck %v in the same way", i)
+		}
+	}
+}
+
+func TestCryptoShuffler(t *testing.T) {
+	d := New()
+	if seed := (&CryptoShuffler{}).Shuffle(d); seed != 0 {
+		t.Errorf("A deck shuffled from a crypto-secure source should have no seed but has %v", seed)
+	}
+	if !reflect.DeepEqual(cardCount(d), cardCount(New())) {
+		t.Errorf("The deck shuffled should have all the cards of a new deck but is %v", d)
+	}
+	if reflect.DeepEqual(d, New()) {
+		t.Errorf("The deck should be shuffled but is %v", d)
+	}
+}
+
+func TestArrangedShuffler(t *testing.T) {
+	arranged1 := ShuffleWithSeed(New(), 1)
+	arranged2 := ShuffleWithSeed(New(), 2)
+	s := NewArrangedShuffler(arranged1, arranged2)
+	for _, expected := range []Deck{arranged1, arranged2, arranged1} {
+		d := New()
+		if seed := s.Shuffle(d); seed != 0 {
+			t.Errorf("An arranged deck should have seed 0 but has %v", seed)
+		}
+		if !reflect.DeepEqual(d, expected) {
+			t.Errorf("The deck should be %v but is %v", expected, d)
+		}
+	}
+}
+
+// cardCount returns how many times each card is in the deck
+func cardCount(d Deck) map[Card]int {
+	count := make(map[Card]int)
+	for _, c := range d {
+		count[c]++
+	}
+	return count
+}
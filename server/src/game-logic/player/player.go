@@ -22,6 +22,8 @@ type Player struct {
 	// any time the Players list is sent to the clients to refresh them
 	Cards  []deck.Card  `json:"-"` // DO NOT SEND THIS AS JSON PROPERTY
 	Status PlayerStatus `json:"status"`
+	// Bot is the name of the strategy of a player controlled by the computer - it is empty for the real players
+	Bot string `json:"bot,omitempty"`
 }
 
 // New returns a new Player
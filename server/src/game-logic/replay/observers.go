@@ -0,0 +1,51 @@
+package replay
+
+import (
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ObserverViews returns the views of the current hand of the game which its observers are allowed to see, given the
+// views of the players passed in, see scopone.ObserverMode
+// With ObserveDelayed the observers see the cards of all the players in the hand replayed up to the move which is
+// the delay of the game behind the last one, and only the public views until that many moves have been played
+func ObserverViews(g *scopone.Game, handViews map[string]scopone.HandPlayerView) map[string]scopone.HandPlayerView {
+	if len(g.Hands) == 0 || handViews == nil {
+		return handViews
+	}
+	mode, delay := g.Observation()
+	closed := g.Hands[len(g.Hands)-1].State == scopone.HandClosed
+	switch {
+	case closed && mode != scopone.ObservePublic:
+		// the hand is over and there is nothing left to hide
+		return handViews
+	case mode == scopone.ObserveDelayed:
+		return delayedViews(g, handViews, delay)
+	default:
+		return publicViews(handViews)
+	}
+}
+
+// delayedViews returns the views of the current hand of the game as they were the number of moves passed in
+// before the views of the players passed in
+func delayedViews(g *scopone.Game, handViews map[string]scopone.HandPlayerView, delay int) map[string]scopone.HandPlayerView {
+	r, err := ForHand(g, len(g.Hands)-1)
+	if err != nil {
+		// the hand just dealt has no card played to replay
+		return publicViews(handViews)
+	}
+	position := r.Len() - 1 - delay
+	if position < 0 {
+		// the hand just dealt is less than the delay behind, so the cards of the players are not shown yet
+		return publicViews(r.steps[0].HandViews)
+	}
+	return r.steps[position].HandViews
+}
+
+// publicViews returns the views passed in without what only each player sees, see scopone.PublicView
+func publicViews(handViews map[string]scopone.HandPlayerView) map[string]scopone.HandPlayerView {
+	public := make(map[string]scopone.HandPlayerView)
+	for pName, view := range handViews {
+		public[pName] = scopone.PublicView(view)
+	}
+	return public
+}
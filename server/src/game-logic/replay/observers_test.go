@@ -0,0 +1,91 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// gameInPlay creates a game with the options passed in, played by bots, and makes the bots play the number of cards
+// passed in of its first hand
+func gameInPlay(t *testing.T, options scopone.GameOptions, cards int) (*scopone.Scopone, *scopone.Game) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, err := s.NewGame(ctx, "game", options)
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		s.AddBotToGame(ctx, "game", &bot.Heuristic{})
+	}
+	s.NewHand(ctx, g)
+	for i := 0; i < cards; i++ {
+		if _, move, _, _, err := s.PlayBot(ctx, g); err != nil {
+			t.Fatalf("The move %v returns an error %v", move, err)
+		}
+	}
+	return s, g
+}
+
+func TestPublicObservers(t *testing.T) {
+	s, g := gameInPlay(t, scopone.GameOptions{Seed: 1}, 5)
+	for pName, view := range ObserverViews(g, s.CurrentHandViews(g)) {
+		if view.PlayerCards != nil || view.LegalMoves != nil {
+			t.Errorf("The observers should not see the cards of %v", pName)
+		}
+		if !reflect.DeepEqual(view.Table, g.Hands[0].Table) {
+			t.Errorf("The observers should see the table %v but see %v", g.Hands[0].Table, view.Table)
+		}
+	}
+}
+
+func TestDelayedObservers(t *testing.T) {
+	options := scopone.GameOptions{Seed: 2, ObserverMode: scopone.ObserveDelayed, ObserverDelay: 3}
+	s, g := gameInPlay(t, options, 2)
+	for pName, view := range ObserverViews(g, s.CurrentHandViews(g)) {
+		if view.PlayerCards != nil {
+			t.Errorf("The observers should not see the cards of %v before the first 3 moves", pName)
+		}
+		if !reflect.DeepEqual(view.Table, g.Hands[0].History.CardPlaySequence[0].Table) {
+			t.Errorf("The observers should see the table dealt but see %v", view.Table)
+		}
+	}
+
+	// after 5 moves the observers see all the cards after the second move
+	s, g = gameInPlay(t, options, 5)
+	r, _ := ForHand(g, 0)
+	views := ObserverViews(g, s.CurrentHandViews(g))
+	if !reflect.DeepEqual(views, r.Steps()[2].HandViews) {
+		t.Errorf("The observers should see the hand after the second move")
+	}
+	for pName, view := range views {
+		if len(view.PlayerCards) == 0 {
+			t.Errorf("The observers should see the cards of %v", pName)
+		}
+	}
+}
+
+func TestObserversAfterTheHand(t *testing.T) {
+	options := scopone.GameOptions{Seed: 3, ObserverMode: scopone.ObserveAfterHand}
+	s, g := gameInPlay(t, options, 1)
+	for pName, view := range ObserverViews(g, s.CurrentHandViews(g)) {
+		if view.PlayerCards != nil {
+			t.Errorf("The observers should not see the cards of %v while the hand is played", pName)
+		}
+	}
+	for s.IsBotTurn(g) {
+		s.PlayBot(ctx, g)
+	}
+	for pName, view := range ObserverViews(g, s.CurrentHandViews(g)) {
+		if view.History.PlayerDecks[pName] == nil {
+			t.Errorf("The observers should see the cards dealt to %v once the hand is closed", pName)
+		}
+	}
+	g.ObserverMode = scopone.ObservePublic
+	for pName, view := range ObserverViews(g, s.CurrentHandViews(g)) {
+		if view.History.PlayerDecks != nil {
+			t.Errorf("The observers of a public game should never see the cards dealt to %v", pName)
+		}
+	}
+}
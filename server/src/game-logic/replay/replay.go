@@ -0,0 +1,326 @@
+// Package replay re-executes the hands stored in the history of the games, card by card, with the same logic used to
+// play them, so that a hand can be watched again moving forward and back through its plays
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ErrInvalidHistory is returned when the history of a hand does not contain what is needed to replay the hand
+var ErrInvalidHistory = errors.New("Invalid hand history")
+
+// ErrReplayDiverged is returned when a card play registered in the history is not accepted by the game logic
+var ErrReplayDiverged = errors.New("Replay diverged from the hand history")
+
+// ErrHandNotClosed is returned when a hand which is still played is asked to be replayed to the players
+var ErrHandNotClosed = errors.New("Hand not closed")
+
+// ErrScoreMismatch is returned when the score calculated by replaying a hand is not the one registered for the hand
+var ErrScoreMismatch = errors.New("Replayed score does not match the registered score")
+
+// Step is the state of the hand after one card play, as seen by each player
+type Step struct {
+	// CardPlay is the card play which has brought the hand to this step - it is empty for the first step, which is
+	// the hand just dealt
+	CardPlay scopone.HandCardPlay `json:"cardPlay"`
+	// HandViews are the views of the hand of all the players, with the name of the player as key
+	HandViews map[string]scopone.HandPlayerView `json:"handViews"`
+}
+
+// Replay is a hand replayed from its history - it holds all the steps of the hand and a cursor which can be moved
+// forward and back through them
+type Replay struct {
+	steps    []Step
+	position int
+}
+
+// New replays the hand registered in the history with the rules of the variant passed in
+// The players are seated in the order they play the first round of the hand and, in games with 4 players, the
+// first and the third player of the round are one team and the second and the fourth the other team
+func New(history scopone.HandHistory, variant scopone.Variant) (*Replay, error) {
+	teams, err := historyTeams(history)
+	if err != nil {
+		return nil, err
+	}
+	return replay(history, teams, variant)
+}
+
+// historyTeams returns the teams of the players of the hand registered in the history, see New
+func historyTeams(history scopone.HandHistory) ([][]string, error) {
+	seating, err := seatingOrder(history)
+	if err != nil {
+		return nil, err
+	}
+	var teams [][]string
+	if len(seating) == 4 {
+		teams = [][]string{{seating[0], seating[2]}, {seating[1], seating[3]}}
+	} else {
+		for _, pName := range seating {
+			teams = append(teams, []string{pName})
+		}
+	}
+	return teams, nil
+}
+
+// ForHand replays the hand of a game with the index passed in and verifies that the score calculated by the replay
+// is the one registered for the hand, if the hand is closed
+func ForHand(g *scopone.Game, handIndex int) (*Replay, error) {
+	if handIndex < 0 || handIndex >= len(g.Hands) {
+		return nil, fmt.Errorf("%w - Game %v has no hand with index %v", ErrInvalidHistory, g.Name, handIndex)
+	}
+	hand := g.Hands[handIndex]
+	var teams [][]string
+	playedBySeated := true
+	for _, t := range g.Teams {
+		var players []string
+		for _, p := range t.Players {
+			players = append(players, p.Name)
+			_, played := hand.History.PlayerDecks[p.Name]
+			playedBySeated = playedBySeated && played
+		}
+		teams = append(teams, players)
+	}
+	if !playedBySeated {
+		// the hand has been played before a player has taken the seat of another player, so the teams are the ones
+		// of the players registered in the history
+		var err error
+		teams, err = historyTeams(hand.History)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, err := replay(hand.History, teams, g.Variant)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range r.steps {
+		for pName, hv := range s.HandViews {
+			hv.ID = strconv.Itoa(handIndex + 1)
+			hv.GameName = g.Name
+			s.HandViews[pName] = hv
+		}
+	}
+	if hand.State == scopone.HandClosed {
+		err = r.VerifyScore(hand.Score)
+	}
+	return r, err
+}
+
+// ForClosedHand replays a hand of a game, as ForHand does, only if the hand is closed - the replay of a hand still
+// played would show the cards of all the players
+func ForClosedHand(g *scopone.Game, handIndex int) (*Replay, error) {
+	if handIndex >= 0 && handIndex < len(g.Hands) && g.Hands[handIndex].State != scopone.HandClosed {
+		return nil, fmt.Errorf("%w - Hand %v of game %v is still played", ErrHandNotClosed, handIndex, g.Name)
+	}
+	return ForHand(g, handIndex)
+}
+
+// replay plays again the hand registered in the history, with the teams passed in, and builds all its steps
+func replay(history scopone.HandHistory, teams [][]string, variant scopone.Variant) (*Replay, error) {
+	if len(history.CardPlaySequence) == 0 {
+		return nil, fmt.Errorf("%w - There are no card plays in the history", ErrInvalidHistory)
+	}
+	firstPlayer := history.CardPlaySequence[0].Player
+	table := history.CardPlaySequence[0].Table
+	toDeal, err := cardsToDeal(history, table)
+	if err != nil {
+		return nil, err
+	}
+	view := scopone.HandPlayerView{
+		Table:             table,
+		FirstPlayerName:   firstPlayer,
+		CurrentPlayerName: firstPlayer,
+		Variant:           variant,
+	}
+	for _, players := range teams {
+		view.Teams = append(view.Teams, scopone.TeamHandView{Players: players})
+	}
+	sim, err := scopone.NewSimulation(view, history.PlayerDecks, toDeal)
+	if err != nil {
+		return nil, fmt.Errorf("%w - %v", ErrInvalidHistory, err)
+	}
+
+	r := &Replay{}
+	r.steps = append(r.steps, Step{HandViews: sim.HandViews()})
+	for i, cardPlay := range history.CardPlaySequence {
+		// the final take of the cards left on the table is not a card play but is made by the game logic when the
+		// last card of the hand is played
+		if isFinalTableTake(cardPlay) {
+			continue
+		}
+		if sim.IsOver() {
+			return nil, fmt.Errorf("%w - The hand is over but there are more cards played in the history (play %v)",
+				ErrReplayDiverged, i)
+		}
+		if sim.CurrentPlayer() != cardPlay.Player {
+			return nil, fmt.Errorf("%w - Play %v is made by %v but it is the turn of %v",
+				ErrReplayDiverged, i, cardPlay.Player, sim.CurrentPlayer())
+		}
+		err = sim.Play(scopone.Move{CardPlayed: cardPlay.CardPlayed, CardsTaken: cardPlay.CardsTaken})
+		if err != nil {
+			return nil, fmt.Errorf("%w - Play %v of %v is not accepted: %v", ErrReplayDiverged, i, cardPlay.Player, err)
+		}
+		r.steps = append(r.steps, Step{CardPlay: cardPlay, HandViews: sim.HandViews()})
+	}
+	return r, nil
+}
+
+// seatingOrder returns the names of the players in the order they play, which is the order of the first round of
+// the hand
+func seatingOrder(history scopone.HandHistory) ([]string, error) {
+	numberOfPlayers := len(history.PlayerDecks)
+	seating := make([]string, 0)
+	for _, cardPlay := range history.CardPlaySequence {
+		if len(seating) == numberOfPlayers {
+			break
+		}
+		for _, pName := range seating {
+			if pName == cardPlay.Player {
+				return nil, fmt.Errorf("%w - Player %v plays twice in the first round", ErrInvalidHistory, pName)
+			}
+		}
+		seating = append(seating, cardPlay.Player)
+	}
+	if numberOfPlayers == 0 || len(seating) < numberOfPlayers {
+		return nil, fmt.Errorf("%w - The players of the hand can not be seated since the first round is not complete",
+			ErrInvalidHistory)
+	}
+	return seating, nil
+}
+
+// cardsToDeal returns the cards of the deck dealt after the first round, in the order they are dealt
+// The hands stored before the deck was registered in the history have all their cards dealt at the beginning
+func cardsToDeal(history scopone.HandHistory, table []deck.Card) ([]deck.Card, error) {
+	if len(history.Deck) == 0 {
+		return nil, nil
+	}
+	if history.Seed != 0 {
+		shuffled := deck.ShuffleWithSeed(deck.New(), history.Seed)
+		for i := range shuffled {
+			if len(history.Deck) != len(shuffled) || shuffled[i] != history.Deck[i] {
+				return nil, fmt.Errorf("%w - The deck is not the one shuffled with seed %v", ErrInvalidHistory,
+					history.Seed)
+			}
+		}
+	}
+	dealt := len(table)
+	for _, cards := range history.PlayerDecks {
+		dealt = dealt + len(cards)
+	}
+	if dealt > len(history.Deck) {
+		return nil, fmt.Errorf("%w - %v cards dealt from a deck of %v cards", ErrInvalidHistory, dealt,
+			len(history.Deck))
+	}
+	return history.Deck[dealt:], nil
+}
+
+// isFinalTableTake returns true if the card play is the take of the cards left on the table at the end of the hand
+func isFinalTableTake(cardPlay scopone.HandCardPlay) bool {
+	return cardPlay.CardPlayed == deck.Card{}
+}
+
+// VerifyScore checks that the scores of the teams at the end of the replayed hand are the scores passed in, which
+// have the names of the teams as keys - an error wrapping ErrScoreMismatch is returned if they are not
+func (r *Replay) VerifyScore(score map[string]scopone.TeamScore) error {
+	last := r.steps[len(r.steps)-1]
+	var teamViews []scopone.TeamHandView
+	for _, hv := range last.HandViews {
+		teamViews = hv.Teams
+		if hv.Status != scopone.HandClosed {
+			return fmt.Errorf("%w - The replayed hand is not closed", ErrScoreMismatch)
+		}
+		break
+	}
+	if len(teamViews) != len(score) {
+		return fmt.Errorf("%w - %v teams have been replayed but %v teams have a score", ErrScoreMismatch,
+			len(teamViews), len(score))
+	}
+	for _, tv := range teamViews {
+		s, found := scoreOfTeam(score, tv.Players)
+		if !found {
+			return fmt.Errorf("%w - There is no score for team %v", ErrScoreMismatch, tv.Players)
+		}
+		if s.Score != tv.FinalHandScore {
+			return fmt.Errorf("%w - Team %v has scored %v but the replay gives %v", ErrScoreMismatch, tv.Players,
+				s.Score, tv.FinalHandScore)
+		}
+	}
+	return nil
+}
+
+// scoreOfTeam returns the score of the team with the players passed in - the players of the team may be in any
+// order since the order of the players within a team is not registered in the history
+func scoreOfTeam(score map[string]scopone.TeamScore, players []string) (scopone.TeamScore, bool) {
+	for tName, s := range score {
+		if sameTeam(tName, players) {
+			return s, true
+		}
+	}
+	return scopone.TeamScore{}, false
+}
+
+// sameTeam returns true if the name of the team is made of the names of its players, which are at most 2, in any
+// order
+func sameTeam(tName string, players []string) bool {
+	if tName == strings.Join(players, "_") {
+		return true
+	}
+	reversed := make([]string, len(players))
+	for i, pName := range players {
+		reversed[len(players)-1-i] = pName
+	}
+	return tName == strings.Join(reversed, "_")
+}
+
+// Len returns the number of steps of the replay, which are the cards played plus the hand just dealt
+func (r *Replay) Len() int {
+	return len(r.steps)
+}
+
+// Position returns the index of the step the cursor is on - 0 is the hand just dealt
+func (r *Replay) Position() int {
+	return r.position
+}
+
+// Current returns the step the cursor is on
+func (r *Replay) Current() Step {
+	return r.steps[r.position]
+}
+
+// Forward moves the cursor to the next step and returns true, or returns false if the cursor is on the last step
+func (r *Replay) Forward() bool {
+	if r.position == len(r.steps)-1 {
+		return false
+	}
+	r.position++
+	return true
+}
+
+// Back moves the cursor to the previous step and returns true, or returns false if the cursor is on the first step
+func (r *Replay) Back() bool {
+	if r.position == 0 {
+		return false
+	}
+	r.position--
+	return true
+}
+
+// Seek moves the cursor to the step with the index passed in
+func (r *Replay) Seek(position int) error {
+	if position < 0 || position >= len(r.steps) {
+		return fmt.Errorf("%w - The replay has no step %v", ErrInvalidHistory, position)
+	}
+	r.position = position
+	return nil
+}
+
+// Steps returns all the steps of the replay, e.g. to send them to a client which moves through them on its own
+func (r *Replay) Steps() []Step {
+	return r.steps
+}
@@ -0,0 +1,172 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the commands of the tests
+var ctx = context.Background()
+
+// playedGame creates a game with the options passed in, played by bots with the heuristic strategy, and plays its
+// first hand until it is closed
+func playedGame(t *testing.T, gName string, options scopone.GameOptions) *scopone.Game {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, err := s.NewGame(ctx, gName, options)
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for i := 0; i < options.NumberOfPlayers || (options.NumberOfPlayers == 0 && i < 4); i++ {
+		if _, err := s.AddBotToGame(ctx, gName, &bot.Heuristic{}); err != nil {
+			t.Fatalf("A bot could not be added to the game: %v", err)
+		}
+	}
+	s.NewHand(ctx, g)
+	for s.IsBotTurn(g) {
+		if _, move, _, _, err := s.PlayBot(ctx, g); err != nil {
+			t.Fatalf("The move %v returns an error %v", move, err)
+		}
+	}
+	if g.Hands[0].State != scopone.HandClosed {
+		t.Fatalf("The hand should be closed but is %v", g.Hands[0].State)
+	}
+	return g
+}
+
+func TestReplayOfClosedHands(t *testing.T) {
+	for _, options := range []scopone.GameOptions{
+		{Seed: 1},
+		{Seed: 2, Variant: scopone.ScoponeClassico},
+		{Seed: 3, Variant: scopone.ScoponeTrentino},
+		{Seed: 4, Variant: scopone.Scopa, NumberOfPlayers: 2},
+		{Seed: 5, Variant: scopone.Scopa, NumberOfPlayers: 3},
+	} {
+		g := playedGame(t, "TestReplayOfClosedHands", options)
+		r, err := ForHand(g, 0)
+		if err != nil {
+			t.Fatalf("The hand of variant %v should be replayed but returns the error %v", options.Variant, err)
+		}
+		// a step for the hand just dealt plus one for each card of the deck played by the players
+		cardsPlayed := 40 - len(g.Hands[0].History.CardPlaySequence[0].Table)
+		if r.Len() != cardsPlayed+1 {
+			t.Errorf("The replay of variant %v should have %v steps but has %v", options.Variant, cardsPlayed+1, r.Len())
+		}
+
+		// the replay without the teams of the game gives the same result
+		r, err = New(g.Hands[0].History, g.Variant)
+		if err != nil {
+			t.Fatalf("The history of variant %v should be replayed but returns the error %v", options.Variant, err)
+		}
+		if err := r.VerifyScore(g.Hands[0].Score); err != nil {
+			t.Errorf("The score of variant %v should be verified but returns the error %v", options.Variant, err)
+		}
+	}
+}
+
+func TestReplayCursor(t *testing.T) {
+	g := playedGame(t, "TestReplayCursor", scopone.GameOptions{Seed: 6})
+	r, err := ForHand(g, 0)
+	if err != nil {
+		t.Fatalf("The hand should be replayed but returns the error %v", err)
+	}
+	if r.Back() {
+		t.Errorf("The cursor should not move back from the first step")
+	}
+	first := r.Current()
+	for pName, hv := range first.HandViews {
+		if len(hv.PlayerCards) != 10 || hv.Status != scopone.HandActive {
+			t.Errorf("At the beginning %v should have 10 cards in an active hand but has %v in a hand %v",
+				pName, len(hv.PlayerCards), hv.Status)
+		}
+		if hv.ID != "1" || hv.GameName != g.Name {
+			t.Errorf("The view should be of hand 1 of game %v but is of hand %v of game %v", g.Name, hv.ID, hv.GameName)
+		}
+	}
+	history := g.Hands[0].History.CardPlaySequence
+	if !r.Forward() || r.Position() != 1 || r.Current().CardPlay.CardPlayed != history[0].CardPlayed {
+		t.Errorf("The second step should be the play of %v but is %v", history[0].CardPlayed, r.Current().CardPlay)
+	}
+	player := history[0].Player
+	if len(r.Current().HandViews[player].PlayerCards) != 9 {
+		t.Errorf("After the first card played %v should have 9 cards but has %v", player,
+			len(r.Current().HandViews[player].PlayerCards))
+	}
+	if !r.Back() || r.Position() != 0 || len(r.Current().HandViews[player].PlayerCards) != 10 {
+		t.Errorf("Moving back the cards of %v should be 10 again", player)
+	}
+
+	if err := r.Seek(r.Len() - 1); err != nil {
+		t.Fatalf("The cursor should move to the last step but returns the error %v", err)
+	}
+	if r.Forward() {
+		t.Errorf("The cursor should not move forward from the last step")
+	}
+	for pName, hv := range r.Current().HandViews {
+		if hv.Status != scopone.HandClosed {
+			t.Errorf("At the last step the hand should be closed for %v but is %v", pName, hv.Status)
+		}
+	}
+	if err := r.Seek(r.Len()); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("Seeking a step beyond the last should return ErrInvalidHistory but returns %v", err)
+	}
+}
+
+func TestReplayWithWrongScore(t *testing.T) {
+	g := playedGame(t, "TestReplayWithWrongScore", scopone.GameOptions{Seed: 7})
+	for tName, s := range g.Hands[0].Score {
+		s.Score = s.Score + 1
+		g.Hands[0].Score[tName] = s
+		break
+	}
+	if _, err := ForHand(g, 0); !errors.Is(err, ErrScoreMismatch) {
+		t.Errorf("A wrong score should return ErrScoreMismatch but returns %v", err)
+	}
+}
+
+func TestReplayOfTamperedHistory(t *testing.T) {
+	g := playedGame(t, "TestReplayOfTamperedHistory", scopone.GameOptions{Seed: 8})
+	history := g.Hands[0].History
+	sequence := make([]scopone.HandCardPlay, len(history.CardPlaySequence))
+	copy(sequence, history.CardPlaySequence)
+	// the fifth card is played by the player who plays the sixth
+	sequence[4].Player = sequence[5].Player
+	history.CardPlaySequence = sequence
+	if _, err := New(history, g.Variant); !errors.Is(err, ErrReplayDiverged) {
+		t.Errorf("A card played out of turn should return ErrReplayDiverged but returns %v", err)
+	}
+
+	history = g.Hands[0].History
+	history.Seed = history.Seed + 1
+	if _, err := New(history, g.Variant); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("A deck not shuffled with the seed should return ErrInvalidHistory but returns %v", err)
+	}
+
+	if _, err := ForHand(g, 1); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("A hand not played should return ErrInvalidHistory but returns %v", err)
+	}
+}
+
+func TestReplayOfActiveHand(t *testing.T) {
+	g := playedGame(t, "TestReplayOfActiveHand", scopone.GameOptions{Seed: 9})
+	g.Hands[0].State = scopone.HandActive
+	if _, err := ForClosedHand(g, 0); !errors.Is(err, ErrHandNotClosed) {
+		t.Errorf("A hand still played should return ErrHandNotClosed but returns %v", err)
+	}
+	if _, err := ForHand(g, 0); err != nil {
+		t.Errorf("A hand still played should be replayed but returns the error %v", err)
+	}
+}
+
+func TestSameTeam(t *testing.T) {
+	players := []string{"Player_1", "Player_3"}
+	if !sameTeam("Player_1_Player_3", players) || !sameTeam("Player_3_Player_1", players) {
+		t.Errorf("The team should be found with its players in any order")
+	}
+	if sameTeam("Player_1_Player_2", players) {
+		t.Errorf("A team with other players should not be found")
+	}
+}
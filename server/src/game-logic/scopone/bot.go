@@ -0,0 +1,82 @@
+package scopone
+
+import (
+	"context"
+	"fmt"
+
+	"go-scopone/src/game-logic/player"
+)
+
+// BotStrategy is the way a bot, i.e. a player controlled by the computer, chooses its moves
+type BotStrategy interface {
+	// Name returns the name of the strategy - it is stored with the bot so that the bot can be given back its
+	// strategy when its game is read from a store
+	Name() string
+	// ChooseMove returns the move the bot makes given the view of the hand it can see - it is called only when the
+	// bot is the current player and therefore the view contains the legal moves of the bot
+	ChooseMove(view HandPlayerView) Move
+}
+
+// AddBotToGame adds to a game a bot which plays with the strategy passed in - the bot takes the first free seat of
+// the game, or the seat of a player whose grace period has expired, and gets a name not used by any other player
+// in the Osteria, which is returned
+func (s *Scopone) AddBotToGame(ctx context.Context, gameName string, strategy BotStrategy) (botName string, err error) {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		err = fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+		return
+	}
+	for i := 1; ; i++ {
+		botName = fmt.Sprintf("Bot %v", i)
+		if _, found := s.Players[botName]; !found {
+			break
+		}
+	}
+	bot := player.New(botName)
+	bot.Bot = strategy.Name()
+	err = s.addToSeat(g, bot, AnySeat)
+	if err != nil {
+		return "", err
+	}
+	s.Players[botName] = bot
+	s.Bots[botName] = strategy
+	return botName, storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// IsBotTurn returns true if the current player of the active hand of the game is a bot
+func (s *Scopone) IsBotTurn(g *Game) bool {
+	if !IsCurrentHandActive(g) || g.handStopped() {
+		return false
+	}
+	_, isBot := s.Bots[currentPlayer(g).Name]
+	return isBot
+}
+
+// PlayBot makes the bot which is the current player of the game play the move chosen by its strategy
+// The bot sees the same view of the hand that a real player sees, plus the cards played so far in the hand,
+// which a real player sees on the table while they are played
+func (s *Scopone) PlayBot(ctx context.Context, g *Game) (botName string, move Move, handViews map[string]HandPlayerView,
+	finalTableTake FinalTableTake, err error) {
+	if !s.IsBotTurn(g) {
+		err = fmt.Errorf("%w - The current player of game %v is not a bot", ErrNotYourTurn, g.Name)
+		return
+	}
+	hand := currentHand(g)
+	botName = hand.CurrentPlayer.Name
+	view := buildHandView(hand, g)[botName]
+	view.History = publicHistory(hand.History)
+	move = s.Bots[botName].ChooseMove(view)
+	handViews, finalTableTake, err = s.Play(ctx, g, botName, move.CardPlayed, move.CardsTaken)
+	return
+}
+
+// publicHistory returns the history of the hand without the cards in the hands of the players, i.e. the cards
+// played and taken that all the players have seen
+func publicHistory(history HandHistory) HandHistory {
+	public := HandHistory{}
+	for _, cardPlay := range history.CardPlaySequence {
+		cardPlay.PlayersDecks = nil
+		public.CardPlaySequence = append(public.CardPlaySequence, cardPlay)
+	}
+	return public
+}
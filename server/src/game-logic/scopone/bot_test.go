@@ -0,0 +1,67 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+)
+
+// firstMoveBot is a bot which always plays the first of its legal moves
+type firstMoveBot struct{}
+
+func (b *firstMoveBot) Name() string { return "firstMove" }
+func (b *firstMoveBot) ChooseMove(view HandPlayerView) Move {
+	return view.LegalMoves[0]
+}
+
+func TestAddBotToGame(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	gName := "TestAddBotToGame"
+	g, _ := s.NewGame(ctx, gName, GameOptions{})
+	s.PlayerEnters(ctx, "Player_1", "")
+	s.AddPlayerToGame(ctx, "Player_1", gName)
+	s.PlayerReady(ctx, "Player_1", gName)
+	for i := 0; i < 3; i++ {
+		botName, err := s.AddBotToGame(ctx, gName, &firstMoveBot{})
+		if err != nil {
+			t.Fatalf("Bot %v could not be added: %v", i, err)
+		}
+		if s.Players[botName].Bot != "firstMove" {
+			t.Errorf("%v should be a bot with strategy firstMove but has %v", botName, s.Players[botName].Bot)
+		}
+	}
+	if len(g.Players) != 4 || g.State != GameOpen {
+		t.Errorf("The game should be open with 4 players but is %v with %v players", g.State, len(g.Players))
+	}
+	if _, err := s.AddBotToGame(ctx, gName, &firstMoveBot{}); !errors.Is(err, ErrGameFull) {
+		t.Errorf("Adding a bot to a full game should return ErrGameFull but returns %v", err)
+	}
+	if _, err := s.AddBotToGame(ctx, "A game that does not exist", &firstMoveBot{}); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("Adding a bot to a game that does not exist should return ErrGameNotFound but returns %v", err)
+	}
+	// the bots play until it is the turn of the real player
+	s.NewHand(ctx, g)
+	for s.IsBotTurn(g) {
+		s.PlayBot(ctx, g)
+	}
+	if currentPlayer(g).Name != "Player_1" {
+		t.Errorf("The bots should play until it is the turn of Player_1 but the current player is %v", currentPlayer(g).Name)
+	}
+	if _, _, _, _, err := s.PlayBot(ctx, g); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("A bot playing when it is the turn of a real player should return ErrNotYourTurn but returns %v", err)
+	}
+}
+
+func TestPublicHistory(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(s, "TestPublicHistory")
+	s.NewHand(ctx, g)
+	moves := g.LegalMoves()
+	s.Play(ctx, g, currentPlayer(g).Name, moves[0].CardPlayed, moves[0].CardsTaken)
+	history := publicHistory(currentHand(g).History)
+	if len(history.CardPlaySequence) != 1 {
+		t.Errorf("The public history should have 1 card play but has %v", len(history.CardPlaySequence))
+	}
+	if history.PlayerDecks != nil || history.CardPlaySequence[0].PlayersDecks != nil {
+		t.Errorf("The public history should not contain the cards of the players")
+	}
+}
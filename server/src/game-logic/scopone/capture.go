@@ -0,0 +1,160 @@
+package scopone
+
+import (
+	"fmt"
+
+	"go-scopone/src/game-logic/deck"
+)
+
+// IllegalPlayError is returned when a card play does not respect the rules of the game, e.g. the cards taken
+// are not on the table or do not match the card played
+// It is always recognized as ErrIllegalPlay and, if the card played is not in the hands of the player, also as
+// ErrCardNotInHand
+type IllegalPlayError struct {
+	Player     string
+	CardPlayed deck.Card
+	CardsTaken []deck.Card
+	Reason     string
+	Err        error
+}
+
+func (e *IllegalPlayError) Error() string {
+	return fmt.Sprintf("Player %v can not play %v taking %v: %v", e.Player, e.CardPlayed, e.CardsTaken, e.Reason)
+}
+
+// Is makes any IllegalPlayError recognized as ErrIllegalPlay
+func (e *IllegalPlayError) Is(target error) bool {
+	return target == ErrIllegalPlay
+}
+
+// Unwrap returns the more specific error, if any, which caused the play to be illegal
+func (e *IllegalPlayError) Unwrap() error {
+	return e.Err
+}
+
+// Move is a card play, i.e. a card played by a player with the cards it takes from the table
+type Move struct {
+	CardPlayed deck.Card   `json:"cardPlayed"`
+	CardsTaken []deck.Card `json:"cardsTaken"`
+}
+
+// LegalMoves returns all the legal moves that the current player of the current hand can make, i.e. all the cards
+// of the player, each with any of the captures it can make - if there is no active hand no move is returned
+func (game *Game) LegalMoves() []Move {
+	if !IsCurrentHandActive(game) {
+		return nil
+	}
+	hand := currentHand(game)
+	return legalMoves(hand.CurrentPlayer.Cards, hand.Table, game.Rules())
+}
+
+// legalMoves returns all the legal moves that can be made with some cards given the cards on the table and the rules
+func legalMoves(playerCards []deck.Card, table []deck.Card, rules Rules) (moves []Move) {
+	moves = make([]Move, 0)
+	for _, c := range playerCards {
+		captures := rules.Captures(c, table)
+		if len(captures) == 0 {
+			moves = append(moves, Move{CardPlayed: c, CardsTaken: []deck.Card{}})
+			continue
+		}
+		for _, capture := range captures {
+			moves = append(moves, Move{CardPlayed: c, CardsTaken: capture})
+		}
+	}
+	return
+}
+
+// CardValue returns the value of a card used to calculate the captures - the Napoli order is the same as the value
+// of the cards, i.e. Ace is 1, Two is 2 ... King is 10
+func CardValue(c deck.Card) int {
+	return napoliOrder[c.Type]
+}
+
+// legalCaptures returns all the combinations of cards on the table that can be taken playing cardPlayed
+// - if there are cards on the table with the same value of the card played, then only one of them can be taken
+// - otherwise any combination of cards on the table whose sum is the value of the card played can be taken
+// If no capture is possible an empty slice is returned and the card played has to be placed on the table
+func legalCaptures(cardPlayed deck.Card, table []deck.Card) (captures [][]deck.Card) {
+	value := CardValue(cardPlayed)
+	// the capture of a single card with the same value is always preferred to the sum combinations
+	for _, c := range table {
+		if CardValue(c) == value {
+			captures = append(captures, []deck.Card{c})
+		}
+	}
+	if len(captures) > 0 {
+		return
+	}
+	return sumCombinations(value, table, []deck.Card{})
+}
+
+// sumCombinations returns all the combinations of cards, taken from the cards passed in, whose sum is value
+func sumCombinations(value int, cards []deck.Card, combination []deck.Card) (combinations [][]deck.Card) {
+	for i, c := range cards {
+		v := CardValue(c)
+		if v > value {
+			continue
+		}
+		// copy for immutability since the same combination prefix is shared by different branches
+		newCombination := make([]deck.Card, len(combination), len(combination)+1)
+		copy(newCombination, combination)
+		newCombination = append(newCombination, c)
+		if v == value {
+			combinations = append(combinations, newCombination)
+			continue
+		}
+		combinations = append(combinations, sumCombinations(value-v, cards[i+1:], newCombination)...)
+	}
+	return
+}
+
+// sameCards returns true if the 2 slices contain the same cards, regardless of the order
+func sameCards(cards1 []deck.Card, cards2 []deck.Card) bool {
+	if len(cards1) != len(cards2) {
+		return false
+	}
+	for _, c := range cards1 {
+		if _, found := deck.Find(cards2, c); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePlay checks that the card played is in the hands of the player and that the cards taken are a legal
+// capture for the card played given the cards on the table and the rules
+// If a capture is possible the player is obliged to take, so playing a card without taking is illegal in that case
+func validatePlay(p string, playerCards []deck.Card, table []deck.Card, cardPlayed deck.Card, cardsTaken []deck.Card,
+	rules Rules) error {
+	illegalPlay := func(reason string) error {
+		return &IllegalPlayError{Player: p, CardPlayed: cardPlayed, CardsTaken: cardsTaken, Reason: reason}
+	}
+	if _, found := deck.Find(playerCards, cardPlayed); !found {
+		return &IllegalPlayError{Player: p, CardPlayed: cardPlayed, CardsTaken: cardsTaken,
+			Reason: "the card played is not in the hands of the player", Err: ErrCardNotInHand}
+	}
+	for i, c := range cardsTaken {
+		if _, found := deck.Find(table, c); !found {
+			return illegalPlay(fmt.Sprintf("the card %v is not on the table", c))
+		}
+		if _, found := deck.Find(cardsTaken[i+1:], c); found {
+			return illegalPlay(fmt.Sprintf("the card %v is taken more than once", c))
+		}
+	}
+	captures := rules.Captures(cardPlayed, table)
+	if len(cardsTaken) == 0 {
+		if len(captures) > 0 {
+			return illegalPlay("the card played can take some cards from the table and therefore it has to take them")
+		}
+		return nil
+	}
+	for _, capture := range captures {
+		if sameCards(capture, cardsTaken) {
+			return nil
+		}
+	}
+	if len(captures) > 0 && len(captures[0]) == 1 && len(cardsTaken) > 1 {
+		return illegalPlay("a card with the same value is on the table and has to be taken instead of a combination")
+	}
+	return illegalPlay("the cards taken do not match the value of the card played")
+}
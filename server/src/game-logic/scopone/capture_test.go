@@ -0,0 +1,196 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+)
+
+func TestLegalCapturesSameValue(t *testing.T) {
+	cardPlayed := deck.Card{Type: "Five", Suit: deck.Denari}
+	table := []deck.Card{
+		{Type: "Five", Suit: "Spade"},
+		{Type: "Two", Suit: "Coppe"},
+		{Type: "Three", Suit: "Bastoni"},
+		{Type: "Five", Suit: "Coppe"},
+	}
+	captures := legalCaptures(cardPlayed, table)
+	// the cards with the same value are preferred to the combination Two+Three
+	if len(captures) != 2 {
+		t.Errorf("There should be 2 captures but there are %v - %v", len(captures), captures)
+	}
+	for _, c := range captures {
+		if len(c) != 1 || c[0].Type != "Five" {
+			t.Errorf("Capture %v should be made of just one Five", c)
+		}
+	}
+}
+
+func TestLegalCapturesSum(t *testing.T) {
+	cardPlayed := deck.Card{Type: "Jack", Suit: deck.Denari}
+	table := []deck.Card{
+		{Type: "Ace", Suit: "Spade"},
+		{Type: "Seven", Suit: "Coppe"},
+		{Type: "Three", Suit: "Bastoni"},
+		{Type: "Four", Suit: "Coppe"},
+		{Type: "Four", Suit: "Spade"},
+	}
+	// Jack is 8: Ace+Seven, Ace+Three+Four (twice, one per Four), Four+Four
+	captures := legalCaptures(cardPlayed, table)
+	if len(captures) != 4 {
+		t.Errorf("There should be 4 captures but there are %v - %v", len(captures), captures)
+	}
+	for _, c := range captures {
+		sum := 0
+		for _, cc := range c {
+			sum = sum + CardValue(cc)
+		}
+		if sum != 8 {
+			t.Errorf("Capture %v should have sum 8 but has %v", c, sum)
+		}
+	}
+}
+
+func TestLegalCapturesNone(t *testing.T) {
+	cardPlayed := deck.Card{Type: "King", Suit: deck.Denari}
+	table := []deck.Card{
+		{Type: "Ace", Suit: "Spade"},
+		{Type: "Two", Suit: "Coppe"},
+	}
+	captures := legalCaptures(cardPlayed, table)
+	if len(captures) != 0 {
+		t.Errorf("There should be no captures but there are %v", captures)
+	}
+	captures = legalCaptures(cardPlayed, []deck.Card{})
+	if len(captures) != 0 {
+		t.Errorf("There should be no captures with an empty table but there are %v", captures)
+	}
+}
+
+func TestValidatePlay(t *testing.T) {
+	playerCards := []deck.Card{{Type: "Five", Suit: deck.Denari}, {Type: "Queen", Suit: deck.Denari}}
+	table := []deck.Card{
+		{Type: "Five", Suit: "Spade"},
+		{Type: "Two", Suit: "Coppe"},
+		{Type: "Three", Suit: "Bastoni"},
+	}
+	five := playerCards[0]
+	queen := playerCards[1]
+
+	// legal plays
+	if e := validatePlay("P", playerCards, table, five, []deck.Card{table[0]}, defaultRules()); e != nil {
+		t.Errorf("Taking a Five with a Five should be legal but we get %v", e)
+	}
+	if e := validatePlay("P", playerCards, table, queen, []deck.Card{}, defaultRules()); e != nil {
+		t.Errorf("Playing a Queen which can not take anything should be legal but we get %v", e)
+	}
+
+	// illegal plays
+	illegalPlays := []struct {
+		description string
+		cardPlayed  deck.Card
+		cardsTaken  []deck.Card
+	}{
+		{"card not in the hand of the player", deck.Card{Type: "Ace", Suit: "Coppe"}, []deck.Card{}},
+		{"card taken not on the table", five, []deck.Card{{Type: "Five", Suit: "Coppe"}}},
+		{"combination instead of same value", five, []deck.Card{table[1], table[2]}},
+		{"not taking when a capture is possible", five, []deck.Card{}},
+		{"cards taken not matching the value", queen, []deck.Card{table[1], table[2]}},
+		{"same card taken twice", five, []deck.Card{table[0], table[0]}},
+	}
+	for _, p := range illegalPlays {
+		e := validatePlay("P", playerCards, table, p.cardPlayed, p.cardsTaken, defaultRules())
+		var illegalPlay *IllegalPlayError
+		if !errors.As(e, &illegalPlay) {
+			t.Errorf("Play with %v should return an IllegalPlayError but returns %v", p.description, e)
+		}
+	}
+}
+
+func TestPlayIllegalCardIsRejected(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(scopone, "TestPlayIllegalCardIsRejected")
+	scopone.NewHand(ctx, g)
+	hand := currentHand(g)
+	player := currentPlayer(g)
+	card := player.Cards[0]
+	numberOfCards := len(player.Cards)
+
+	// try to take a card from an empty table
+	_, _, err := scopone.Play(ctx, g, player.Name, card, []deck.Card{{Type: "Ace", Suit: "Coppe"}})
+	var illegalPlay *IllegalPlayError
+	if !errors.As(err, &illegalPlay) {
+		t.Errorf("Taking cards from an empty table should return an IllegalPlayError but returns %v", err)
+	}
+	// the game is left unchanged
+	if len(player.Cards) != numberOfCards {
+		t.Errorf("Player should still have %v cards but has %v", numberOfCards, len(player.Cards))
+	}
+	if len(hand.Table) != 0 {
+		t.Errorf("The table should be still empty but has %v", hand.Table)
+	}
+	if len(hand.History.CardPlaySequence) != 0 {
+		t.Errorf("No card play should be recorded but we have %v", hand.History.CardPlaySequence)
+	}
+	if hand.CurrentPlayer.Name != player.Name {
+		t.Errorf("The current player should still be %v but is %v", player.Name, hand.CurrentPlayer.Name)
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	playerCards := []deck.Card{{Type: "Five", Suit: deck.Denari}, {Type: "Queen", Suit: deck.Denari}}
+	table := []deck.Card{
+		{Type: "Five", Suit: "Spade"},
+		{Type: "Five", Suit: "Coppe"},
+		{Type: "Three", Suit: "Bastoni"},
+	}
+	// the Five can take one of the two Fives, the Queen can not take anything
+	moves := legalMoves(playerCards, table, defaultRules())
+	if len(moves) != 3 {
+		t.Errorf("There should be 3 legal moves but there are %v - %v", len(moves), moves)
+	}
+	for _, m := range moves {
+		if e := validatePlay("P", playerCards, table, m.CardPlayed, m.CardsTaken, defaultRules()); e != nil {
+			t.Errorf("Move %v should be legal but we get %v", m, e)
+		}
+		if m.CardPlayed.Type == "Queen" && len(m.CardsTaken) != 0 {
+			t.Errorf("The Queen should not take any card but takes %v", m.CardsTaken)
+		}
+	}
+}
+
+func TestLegalMovesInHandView(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(scopone, "TestLegalMovesInHandView")
+
+	// no legal moves before the first hand is started
+	if moves := g.LegalMoves(); moves != nil {
+		t.Errorf("There should be no legal moves before the hand starts but there are %v", moves)
+	}
+
+	_, handViews, _ := scopone.NewHand(ctx, g)
+	player := currentPlayer(g)
+	// with an empty table each card can be played without taking anything
+	if len(g.LegalMoves()) != len(player.Cards) {
+		t.Errorf("There should be %v legal moves but there are %v", len(player.Cards), len(g.LegalMoves()))
+	}
+	for pName, hv := range handViews {
+		if pName == player.Name {
+			if len(hv.LegalMoves) != len(player.Cards) {
+				t.Errorf("The current player %v should see %v legal moves but sees %v", pName, len(player.Cards), len(hv.LegalMoves))
+			}
+		} else if len(hv.LegalMoves) != 0 {
+			t.Errorf("Player %v is not the current player and should see no legal moves but sees %v", pName, hv.LegalMoves)
+		}
+	}
+
+	// the legal moves of the next player are calculated against the new table
+	handViews, _, _ = scopone.Play(ctx, g, player.Name, player.Cards[0], []deck.Card{})
+	next := currentPlayer(g)
+	for _, m := range handViews[next.Name].LegalMoves {
+		if e := validatePlay(next.Name, next.Cards, currentHand(g).Table, m.CardPlayed, m.CardsTaken, g.Rules()); e != nil {
+			t.Errorf("Move %v should be legal but we get %v", m, e)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package scopone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Errors returned by the Osteria and by the Games - the servers can use errors.Is to recognize them and decide
+// which message to send back to the player whose command has failed
+var (
+	ErrEmptyPlayerName        = errors.New("The name of the player is empty")
+	ErrPlayerNotFound         = errors.New("Player not found")
+	ErrPlayerAlreadyInOsteria = errors.New("Player already in the Osteria")
+	ErrPlayerAlreadyLeft      = errors.New("Player already left the Osteria")
+	ErrPlayerNotPlaying       = errors.New("Player not playing any game")
+	ErrPlayerAlreadyInGame    = errors.New("Player already in the game")
+	ErrAlreadyObserving       = errors.New("Player already observing the game")
+	ErrInvalidPlayerStatus    = errors.New("Invalid status of the player")
+	ErrGameNotFound           = errors.New("Game not found")
+	ErrGameAlreadyPresent     = errors.New("Game with the same name already present")
+	ErrGameFull               = errors.New("Game has already all its players")
+	ErrTeamFull               = errors.New("Team has already all its players")
+	ErrInvalidSeat            = errors.New("Invalid seat")
+	ErrSeatTaken              = errors.New("Seat already taken")
+	ErrPlayersNotReady        = errors.New("Not all the players are ready")
+	ErrGameStarted            = errors.New("Game already started")
+	ErrGameFinished           = errors.New("Game already finished")
+	ErrInvalidGameOptions     = errors.New("Invalid options for the game")
+	ErrGameNotStarted         = errors.New("Game not started")
+	ErrHandStillActive        = errors.New("The current hand is still active")
+	ErrNotYourTurn            = errors.New("Not your turn")
+	ErrInvalidCard            = errors.New("Invalid card")
+	ErrCardNotInHand          = errors.New("Card not in the hands of the player")
+	ErrIllegalPlay            = errors.New("Illegal play")
+	ErrInconsistentHand       = errors.New("Inconsistent state of the hand")
+	ErrInvalidReconnectToken  = errors.New("Invalid reconnect token")
+	ErrTurnNotExpired         = errors.New("Turn not expired")
+	ErrHiddenFromObservers    = errors.New("Hidden from the observers of the game")
+	ErrNotInvited             = errors.New("Not invited to the private game")
+	ErrStoreFailure           = errors.New("Store failure")
+	// ErrStoreUnavailable is wrapped by the stores in the errors returned when they can not be reached
+	ErrStoreUnavailable = errors.New("Store unavailable")
+)
+
+// storeFailure wraps an error returned by a store so that it can be recognized as ErrStoreFailure
+func storeFailure(err error) error {
+	return fmt.Errorf("%w - %v", ErrStoreFailure, err)
+}
+
+// storeError returns the error of a command whose changes the store has failed to write
+// If the store can not be reached, or does not answer in time, the Osteria degrades to keep the changes only in
+// memory: the failure is logged and no error is returned, since the games are written again as a whole, or as the
+// events not yet written, with the next change once the store is back
+func storeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrStoreUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Store unavailable, the changes are kept only in memory: %v\n", err)
+		return nil
+	}
+	return storeFailure(err)
+}
@@ -0,0 +1,181 @@
+package scopone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+)
+
+// failingStore is a store which always fails with its error
+type failingStore struct {
+	DoNothingStore
+	err error
+}
+
+func (store *failingStore) AddPlayerEntry(ctx context.Context, player *player.Player) error {
+	return store.err
+}
+
+func (store *failingStore) WriteGame(ctx context.Context, game *Game) error {
+	return store.err
+}
+
+func (store *failingStore) ReadOpenGames(ctx context.Context) (map[string]*Game, map[string]*player.Player, error) {
+	return nil, nil, store.err
+}
+
+func TestPlayErrors(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(scopone, "TestPlayErrors")
+	aCard := deck.Card{Type: "Ace", Suit: deck.Denari}
+
+	// no hand started yet
+	_, _, err := scopone.Play(ctx, g, "Player_1", aCard, []deck.Card{})
+	if !errors.Is(err, ErrGameNotStarted) {
+		t.Errorf("Playing before the hand is started should return ErrGameNotStarted but returns %v", err)
+	}
+
+	scopone.NewHand(ctx, g)
+	current := currentPlayer(g)
+	next := nextPlayer(g)
+
+	errorCases := []struct {
+		description string
+		player      string
+		card        deck.Card
+		expected    error
+	}{
+		{"player not in the Osteria", "Player_not_present", aCard, ErrPlayerNotFound},
+		{"player not the current player", next.Name, next.Cards[0], ErrNotYourTurn},
+		{"card with no suit", current.Name, deck.Card{Type: "Ace"}, ErrInvalidCard},
+	}
+	for _, c := range errorCases {
+		_, _, err := scopone.Play(ctx, g, c.player, c.card, []deck.Card{})
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Play with %v should return %v but returns %v", c.description, c.expected, err)
+		}
+	}
+
+	// a card not in the hands of the player is both an illegal play and a card not in hand
+	var cardNotInHand deck.Card
+	for _, c := range next.Cards {
+		cardNotInHand = c
+	}
+	_, _, err = scopone.Play(ctx, g, current.Name, cardNotInHand, []deck.Card{})
+	if !errors.Is(err, ErrCardNotInHand) || !errors.Is(err, ErrIllegalPlay) {
+		t.Errorf("Playing a card not in hand should return ErrCardNotInHand and ErrIllegalPlay but returns %v", err)
+	}
+
+	// a player not playing any game
+	scopone.PlayerEnters(ctx, "Player_not_playing", "")
+	_, _, err = scopone.Play(ctx, g, "Player_not_playing", aCard, []deck.Card{})
+	if !errors.Is(err, ErrPlayerNotPlaying) {
+		t.Errorf("Playing for a player not in a game should return ErrPlayerNotPlaying but returns %v", err)
+	}
+}
+
+func TestOsteriaErrors(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	gName := "TestOsteriaErrors"
+	g := newTestGameFactory(scopone, gName)
+
+	if err := scopone.Close(ctx, "A game that does not exist", "Player_1"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("Closing a game that does not exist should return ErrGameNotFound but returns %v", err)
+	}
+	if _, err := scopone.NewGame(ctx, gName, GameOptions{}); !errors.Is(err, ErrGameAlreadyPresent) {
+		t.Errorf("Creating a game twice should return ErrGameAlreadyPresent but returns %v", err)
+	}
+	if _, _, err := scopone.NewHand(ctx, nil); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("A new hand for no game should return ErrGameNotFound but returns %v", err)
+	}
+	scopone.NewHand(ctx, g)
+	if _, _, err := scopone.NewHand(ctx, g); !errors.Is(err, ErrHandStillActive) {
+		t.Errorf("A new hand while the current one is active should return ErrHandStillActive but returns %v", err)
+	}
+	if _, _, err := scopone.RemovePlayer("Player_not_present"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Removing a player not present should return ErrPlayerNotFound but returns %v", err)
+	}
+	scopone.RemovePlayer("Player_1")
+	if _, _, err := scopone.RemovePlayer("Player_1"); !errors.Is(err, ErrPlayerAlreadyLeft) {
+		t.Errorf("Removing a player twice should return ErrPlayerAlreadyLeft but returns %v", err)
+	}
+	scopone.PlayerEnters(ctx, "Player_5", "")
+	scopone.NewGame(ctx, "Another game", GameOptions{})
+	scopone.AddPlayerToGame(ctx, "Player_5", "Another game")
+	if err := scopone.AddPlayerToGame(ctx, "Player_5", "Another game"); !errors.Is(err, ErrPlayerAlreadyInGame) {
+		t.Errorf("Adding a player twice should return ErrPlayerAlreadyInGame but returns %v", err)
+	}
+	if err := scopone.AddPlayerToGame(ctx, "Player_5", gName); !errors.Is(err, ErrGameFull) {
+		t.Errorf("Adding a fifth player should return ErrGameFull but returns %v", err)
+	}
+}
+
+func TestCalculateStateWithInvalidPlayerStatus(t *testing.T) {
+	game := NewGame()
+	p := player.New("Player_1")
+	game.AddPlayer(p)
+	p.Status = player.PlayerObserving
+	err := game.CalculateState()
+	if !errors.Is(err, ErrInvalidPlayerStatus) {
+		t.Errorf("A player observing in the players of the game should return ErrInvalidPlayerStatus but returns %v", err)
+	}
+}
+
+func TestStoreFailure(t *testing.T) {
+	store := &failingStore{err: errors.New("the store is down")}
+	scopone := New(ctx, store, store)
+
+	_, err := scopone.PlayerEnters(ctx, "Player_1", "")
+	if !errors.Is(err, ErrStoreFailure) {
+		t.Errorf("Entering the Osteria with a failing store should return ErrStoreFailure but returns %v", err)
+	}
+	// the player is not left in the Osteria if the entry could not be stored
+	if _, found := scopone.Players["Player_1"]; found {
+		t.Errorf("Player_1 should not be in the Osteria since the store failed")
+	}
+
+	_, err = scopone.NewGame(ctx, "TestStoreFailure", GameOptions{})
+	if !errors.Is(err, ErrStoreFailure) {
+		t.Errorf("Creating a game with a failing store should return ErrStoreFailure but returns %v", err)
+	}
+	if _, found := scopone.Games["TestStoreFailure"]; found {
+		t.Errorf("The game should not be in the Osteria since the store failed")
+	}
+}
+
+func TestStoreUnavailable(t *testing.T) {
+	for _, storeErr := range []error{
+		fmt.Errorf("%w - the store does not answer", ErrStoreUnavailable),
+		context.DeadlineExceeded,
+	} {
+		store := &failingStore{err: storeErr}
+		// the Osteria starts empty when the games can not be read
+		scopone := New(ctx, store, store)
+		if scopone.Games == nil || scopone.Players == nil {
+			t.Fatalf("The Osteria should start with no games when the store is unavailable")
+		}
+
+		// the Osteria keeps the changes in memory when the store is unavailable
+		if _, err := scopone.NewGame(ctx, "TestStoreUnavailable", GameOptions{}); err != nil {
+			t.Errorf("Creating a game with the store unavailable (%v) should not fail but returns %v", storeErr, err)
+		}
+		for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+			if _, err := scopone.PlayerEnters(ctx, pName, ""); err != nil {
+				t.Errorf("%v should enter the Osteria with the store unavailable but the error is %v", pName, err)
+			}
+			if err := scopone.AddPlayerToGame(ctx, pName, "TestStoreUnavailable"); err != nil {
+				t.Errorf("%v should join the game with the store unavailable but the error is %v", pName, err)
+			}
+			if err := scopone.PlayerReady(ctx, pName, "TestStoreUnavailable"); err != nil {
+				t.Errorf("%v should be ready with the store unavailable but the error is %v", pName, err)
+			}
+		}
+		if _, _, err := scopone.NewHand(ctx, scopone.Games["TestStoreUnavailable"]); err != nil {
+			t.Errorf("A new hand should start with the store unavailable but the error is %v", err)
+		}
+	}
+}
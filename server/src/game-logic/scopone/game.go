@@ -2,6 +2,8 @@ package scopone
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/player"
@@ -17,10 +19,74 @@ const (
 	TeamsForming  State = "teamsForming" // it has some players but not all
 	GameOpen      State = "open"
 	GameSuspended State = "suspended"
+	GameFinished  State = "finished" // a team has reached the target score and won the game
 	GameClosed    State = "closed"
 )
 
-// Game represents a match of Scopone
+// GameOptions are the options which can be set when a game is created
+type GameOptions struct {
+	// TargetScore is the score a team has to reach to win the game, e.g. 11, 21 or 31 - if it is 0 the game
+	// goes on until it is closed
+	TargetScore int `json:"targetScore"`
+	// Variant is the variant of the rules used in the game - if it is empty the DefaultVariant is used
+	Variant Variant `json:"variant"`
+	// NumberOfPlayers is the number of players of the game, 4 in 2 teams of 2 for Scopone while Scopa can be played
+	// also by 2 or 3 players, each one on its own - if it is 0 the game has 4 players
+	NumberOfPlayers int `json:"numberOfPlayers"`
+	// Seed, if not 0, is the seed from which the decks of all the hands of the game are shuffled, so that the same
+	// hands can be played again, e.g. in the different tables of a tournament - the decks of a game with a seed can
+	// be predicted from the cards dealt, see deck.ShuffleWithSeed
+	Seed int64 `json:"seed"`
+	// MoveTimeLimit is the number of seconds each player has to play a card, after which a card is played for the
+	// player - if it is 0 the players have no time limit
+	MoveTimeLimit int `json:"moveTimeLimit,omitempty"`
+	// ObserverMode is what the observers of the game see of its hands - if it is empty the DefaultObserverMode is
+	// used
+	ObserverMode ObserverMode `json:"observerMode,omitempty"`
+	// ObserverDelay is the number of moves the observers are behind the players with ObserveDelayed - if it is 0
+	// the DefaultObserverDelay is used
+	ObserverDelay int `json:"observerDelay,omitempty"`
+	// Password, if not empty, makes the game private and lets anybody who knows it join or observe the game, as an
+	// invite code - the game keeps only its hash, see Game.PasswordHash
+	Password string `json:"password,omitempty"`
+	// PasswordHash is the hash of the password of a game read from a store, which is used when there is no Password
+	PasswordHash []byte `json:"passwordHash,omitempty"`
+	// Invited are the players who can see and join the game without the password - if it is not empty the game is
+	// private
+	Invited []string `json:"invited,omitempty"`
+	// NoTeamChatInHands, if true, keeps the players of a team from chatting with each other while a hand is played,
+	// so that they can not tell their partner their cards
+	NoTeamChatInHands bool `json:"noTeamChatInHands,omitempty"`
+}
+
+// validate checks that the options are valid
+func (o GameOptions) validate() error {
+	if o.TargetScore < 0 {
+		return fmt.Errorf("%w - The target score can not be negative but is %v", ErrInvalidGameOptions, o.TargetScore)
+	}
+	if o.MoveTimeLimit < 0 {
+		return fmt.Errorf("%w - The move time limit can not be negative but is %v", ErrInvalidGameOptions, o.MoveTimeLimit)
+	}
+	if err := validateObserverMode(o.ObserverMode, o.ObserverDelay); err != nil {
+		return err
+	}
+	if err := validatePassword(o.Password); err != nil {
+		return err
+	}
+	if err := validateInvitations(o.Invited); err != nil {
+		return err
+	}
+	rules, err := RulesFor(o.Variant)
+	if err != nil {
+		return err
+	}
+	if o.NumberOfPlayers != 0 && !rules.AllowsNumberOfPlayers(o.NumberOfPlayers) {
+		return fmt.Errorf("%w - %v can not be played by %v players", ErrInvalidGameOptions, rules.Variant(), o.NumberOfPlayers)
+	}
+	return nil
+}
+
+// Game represents a match of Scopone, or of Scopa, played by 2 teams of 2 players or by 2 or 3 players on their own
 type Game struct {
 	Name      string                    `json:"name"`
 	Hands     []*Hand                   `json:"hands"`
@@ -30,29 +96,174 @@ type Game struct {
 	Score     map[string]int            `json:"score"`
 	State     State                     `json:"state"`
 	ClosedBy  string                    `json:"closedBy"`
-	History   []*HandHistory            `json:"-"`
+	// TargetScore is the score a team has to reach to win the game - if it is 0 the game has no target score
+	TargetScore int `json:"targetScore"`
+	// Winners are the players of the team which has won the game, set when the game is finished
+	Winners []string `json:"winners,omitempty"`
+	// Variant is the variant of the rules used in the game - only the variant is stored since the rules are
+	// retrieved from it
+	Variant Variant `json:"variant"`
+	// NumberOfPlayers is the number of players of the game - games stored when all games had 4 players have it 0
+	NumberOfPlayers int `json:"numberOfPlayers"`
+	// MoveTimeLimit is the number of seconds each player has to play a card - if it is 0 there is no limit
+	MoveTimeLimit int `json:"moveTimeLimit,omitempty"`
+	// ObserverMode is what the observers see of the hands and ObserverDelay the number of moves they are behind the
+	// players with ObserveDelayed, see Observation
+	ObserverMode  ObserverMode `json:"observerMode,omitempty"`
+	ObserverDelay int          `json:"observerDelay,omitempty"`
+	// Ready are the players who have confirmed to be ready to start the game, with their teams as they are - the
+	// first hand can be dealt only when all the players are ready, the bots being always ready
+	Ready map[string]bool `json:"ready,omitempty"`
+	// SeatsLeft are the players who have left their seat in the middle of the game, whose seats wait for a player
+	// who takes them, see LeaveSeat
+	SeatsLeft map[string]bool `json:"seatsLeft,omitempty"`
+	// AbandonVotes are the players who have voted to abandon the game, see VoteToAbandon
+	AbandonVotes map[string]bool `json:"abandonVotes,omitempty"`
+	// AbandonedBy is the name of the team which has abandoned the game, losing it by forfeit
+	AbandonedBy string `json:"abandonedBy,omitempty"`
+	// Seated are the times the players have taken their seats, so that a player who takes the seat of another player
+	// does not get what the team has said before - they are kept by the stores but not sent to the players
+	Seated map[string]time.Time `json:"-"`
+	// Private is true if the game can be seen and joined only by the players Invited and by the players who know its
+	// password, see Admits - only the bcrypt hash of the password is kept, and it is not sent to the players
+	Private      bool     `json:"private,omitempty"`
+	Invited      []string `json:"invited,omitempty"`
+	PasswordHash []byte   `json:"-"`
+	// NoTeamChatInHands is true if the players of a team can not chat with each other while a hand is played, see
+	// TeamChatAllowed
+	NoTeamChatInHands bool `json:"noTeamChatInHands,omitempty"`
+	// Seed is the seed of the game, if any - it is not sent to the players since it allows to know the cards of
+	// all the hands of the game
+	Seed    int64          `json:"-"`
+	History []*HandHistory `json:"-"`
+	rules   Rules
+	// mu is the lock which makes the commands of the game run one at a time - see LockGame
+	mu sync.Mutex
 }
 
 // NewGame game
 func NewGame() *Game {
+	return newGameWithPlayers(4)
+}
+
+// newGameWithPlayers creates a game for a number of players - with 4 players there are 2 teams of 2 players while
+// with 2 or 3 players each player is a team on its own
+func newGameWithPlayers(numberOfPlayers int) *Game {
 	fmt.Println("A new Game is created")
 	g := Game{}
-	g.Teams = make([]*team.Team, 2)
-	g.Teams[0] = team.New()
-	g.Teams[1] = team.New()
+	g.NumberOfPlayers = numberOfPlayers
+	numberOfTeams := numberOfPlayers
+	teamSize := 1
+	if numberOfPlayers == 4 {
+		numberOfTeams = 2
+		teamSize = 2
+	}
+	g.Teams = make([]*team.Team, numberOfTeams)
+	for i := range g.Teams {
+		g.Teams[i] = team.NewWithSize(teamSize)
+	}
 	g.Players = make(map[string]*player.Player)
 	g.Observers = make(map[string]*player.Player)
 	g.Score = make(map[string]int)
 	g.Hands = make([]*Hand, 0)
 	g.State = GameCreated
+	g.Variant = DefaultVariant
+	g.rules = defaultRules()
 	return &g
 }
 
-// Suspend suspends the game
+// TeamChatAllowed returns true if the players of a team of the game can chat with each other now, i.e. unless the
+// game keeps the teams from chatting while a hand is played and a hand is being played
+func (game *Game) TeamChatAllowed() bool {
+	return !game.NoTeamChatInHands || !IsCurrentHandActive(game)
+}
+
+// Rules returns the rules of the game
+// Games read from a store have only the variant set and so the rules are retrieved from it the first time they
+// are needed - games stored before the variants were introduced have no variant and use the DefaultVariant
+func (game *Game) Rules() Rules {
+	if game.rules != nil {
+		return game.rules
+	}
+	rules, err := RulesFor(game.Variant)
+	if err != nil {
+		fmt.Printf("Game %v has an unknown variant, the default rules are used: %v\n", game.Name, err)
+		rules = defaultRules()
+	}
+	game.rules = rules
+	return rules
+}
+
+// setRules sets the rules of the game
+func (game *Game) setRules(rules Rules) {
+	game.rules = rules
+	game.Variant = rules.Variant()
+}
+
+// seats returns the number of players of the game
+func (game *Game) seats() int {
+	if game.NumberOfPlayers == 0 {
+		return 4
+	}
+	return game.NumberOfPlayers
+}
+
+// seatingOrder returns the players of the game in the order they sit at the table, which is also the order they
+// play - the players of the same team do not sit next to each other, so the order is the first player of each team,
+// then the second player of each team and so on
+func (game *Game) seatingOrder() []*player.Player {
+	players := make([]*player.Player, 0)
+	for j := range game.Teams[0].Players {
+		for _, t := range game.Teams {
+			players = append(players, t.Players[j])
+		}
+	}
+	return players
+}
+
+// Suspend suspends the game - a game which is finished or closed can not be suspended
 func (game *Game) Suspend() {
+	if game.State == GameFinished || game.State == GameClosed {
+		return
+	}
 	game.State = GameSuspended
 }
 
+// over returns true if the game is finished or closed, i.e. if no more hands can be played in it
+func (game *Game) over() bool {
+	return game.State == GameFinished || game.State == GameClosed
+}
+
+// checkFinished sets the game as finished if a team has reached the target score with more points than any other
+// team - if more teams have reached the target score with the same points, then no team wins and more hands have
+// to be played until one team has more points than the others
+func (game *Game) checkFinished() {
+	if game.TargetScore == 0 {
+		return
+	}
+	var leader *team.Team
+	leaderScore := -1
+	tie := false
+	for _, t := range game.Teams {
+		score := game.Score[team.Name(t)]
+		if score > leaderScore {
+			leader = t
+			leaderScore = score
+			tie = false
+		} else if score == leaderScore {
+			tie = true
+		}
+	}
+	if leaderScore < game.TargetScore || tie {
+		return
+	}
+	game.Winners = make([]string, 0)
+	for _, p := range leader.Players {
+		game.Winners = append(game.Winners, p.Name)
+	}
+	game.State = GameFinished
+}
+
 // Close the game and sets all other players as not playing
 // this means that if just ONE player leaves the game, all other players leave it
 func (game *Game) Close(playerClosing string) {
@@ -86,6 +297,10 @@ type Hand struct {
 	Table         []deck.Card          `json:"-"`
 	Score         map[string]TeamScore `json:"-"`
 	History       HandHistory          `json:"-"`
+	// DealtCards is the number of cards of the deck already dealt to the players or to the table
+	DealtCards int `json:"-"`
+	// TurnStarted is when the turn of the current player has started, see TurnTimer
+	TurnStarted time.Time `json:"turnStarted"`
 }
 
 // HandCardPlay represents a single card played by a player with the cards it took
@@ -101,45 +316,134 @@ type HandCardPlay struct {
 type HandHistory struct {
 	PlayerDecks      map[string][]deck.Card `json:"playerDecks"`
 	CardPlaySequence []HandCardPlay         `json:"cardPlaySequence"`
+	// Seed is the seed with which the deck has been shuffled - it is 0 if the deck has not been shuffled with a seed
+	Seed int64 `json:"seed"`
+	// Deck is the deck of the hand in the order the cards have been dealt
+	Deck []deck.Card `json:"deck"`
 }
 
 // AddPlayer adds a player to a game and to one of the 2 teams
 func (game *Game) AddPlayer(p *player.Player) error {
-	if len(game.Players) == 4 {
+	return game.addPlayerToSeat(p, AnySeat)
+}
+
+// addPlayerToSeat adds a player to a game in the seat chosen or, with AnySeat, in the first seat free
+func (game *Game) addPlayerToSeat(p *player.Player, seat int) error {
+	if len(game.Players) == game.seats() {
 		var playerNames string
 		for pName := range game.Players {
 			playerNames = playerNames + " " + pName
 		}
-		return fmt.Errorf("Game has already 4 Players: %v", playerNames)
+		return fmt.Errorf("%w - Game has already %v Players: %v", ErrGameFull, game.seats(), playerNames)
 	}
 	// the same player can not be added twice to the same game
 	_, pFound := game.Players[p.Name]
 	if pFound {
-		return fmt.Errorf("Player %v is already present in game %v", p.Name, game.Name)
-	}
-	// the player fills the first slot free in the teams - this allows a player to reenter a game at his place
-	switch noOfPlayer := len(game.Players); noOfPlayer {
-	case 0:
-		game.Teams[0].Players[0] = p
-	case 1:
-		game.Teams[0].Players[1] = p
-	case 2:
-		game.Teams[1].Players[0] = p
-	case 3:
-		game.Teams[1].Players[1] = p
+		return fmt.Errorf("%w - Player %v is already present in game %v", ErrPlayerAlreadyInGame, p.Name, game.Name)
+	}
+	// the player who does not choose fills the first slot free in the teams, first all the slots of the first team,
+	// then the slots of the second team and so on - this allows a player to reenter a game at his place
+	if seat == AnySeat {
+		seat, _ = game.freeSeat(0, game.seats())
+	}
+	err := game.sit(p, seat)
+	if err != nil {
+		return err
 	}
-	game.Players[p.Name] = p
 	p.Status = player.PlayerPlaying
-	game.CalculateState()
+	game.seatedNow(p.Name)
+	return game.CalculateState()
+}
+
+// seatedNow records that the player has taken the seat now
+func (game *Game) seatedNow(pName string) {
+	if game.Seated == nil {
+		game.Seated = make(map[string]time.Time)
+	}
+	game.Seated[pName] = time.Now()
+}
+
+// sit makes a player sit in a seat of the game, which has to be free
+func (game *Game) sit(p *player.Player, seat int) error {
+	t, slot, err := game.slot(seat)
+	if err != nil {
+		return err
+	}
+	if t.Players[slot] != nil {
+		return fmt.Errorf("%w - Seat %v of game %v is taken by %v", ErrSeatTaken, seat, game.Name, t.Players[slot].Name)
+	}
+	t.Players[slot] = p
+	game.Players[p.Name] = p
 	return nil
 }
 
+// replacePlayer makes a player sit in the seat of another player, with the cards of the player replaced
+// The team of the seat has a new name, made of the names of its players, and so its score in the game is moved to
+// the new name, while the scores of the hands already closed keep the names of the teams which have played them
+// The card plays of the current hand, if the hand is still active, are moved to the new player as well, so that
+// the history of the hand is the history of the seat
+func (game *Game) replacePlayer(replaced *player.Player, p *player.Player) {
+	for _, t := range game.Teams {
+		for i, tp := range t.Players {
+			if tp == nil || tp.Name != replaced.Name {
+				continue
+			}
+			oldName := team.Name(t)
+			t.Players[i] = p
+			if score, found := game.Score[oldName]; found {
+				delete(game.Score, oldName)
+				game.Score[team.Name(t)] = score
+			}
+		}
+	}
+	delete(game.Players, replaced.Name)
+	delete(game.Ready, replaced.Name)
+	delete(game.AbandonVotes, replaced.Name)
+	if p.Name != replaced.Name {
+		// the player who takes back the seat left keeps the time of the seat
+		delete(game.Seated, replaced.Name)
+		game.seatedNow(p.Name)
+	}
+	game.Players[p.Name] = p
+	p.Cards = replaced.Cards
+	replaced.Cards = nil
+	for _, h := range game.Hands {
+		if h.FirstPlayer != nil && h.FirstPlayer.Name == replaced.Name {
+			h.FirstPlayer = p
+		}
+		if h.CurrentPlayer != nil && h.CurrentPlayer.Name == replaced.Name {
+			h.CurrentPlayer = p
+		}
+	}
+	if h := currentHand(game); h != nil && h.State == HandActive {
+		h.History.renamePlayer(replaced.Name, p.Name)
+	}
+}
+
+// renamePlayer gives the cards and the card plays of a player of the hand to another player
+func (history *HandHistory) renamePlayer(oldName string, newName string) {
+	if cards, found := history.PlayerDecks[oldName]; found {
+		delete(history.PlayerDecks, oldName)
+		history.PlayerDecks[newName] = cards
+	}
+	for i := range history.CardPlaySequence {
+		cardPlay := &history.CardPlaySequence[i]
+		if cardPlay.Player == oldName {
+			cardPlay.Player = newName
+		}
+		if cards, found := cardPlay.PlayersDecks[oldName]; found {
+			delete(cardPlay.PlayersDecks, oldName)
+			cardPlay.PlayersDecks[newName] = cards
+		}
+	}
+}
+
 // AddObserver adds an Observer to a game
 func (game *Game) AddObserver(p *player.Player) error {
 	// the same observer can not be added twice to the same game
 	_, oFound := game.Observers[p.Name]
 	if oFound {
-		return fmt.Errorf("%v is already observing game %v", p.Name, game.Name)
+		return fmt.Errorf("%w - %v is already observing game %v", ErrAlreadyObserving, p.Name, game.Name)
 	}
 	game.Observers[p.Name] = p
 	p.Status = player.PlayerObserving
@@ -147,31 +451,33 @@ func (game *Game) AddObserver(p *player.Player) error {
 }
 
 // CalculateState calculates the sate of the game
-func (game *Game) CalculateState() {
+// An error wrapping ErrInvalidPlayerStatus is returned if one of the players has a status which is not expected
+// for a player of a game, in which case the state of the game is left unchanged
+func (game *Game) CalculateState() error {
 	if len(game.Players) == 0 {
 		game.State = GameCreated
-		return
+		return nil
 	}
-	if game.State == GameClosed {
-		return
+	if game.State == GameClosed || game.State == GameFinished {
+		return nil
 	}
 	for kP := range game.Players {
 		p := game.Players[kP]
 		if p.Status == player.PlayerLeftOsteria {
 			game.State = GameSuspended
-			return
+			return nil
 		}
 		if p.Status != player.PlayerPlaying && p.Status != player.PlayerLookingAtHandResult {
-			msg := fmt.Sprintf(`Player %v in game %v has state "%v" which is never expected to happen 
-			since player in a game should either be playing or be suspended`, p.Name, game.Name, p.Status)
-			panic(msg)
+			return fmt.Errorf(`%w - Player %v in game %v has state "%v" which is never expected to happen 
+			since player in a game should either be playing or be suspended`, ErrInvalidPlayerStatus, p.Name, game.Name, p.Status)
 		}
 	}
-	if len(game.Players) == 4 {
+	if len(game.Players) == game.seats() && (len(game.Hands) > 0 || game.allReady()) {
 		game.State = GameOpen
-		return
+		return nil
 	}
 	game.State = TeamsForming
+	return nil
 }
 
 // HandPlayerView is the data set that a Player can see of a running hand
@@ -192,6 +498,29 @@ type HandPlayerView struct {
 	OurFinalHandScore     int         `json:"ourFinalScore"`
 	TheirFinalHandScore   int         `json:"theirFinalScore"`
 	History               HandHistory `json:"history,omitempty"`
+	// LegalMoves are the moves the player can make - they are set only for the current player of an active hand
+	LegalMoves []Move `json:"legalMoves,omitempty"`
+	// Teams are the views of all the teams of the game - "Our" and "Their" fields describe only the team of the
+	// player and one other team, while games of Scopa with 3 players have 3 teams
+	Teams      []TeamHandView `json:"teams"`
+	PlayerName string         `json:"playerName"`
+	Variant    Variant        `json:"variant"`
+	// PlayersCardsCount is the number of cards each player has in his hands, which everybody sitting at the table sees
+	PlayersCardsCount map[string]int `json:"playersCardsCount"`
+	// CardsToDeal is the number of cards still to be dealt in the next rounds
+	CardsToDeal int `json:"cardsToDeal"`
+	// RemainingTime is the number of milliseconds the current player has left to play a card, set only in the
+	// games with a MoveTimeLimit
+	RemainingTime int64 `json:"remainingTime,omitempty"`
+}
+
+// TeamHandView is the data set that a Player can see of a team in a running hand
+type TeamHandView struct {
+	Players          []string    `json:"players"`
+	Scope            []deck.Card `json:"scope"`
+	Scorecard        ScoreCard   `json:"scorecard"`
+	CurrentGameScore int         `json:"currentGameScore"`
+	FinalHandScore   int         `json:"finalScore"`
 }
 
 // ScoreCard organizes the cards to facilitate calculating the score of a Team
@@ -202,6 +531,7 @@ type ScoreCard struct {
 	Carte         []deck.Card            `json:"carte"`
 	Scope         []deck.Card            `json:"scope"`
 	Napoli        []deck.Card            `json:"napoli"`
+	ReBello       bool                   `json:"reBello"`
 }
 
 // TeamScore is a data struct containg info related to the score of a team in one hand
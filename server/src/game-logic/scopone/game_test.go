@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
 )
 
 func TestAddPlayerToNewGameAndGameState(t *testing.T) {
@@ -64,9 +65,14 @@ func TestAddPlayerToNewGameAndGameState(t *testing.T) {
 	if len(t0.Players) != 2 {
 		t.Errorf("The second team has %v Players and not 2", len(t1.Players))
 	}
-	// test the game state is "Open" since all players have joined
+	// test the teams are still forming until all the players are ready, and then the game state is "Open"
+	if game.State != TeamsForming {
+		t.Errorf("A game whose players are not ready should have state teamsForming but has state %v", game.State)
+	}
+	game.Ready = map[string]bool{"Player_1": true, "Player_2": true, "Player_3": true, "Player_4": true}
+	game.CalculateState()
 	if game.State != GameOpen {
-		t.Errorf("A game with at least one player should have state open but has state %v", game.State)
+		t.Errorf("A game whose players are all ready should have state open but has state %v", game.State)
 	}
 
 	// test that if we try to add one more player we get an error
@@ -82,3 +88,59 @@ func TestAddPlayerToNewGameAndGameState(t *testing.T) {
 		t.Errorf("We should not be able to add twice the same player")
 	}
 }
+
+func TestCheckFinished(t *testing.T) {
+	game := NewGame()
+	for _, n := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+		game.AddPlayer(player.New(n))
+	}
+	game.TargetScore = 11
+	firstTeam := team.Name(game.Teams[0])
+	secondTeam := team.Name(game.Teams[1])
+
+	// no team has reached the target score
+	game.Score[firstTeam] = 10
+	game.Score[secondTeam] = 8
+	game.checkFinished()
+	if game.State == GameFinished {
+		t.Errorf("The game should not be finished with score %v", game.Score)
+	}
+
+	// both teams have reached the target score with the same points, so more hands have to be played
+	game.Score[firstTeam] = 12
+	game.Score[secondTeam] = 12
+	game.checkFinished()
+	if game.State == GameFinished {
+		t.Errorf("The game should not be finished with a tie %v", game.Score)
+	}
+
+	// both teams have passed the target score and the one with more points wins
+	game.Score[firstTeam] = 13
+	game.Score[secondTeam] = 15
+	game.checkFinished()
+	if game.State != GameFinished {
+		t.Errorf("The game should be finished with score %v but is %v", game.Score, game.State)
+	}
+	if len(game.Winners) != 2 || game.Winners[0] != "Player_3" || game.Winners[1] != "Player_4" {
+		t.Errorf("The winners should be the players of the second team but are %v", game.Winners)
+	}
+
+	// a finished game can not be suspended and its state is not recalculated
+	game.Suspend()
+	game.CalculateState()
+	if game.State != GameFinished {
+		t.Errorf("The game should stay finished but is %v", game.State)
+	}
+}
+
+func TestCheckFinishedWithNoTargetScore(t *testing.T) {
+	game := NewGame()
+	for _, n := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+		game.AddPlayer(player.New(n))
+	}
+	game.Score[team.Name(game.Teams[0])] = 100
+	game.checkFinished()
+	if game.State == GameFinished {
+		t.Errorf("A game with no target score should never be finished")
+	}
+}
@@ -0,0 +1,146 @@
+package scopone
+
+import (
+	"context"
+	"fmt"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+// A player can leave the seat of a game without closing the game for everybody else
+// Before the first hand the seat is simply free again, as if the player had been kicked out, while in the middle of
+// the game the cards and the score stay with the seat, which waits, with the game suspended, for a player who takes
+// it joining the game - the player who has left can join the game again and take back the seat, if nobody else has
+// taken it, while in the meantime the player can enter other games
+// The players of a team who do not want to go on with the game vote to abandon it: when all the players of the team
+// have voted, the bots and the seats left agreeing by default, the team loses the game by forfeit
+
+// LeaveSeat makes a player leave the seat of a game for good - the player stays in the Osteria as a player not
+// playing any game
+func (s *Scopone) LeaveSeat(ctx context.Context, gameName string, playerName string) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	p, seated := g.Players[playerName]
+	if !seated || g.SeatsLeft[playerName] {
+		return fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, playerName, gameName)
+	}
+	if g.State == GameClosed {
+		return fmt.Errorf("%w - The seats of game %v, which is closed, can not be left", ErrGameFinished, gameName)
+	}
+	if len(g.Hands) == 0 {
+		s.removeFromSeat(g, p)
+		g.unready()
+	} else {
+		// the seat keeps a player with the name and the cards of the player who has left, whose place in the
+		// Osteria is not in the game any more
+		left := player.New(playerName)
+		left.Status = player.PlayerLeftOsteria
+		g.replacePlayer(p, left)
+		if g.SeatsLeft == nil {
+			g.SeatsLeft = make(map[string]bool)
+		}
+		g.SeatsLeft[playerName] = true
+		delete(s.seatTokens, playerName)
+		p.Status = player.PlayerNotPlaying
+	}
+	if err := g.CalculateState(); err != nil {
+		return err
+	}
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// VoteToAbandon records the vote of a player to abandon a game in progress - when all the players of the team have
+// voted the team loses the game by forfeit, and true is returned
+func (s *Scopone) VoteToAbandon(ctx context.Context, gameName string, playerName string) (bool, error) {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return false, fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	if _, seated := g.Players[playerName]; !seated || g.SeatsLeft[playerName] {
+		return false, fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, playerName, gameName)
+	}
+	if g.State == GameFinished || g.State == GameClosed {
+		return false, fmt.Errorf("%w - Game %v which is %v can not be abandoned", ErrGameFinished, gameName, g.State)
+	}
+	if len(g.Hands) == 0 {
+		return false, fmt.Errorf("%w - Game %v has not started, its seats can be left without voting", ErrGameNotStarted,
+			gameName)
+	}
+	if g.AbandonVotes == nil {
+		g.AbandonVotes = make(map[string]bool)
+	}
+	g.AbandonVotes[playerName] = true
+	t, err := teamOfPlayer(playerName, g)
+	if err != nil {
+		return false, err
+	}
+	if g.teamAgreesToAbandon(t) {
+		g.forfeit(t)
+	}
+	return g.State == GameFinished, storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// teamAgreesToAbandon returns true if all the players of the team have voted to abandon the game - the bots and the
+// seats left agree by default
+func (game *Game) teamAgreesToAbandon(t *team.Team) bool {
+	for _, p := range t.Players {
+		if p.Bot == "" && !game.SeatsLeft[p.Name] && !game.AbandonVotes[p.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// forfeit finishes the game with the team which abandons it losing by forfeit - the other team wins, or with more
+// teams the one with the highest score, and its score is raised to the target score of the game if lower
+func (game *Game) forfeit(loser *team.Team) {
+	var winner *team.Team
+	for _, t := range game.Teams {
+		if t != loser && (winner == nil || game.Score[team.Name(t)] > game.Score[team.Name(winner)]) {
+			winner = t
+		}
+	}
+	if game.Score == nil {
+		game.Score = make(map[string]int)
+	}
+	if game.Score[team.Name(winner)] < game.TargetScore {
+		game.Score[team.Name(winner)] = game.TargetScore
+	}
+	game.Winners = make([]string, 0)
+	for _, p := range winner.Players {
+		game.Winners = append(game.Winners, p.Name)
+	}
+	game.AbandonedBy = team.Name(loser)
+	game.State = GameFinished
+}
+
+// handStopped returns true if the current hand of the game can not go on, since the game has been abandoned or the
+// current player has left the seat
+func (game *Game) handStopped() bool {
+	hand := currentHand(game)
+	return game.State == GameFinished || (hand != nil && game.SeatsLeft[hand.CurrentPlayer.Name])
+}
+
+// detachSeatsLeft gives to the players read from the store who have left a seat an instance of their own, since the
+// instance read is the one which keeps the seat - a player who sits in another game keeps the instance of that game
+func (s *Scopone) detachSeatsLeft() {
+	for _, g := range s.Games {
+		for pName, p := range g.Players {
+			if !g.SeatsLeft[pName] {
+				s.Players[pName] = p
+			}
+		}
+	}
+	for _, g := range s.Games {
+		for pName := range g.SeatsLeft {
+			if p, found := s.Players[pName]; !found || p == g.Players[pName] {
+				detached := player.New(pName)
+				detached.Status = player.PlayerLeftOsteria
+				s.Players[pName] = detached
+			}
+		}
+	}
+}
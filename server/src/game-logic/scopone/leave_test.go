@@ -0,0 +1,117 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+func TestLeaveSeatBeforeFirstHand(t *testing.T) {
+	s, g := formingGame(t, "Player_1", "Player_2")
+	s.AddPlayerToGame(ctx, "Player_1", "game")
+	s.AddPlayerToGame(ctx, "Player_2", "game")
+	if err := s.LeaveSeat(ctx, "game", "Player_1"); err != nil {
+		t.Fatalf("Player_1 could not leave the seat: %v", err)
+	}
+	if _, found := g.Players["Player_1"]; found || g.Seats()[0] != "" {
+		t.Errorf("The seat of Player_1 should be free but the seats are %v", g.Seats())
+	}
+	if s.Players["Player_1"].Status != player.PlayerNotPlaying || g.State != TeamsForming {
+		t.Errorf("Player_1 should not be playing and the teams forming but they are %v and %v",
+			s.Players["Player_1"].Status, g.State)
+	}
+}
+
+func TestLeaveSeatInTheMiddleOfTheGame(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(s, "game")
+	s.NewHand(ctx, g)
+	cards := append([]deck.Card{}, s.Players["Player_3"].Cards...)
+	if err := s.LeaveSeat(ctx, "game", "Player_3"); err != nil {
+		t.Fatalf("Player_3 could not leave the seat: %v", err)
+	}
+	if g.State != GameSuspended || !g.SeatsLeft["Player_3"] {
+		t.Errorf("The game should be suspended waiting for a player in the seat of Player_3 but is %v", g.State)
+	}
+	if _, playing := s.GameOfPlayer("Player_3"); playing || s.Players["Player_3"].Status != player.PlayerNotPlaying {
+		t.Errorf("Player_3 should not be playing any game after leaving the seat")
+	}
+	if len(g.Players["Player_3"].Cards) != len(cards) {
+		t.Errorf("The seat left should keep the cards of Player_3")
+	}
+	// nobody plays for the seat left
+	for currentPlayer(g).Name != "Player_3" {
+		moves := g.LegalMoves()
+		s.Play(ctx, g, currentPlayer(g).Name, moves[0].CardPlayed, moves[0].CardsTaken)
+	}
+	if _, _, err := s.Play(ctx, g, "Player_3", cards[0], nil); !errors.Is(err, ErrPlayerNotPlaying) {
+		t.Errorf("Player_3 who has left should not play but the error is %v", err)
+	}
+
+	// a new player takes the seat at once and goes on with its cards
+	s.PlayerEnters(ctx, "Player_5", "")
+	if err := s.AddPlayerToGame(ctx, "Player_5", "game"); err != nil {
+		t.Fatalf("Player_5 could not take the seat left: %v", err)
+	}
+	if g.State != GameOpen || g.SeatsLeft["Player_3"] || len(s.Players["Player_5"].Cards) != len(cards) {
+		t.Errorf("Player_5 should go on with the cards of the seat left in the game open but the game is %v", g.State)
+	}
+}
+
+func TestTakeBackSeatLeft(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(s, "game")
+	s.NewHand(ctx, g)
+	s.LeaveSeat(ctx, "game", "Player_2")
+	if err := s.AddPlayerToGame(ctx, "Player_2", "game"); err != nil {
+		t.Fatalf("Player_2 could not take back the seat left: %v", err)
+	}
+	if g.Players["Player_2"] != s.Players["Player_2"] || g.SeatsLeft["Player_2"] || g.State != GameOpen {
+		t.Errorf("Player_2 should be back in the seat of the game open, which is %v", g.State)
+	}
+}
+
+func TestVoteToAbandon(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newGameWithOptions(s, "game", GameOptions{TargetScore: 11})
+	if _, err := s.VoteToAbandon(ctx, "game", "Player_1"); !errors.Is(err, ErrGameNotStarted) {
+		t.Errorf("Voting to abandon a game not started should return ErrGameNotStarted but returns %v", err)
+	}
+	s.NewHand(ctx, g)
+	// Player_1 and Player_2 are the first team
+	abandoned, err := s.VoteToAbandon(ctx, "game", "Player_1")
+	if err != nil || abandoned || g.State == GameFinished {
+		t.Fatalf("The game should not be abandoned with only Player_1 voting but is %v with error %v", g.State, err)
+	}
+	abandoned, err = s.VoteToAbandon(ctx, "game", "Player_2")
+	if err != nil || !abandoned || g.State != GameFinished {
+		t.Fatalf("The game should be abandoned with the whole team voting but is %v with error %v", g.State, err)
+	}
+	if g.AbandonedBy != team.Name(g.Teams[0]) || len(g.Winners) != 2 || g.Winners[0] != "Player_3" {
+		t.Errorf("The second team should win by forfeit but the winners are %v and the game is abandoned by %v",
+			g.Winners, g.AbandonedBy)
+	}
+	if score := g.Score[team.Name(g.Teams[1])]; score != 11 {
+		t.Errorf("The winners should reach the target score but have %v", score)
+	}
+	p := currentPlayer(g)
+	if _, _, err := s.Play(ctx, g, p.Name, p.Cards[0], nil); !errors.Is(err, ErrGameFinished) {
+		t.Errorf("Playing in a game abandoned should return ErrGameFinished but returns %v", err)
+	}
+	if _, err := s.VoteToAbandon(ctx, "game", "Player_3"); !errors.Is(err, ErrGameFinished) {
+		t.Errorf("Voting to abandon a finished game should return ErrGameFinished but returns %v", err)
+	}
+}
+
+func TestBotsAndSeatsLeftAgreeToAbandon(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(s, "game")
+	s.NewHand(ctx, g)
+	s.LeaveSeat(ctx, "game", "Player_2")
+	if abandoned, _ := s.VoteToAbandon(ctx, "game", "Player_1"); !abandoned {
+		t.Errorf("The game should be abandoned by Player_1 whose partner has left the seat")
+	}
+}
@@ -0,0 +1,40 @@
+package scopone
+
+import (
+	"fmt"
+)
+
+// The commands of different games run in parallel while the commands of the same game run one at a time, and
+// whoever runs a command has to hold the right lock while the command runs and while its results are read:
+// - the commands which change the Osteria as a whole, i.e. its players, its games or who plays and observes each
+//   game (PlayerEnters, RemovePlayer, NewGame, AddPlayerToGame, AddObserverToGame, AddBotToGame and Close), as well
+//   as the reading of all the players and of all the games, hold the lock returned by LockOsteria
+// - the commands of a single game (NewHand, Play, PlayBot and IsBotTurn) hold the lock returned by LockGame, which
+//   does not stop the commands of the other games, so that a slow write of a game in the store delays only the
+//   players of that game
+// The lock of the Osteria waits for the commands of the games which are running to complete, and therefore the
+// stores must be safe for concurrent use only by the commands of different games
+
+// LockOsteria locks the whole Osteria and returns the function to unlock it
+func (s *Scopone) LockOsteria() (unlock func()) {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// LockGame locks the game with the name passed in and returns it together with the function to unlock it - while
+// the game is locked the Osteria can not be changed but the other games can be played
+// If there is no game with such name an error wrapping ErrGameNotFound is returned and nothing is locked
+func (s *Scopone) LockGame(gameName string) (g *Game, unlock func(), err error) {
+	s.mu.RLock()
+	g, found := s.Games[gameName]
+	if !found {
+		s.mu.RUnlock()
+		return nil, nil, fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	g.mu.Lock()
+	unlock = func() {
+		g.mu.Unlock()
+		s.mu.RUnlock()
+	}
+	return g, unlock, nil
+}
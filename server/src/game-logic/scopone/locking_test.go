@@ -0,0 +1,177 @@
+package scopone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// the tests of this file are meant to be run with the race detector, i.e. "go test -race", which finds the state
+// shared by the games and accessed without the right lock
+
+// blockingStore is a store whose writes of one game wait until the store is released
+type blockingStore struct {
+	DoNothingStore
+	blockedGame string
+	writing     chan struct{}
+	release     chan struct{}
+}
+
+func (store *blockingStore) WriteGame(ctx context.Context, game *Game) error {
+	if game.Name == store.blockedGame {
+		store.writing <- struct{}{}
+		<-store.release
+	}
+	return nil
+}
+
+// playHandsInParallel plays a number of hands of the game, locking the game for each command as the servers do
+func playHandsInParallel(s *Scopone, gName string, hands int) error {
+	for i := 0; i < hands; i++ {
+		g, unlock, err := s.LockGame(gName)
+		if err != nil {
+			return err
+		}
+		_, _, err = s.NewHand(ctx, g)
+		unlock()
+		if err != nil {
+			return err
+		}
+		for {
+			g, unlock, err := s.LockGame(gName)
+			if err != nil {
+				return err
+			}
+			if !IsCurrentHandActive(g) {
+				unlock()
+				break
+			}
+			move := g.LegalMoves()[0]
+			_, _, err = s.Play(ctx, g, currentPlayer(g).Name, move.CardPlayed, move.CardsTaken)
+			unlock()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestManyGamesInParallel(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	numberOfGames := 8
+	for i := 0; i < numberOfGames; i++ {
+		gName := fmt.Sprintf("TestManyGamesInParallel_%v", i)
+		newGame(gName+"_1", gName+"_2", gName+"_3", gName+"_4", s, gName)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numberOfGames+2)
+	for i := 0; i < numberOfGames; i++ {
+		wg.Add(1)
+		go func(gName string) {
+			defer wg.Done()
+			errs <- playHandsInParallel(s, gName, 3)
+		}(fmt.Sprintf("TestManyGamesInParallel_%v", i))
+	}
+	// while the games are played the Osteria gets new players and new games and sends the lists of all of them
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			unlock := s.LockOsteria()
+			pName := fmt.Sprintf("Newcomer_%v", i)
+			_, err := s.PlayerEnters(ctx, pName, "")
+			if err == nil {
+				_, err = s.NewGame(ctx, pName+"_game", GameOptions{})
+			}
+			if err == nil {
+				err = s.AddPlayerToGame(ctx, pName, pName+"_game")
+			}
+			unlock()
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+		errs <- nil
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			unlock := s.LockOsteria()
+			_, err := json.Marshal(s.AllGames())
+			if err == nil {
+				_, err = json.Marshal(s.AllPlayers())
+			}
+			unlock()
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+		errs <- nil
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("The games played in parallel return the error %v", err)
+		}
+	}
+	for i := 0; i < numberOfGames; i++ {
+		g := s.Games[fmt.Sprintf("TestManyGamesInParallel_%v", i)]
+		if len(g.Hands) != 3 || currentHand(g).State != HandClosed {
+			t.Errorf("Game %v should have 3 hands all closed but has %v hands", g.Name, len(g.Hands))
+		}
+	}
+}
+
+func TestSlowGameDoesNotStopOtherGames(t *testing.T) {
+	store := &blockingStore{
+		blockedGame: "TestSlowGame",
+		writing:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	newGame("Slow_1", "Slow_2", "Slow_3", "Slow_4", s, "TestSlowGame")
+	newGame("Fast_1", "Fast_2", "Fast_3", "Fast_4", s, "TestFastGame")
+	s.GameStore = store
+
+	go func() {
+		g, unlock, _ := s.LockGame("TestSlowGame")
+		defer unlock()
+		s.NewHand(ctx, g)
+	}()
+	// the slow game is now writing in the store holding its lock
+	<-store.writing
+
+	done := make(chan error)
+	go func() {
+		done <- playHandsInParallel(s, "TestFastGame", 1)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("The fast game returns the error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The fast game should be played while the slow game is writing in the store")
+	}
+	close(store.release)
+}
+
+func TestLockGameNotFound(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	if _, _, err := s.LockGame("TestLockGameNotFound"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("Locking a game not present should return ErrGameNotFound but returns %v", err)
+	}
+	// nothing is left locked
+	unlock := s.LockOsteria()
+	unlock()
+}
@@ -0,0 +1,75 @@
+package scopone
+
+import "fmt"
+
+// ObserverMode is what the observers of a game see of its hands - the server sends to the observers only the views
+// allowed by the mode of the game, so that an observer can not tell the players the cards of the other players
+type ObserverMode string
+
+// the modes of the observers
+const (
+	// ObserveDelayed shows the cards of all the players, but with the hand as it was a number of moves before, see
+	// GameOptions.ObserverDelay
+	ObserveDelayed ObserverMode = "delayed"
+	// ObservePublic shows only what everybody sitting at the table sees, i.e. the table, the scope and the scores
+	ObservePublic ObserverMode = "public"
+	// ObserveAfterHand shows what everybody sitting at the table sees while the hand is played and the cards of all
+	// the players, with the whole history of the hand, when the hand is closed
+	ObserveAfterHand ObserverMode = "afterHand"
+)
+
+// DefaultObserverMode is the mode of the games created without a mode for their observers
+const DefaultObserverMode = ObservePublic
+
+// DefaultObserverDelay is the number of moves the observers of the games with ObserveDelayed are behind the
+// players, if the game has no delay set - it is one round of a game of Scopone
+const DefaultObserverDelay = 4
+
+// validateObserverMode checks that the mode of the observers is known and that its delay is not negative
+func validateObserverMode(mode ObserverMode, delay int) error {
+	switch mode {
+	case "", ObserveDelayed, ObservePublic, ObserveAfterHand:
+	default:
+		return fmt.Errorf("%w - The observer mode %v is not known", ErrInvalidGameOptions, mode)
+	}
+	if delay < 0 {
+		return fmt.Errorf("%w - The observer delay can not be negative but is %v", ErrInvalidGameOptions, delay)
+	}
+	return nil
+}
+
+// Observation returns the mode of the observers of the game and, with ObserveDelayed, the number of moves they are
+// behind the players - the games stored before the observers had a mode have the default mode
+func (game *Game) Observation() (ObserverMode, int) {
+	mode, delay := game.ObserverMode, game.ObserverDelay
+	if mode == "" {
+		mode = DefaultObserverMode
+	}
+	if delay == 0 {
+		delay = DefaultObserverDelay
+	}
+	return mode, delay
+}
+
+// ObserversSeeMovesLive returns true if the observers of the game can see the cards played as soon as they are
+// played, i.e. if they are not kept behind the players
+func (game *Game) ObserversSeeMovesLive() bool {
+	mode, _ := game.Observation()
+	return mode != ObserveDelayed
+}
+
+// ObserversSeeClosedHands returns true if the observers of the game can see the cards of all the players once a
+// hand is closed, e.g. replaying it
+func (game *Game) ObserversSeeClosedHands() bool {
+	mode, _ := game.Observation()
+	return mode != ObservePublic
+}
+
+// PublicView returns the view of a player without what only the player sees, i.e. the cards in the hands of the
+// player, the legal moves and the cards the players had in their hands during the hand
+func PublicView(view HandPlayerView) HandPlayerView {
+	view.PlayerCards = nil
+	view.LegalMoves = nil
+	view.History = publicHistory(view.History)
+	return view
+}
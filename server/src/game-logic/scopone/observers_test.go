@@ -0,0 +1,24 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestObserverModeOptions(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	if _, err := s.NewGame(ctx, "unknown", GameOptions{ObserverMode: "spoilers"}); !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("An unknown observer mode should return ErrInvalidGameOptions but returns %v", err)
+	}
+	if _, err := s.NewGame(ctx, "negative", GameOptions{ObserverMode: ObserveDelayed, ObserverDelay: -1}); !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A negative observer delay should return ErrInvalidGameOptions but returns %v", err)
+	}
+	g, _ := s.NewGame(ctx, "default", GameOptions{})
+	if mode, _ := g.Observation(); mode != DefaultObserverMode || g.ObserversSeeClosedHands() {
+		t.Errorf("A game without observer mode should have the default mode but has %v", mode)
+	}
+	g, _ = s.NewGame(ctx, "delayed", GameOptions{ObserverMode: ObserveDelayed})
+	if mode, delay := g.Observation(); mode != ObserveDelayed || delay != DefaultObserverDelay || g.ObserversSeeMovesLive() {
+		t.Errorf("A delayed game without delay should have the default delay but has %v", delay)
+	}
+}
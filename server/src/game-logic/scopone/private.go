@@ -0,0 +1,98 @@
+package scopone
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxPasswordLength is the maximum number of bytes of the password of a game, since bcrypt ignores the following ones
+const maxPasswordLength = 72
+
+// validatePassword checks that the password of a private game can be hashed
+func validatePassword(password string) error {
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("%w - The password of a game can not be longer than %v characters", ErrInvalidGameOptions,
+			maxPasswordLength)
+	}
+	return nil
+}
+
+// validateInvitations checks that the players invited to a private game have a name
+func validateInvitations(invited []string) error {
+	for _, pName := range invited {
+		if pName == "" {
+			return fmt.Errorf("%w - The name of a player invited is empty", ErrInvalidGameOptions)
+		}
+	}
+	return nil
+}
+
+// setPrivacy makes the game private if the options have a password or players invited - the password is hashed
+// with bcrypt, as the passwords of the accounts, so that it is never kept, nor saved, as it is
+func (game *Game) setPrivacy(options GameOptions) error {
+	game.PasswordHash = options.PasswordHash
+	if options.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(options.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("%w - The password of game %v can not be hashed: %v", ErrInvalidGameOptions, game.Name, err)
+		}
+		game.PasswordHash = hash
+	}
+	game.Invited = append([]string{}, options.Invited...)
+	game.Private = len(game.PasswordHash) > 0 || len(options.Invited) > 0
+	return nil
+}
+
+// IsInvited returns true if the player is invited to the game - anybody is invited to a game which is not private
+func (game *Game) IsInvited(playerName string) bool {
+	if !game.Private {
+		return true
+	}
+	for _, pName := range game.Invited {
+		if pName == playerName {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo returns true if the player can see the game among the games of the Osteria, i.e. if the game is not
+// private, or the player is invited to it, or the player is already playing or observing it
+func (game *Game) VisibleTo(playerName string) bool {
+	if game.IsInvited(playerName) || game.SeatsLeft[playerName] {
+		return true
+	}
+	_, playing := game.Players[playerName]
+	_, observing := game.Observers[playerName]
+	return playing || observing
+}
+
+// Admits checks that the player can join or observe the game with the password passed in - a private game admits
+// the players invited, the players already in it and anybody who knows its password
+// An error wrapping ErrNotInvited is returned if the game does not admit the player
+func (game *Game) Admits(playerName string, password string) error {
+	if game.VisibleTo(playerName) {
+		return nil
+	}
+	if len(game.PasswordHash) > 0 && password != "" &&
+		bcrypt.CompareHashAndPassword(game.PasswordHash, []byte(password)) == nil {
+		return nil
+	}
+	if password != "" {
+		return fmt.Errorf("%w - The password of the private game %v is wrong", ErrNotInvited, game.Name)
+	}
+	return fmt.Errorf("%w - Player %v is not invited to the private game %v", ErrNotInvited, playerName, game.Name)
+}
+
+// GamesVisibleTo returns the games of the Osteria the player can see, i.e. all the games but the private games the
+// player is not invited to, see Game.VisibleTo
+func (s *Scopone) GamesVisibleTo(playerName string) (games []*Game) {
+	games = make([]*Game, 0)
+	for _, g := range s.Games {
+		if g.VisibleTo(playerName) {
+			games = append(games, g)
+		}
+	}
+	return
+}
@@ -0,0 +1,77 @@
+package scopone
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrivateGameOptions(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	if _, err := s.NewGame(ctx, "game", GameOptions{Invited: []string{"Player_1", ""}}); !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A game with an empty player invited should return ErrInvalidGameOptions but returns %v", err)
+	}
+	public, _ := s.NewGame(ctx, "public game", GameOptions{})
+	invitation, _ := s.NewGame(ctx, "invitation game", GameOptions{Invited: []string{"Player_1"}})
+	password, _ := s.NewGame(ctx, "password game", GameOptions{Password: "secret"})
+	if public.Private || !invitation.Private || !password.Private {
+		t.Errorf("Only the games with players invited or a password should be private")
+	}
+	if len(password.PasswordHash) == 0 || strings.Contains(string(password.PasswordHash), "secret") {
+		t.Errorf("The game should keep only the hash of the password but keeps %q", password.PasswordHash)
+	}
+	tooLong := strings.Repeat("x", 73)
+	if _, err := s.NewGame(ctx, "long password game", GameOptions{Password: tooLong}); !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A game with a password longer than 72 characters should return ErrInvalidGameOptions but returns %v", err)
+	}
+}
+
+func TestGamesVisibleTo(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	s.NewGame(ctx, "public game", GameOptions{})
+	s.NewGame(ctx, "private game", GameOptions{Password: "secret", Invited: []string{"Player_1"}})
+	if games := s.GamesVisibleTo("Player_1"); len(games) != 2 {
+		t.Errorf("Player_1 who is invited should see 2 games but sees %v", len(games))
+	}
+	if games := s.GamesVisibleTo("Player_2"); len(games) != 1 || games[0].Name != "public game" {
+		t.Errorf("Player_2 who is not invited should see only the public game but sees %v games", len(games))
+	}
+	// a player who has joined with the password sees the game
+	s.PlayerEnters(ctx, "Player_2", "")
+	s.AddPlayerToGame(ctx, "Player_2", "private game")
+	if games := s.GamesVisibleTo("Player_2"); len(games) != 2 {
+		t.Errorf("Player_2 who plays the private game should see 2 games but sees %v", len(games))
+	}
+}
+
+func TestAdmits(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g, _ := s.NewGame(ctx, "game", GameOptions{Password: "secret", Invited: []string{"Player_1"}})
+	tests := []struct {
+		playerName string
+		password   string
+		admitted   bool
+	}{
+		{"Player_1", "", true},
+		{"Player_2", "secret", true},
+		{"Player_2", "wrong", false},
+		{"Player_2", "", false},
+	}
+	for _, tt := range tests {
+		err := g.Admits(tt.playerName, tt.password)
+		if tt.admitted && err != nil || !tt.admitted && !errors.Is(err, ErrNotInvited) {
+			t.Errorf("%v with password %q should be admitted %v but the error is %v", tt.playerName, tt.password,
+				tt.admitted, err)
+		}
+	}
+	// a game read from a store has the hash of the password
+	read, _ := s.NewGame(ctx, "read game", GameOptions{PasswordHash: g.PasswordHash})
+	if err := read.Admits("Player_2", "secret"); err != nil || !read.Private {
+		t.Errorf("The game created with the hash of the password should admit the password but the error is %v", err)
+	}
+	// a game with players invited and no password admits only them
+	invitation, _ := s.NewGame(ctx, "invitation game", GameOptions{Invited: []string{"Player_1"}})
+	if err := invitation.Admits("Player_2", ""); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("Player_2 should not be admitted without an invitation but the error is %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package scopone
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go-scopone/src/game-logic/player"
+)
+
+// DefaultReconnectGracePeriod is the time for which the seat of a player who has left the Osteria while playing a
+// game is kept for the player
+const DefaultReconnectGracePeriod = 2 * time.Minute
+
+// The seat a player takes in a game is given a reconnect token, which the player has to present to resume the
+// game after having left the Osteria, e.g. because the connection has dropped
+// When the player leaves, the seat is kept for the grace period of the Osteria - after that the seat can be taken
+// by another player or by a bot joining the game, who goes on with the cards and the score of the seat, while the
+// player can still resume it with the token until somebody takes it
+// The tokens are kept only in memory: the seats of the games read from the store when the Osteria starts have no
+// token, and their players resume them by name and get a new token, while the grace period of such seats starts
+// when the Osteria starts
+
+// SeatToken is the reconnect token of the seat of a player in a game
+type SeatToken struct {
+	Token string `json:"token"`
+	// Left is when the player has left the Osteria - it is zero while the player is in the Osteria
+	Left time.Time `json:"left"`
+}
+
+// newReconnectToken returns a new random reconnect token
+func newReconnectToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("Random bytes for the reconnect token can not be read: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// clock returns the current time
+func (s *Scopone) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// issueReconnectToken gives a new reconnect token to the seat of the player
+func (s *Scopone) issueReconnectToken(pName string) {
+	if s.seatTokens == nil {
+		s.seatTokens = make(map[string]*SeatToken)
+	}
+	s.seatTokens[pName] = &SeatToken{Token: newReconnectToken()}
+}
+
+// ReconnectToken returns the reconnect token of the seat of the player, which has to be sent only to the player -
+// the second value returned is false if the player has no seat with a token
+func (s *Scopone) ReconnectToken(pName string) (string, bool) {
+	st, found := s.seatTokens[pName]
+	if !found || st.Token == "" {
+		return "", false
+	}
+	return st.Token, true
+}
+
+// SeatTokens returns the reconnect tokens of the seats, with the name of the player as key, so that a server which
+// does not keep the Osteria in memory can save them and restore them with RestoreSeatTokens
+func (s *Scopone) SeatTokens() map[string]SeatToken {
+	tokens := make(map[string]SeatToken)
+	for pName, st := range s.seatTokens {
+		tokens[pName] = *st
+	}
+	return tokens
+}
+
+// RestoreSeatTokens gives back to the seats the reconnect tokens returned by SeatTokens - the seats of the players
+// who are in the Osteria have not been left, whatever the tokens say
+func (s *Scopone) RestoreSeatTokens(tokens map[string]SeatToken) {
+	if s.seatTokens == nil {
+		s.seatTokens = make(map[string]*SeatToken)
+	}
+	for pName, st := range tokens {
+		st := st
+		s.seatTokens[pName] = &st
+	}
+	for pName, st := range s.seatTokens {
+		if p, found := s.Players[pName]; found && p.Status != player.PlayerLeftOsteria {
+			st.Left = time.Time{}
+		}
+	}
+}
+
+// keepSeatsOfGamesRead keeps the seats of the players of the games read from the store for the grace period,
+// starting from now, without any token since the tokens issued before the Osteria was started are lost
+func (s *Scopone) keepSeatsOfGamesRead() {
+	for _, g := range s.Games {
+		for pName, p := range g.Players {
+			if p.Bot != "" || g.SeatsLeft[pName] {
+				continue
+			}
+			if s.seatTokens == nil {
+				s.seatTokens = make(map[string]*SeatToken)
+			}
+			s.seatTokens[pName] = &SeatToken{Left: s.clock()}
+		}
+	}
+}
+
+// seatLeft starts the grace period of the seat of a player who has left the Osteria
+func (s *Scopone) seatLeft(pName string) {
+	if st, found := s.seatTokens[pName]; found {
+		st.Left = s.clock()
+	}
+}
+
+// resumeSeat checks the reconnect token of a player who comes back to the seat of a game and gives the seat a new
+// token, so that a token can be used only once
+func (s *Scopone) resumeSeat(pName string, gName string, reconnectToken string) error {
+	st, found := s.seatTokens[pName]
+	if found && st.Token != "" && st.Token != reconnectToken {
+		if reconnectToken == "" {
+			return fmt.Errorf("%w - Player %v has to present the reconnect token to resume game %v", ErrInvalidReconnectToken,
+				pName, gName)
+		}
+		return fmt.Errorf("%w - The token presented by %v is not the one of the seat in game %v", ErrInvalidReconnectToken,
+			pName, gName)
+	}
+	s.issueReconnectToken(pName)
+	return nil
+}
+
+// expiredSeat returns the player of the game whose seat has been left for longer than the grace period, or for
+// good, if any
+func (s *Scopone) expiredSeat(g *Game) (*player.Player, bool) {
+	for _, p := range g.seatingOrder() {
+		if p == nil || p.Status != player.PlayerLeftOsteria {
+			continue
+		}
+		st, found := s.seatTokens[p.Name]
+		if !found {
+			// a seat which has not been kept for its player can be taken at once
+			return p, true
+		}
+		if !st.Left.IsZero() && s.clock().Sub(st.Left) >= s.ReconnectGracePeriod {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// TakeSeat makes a player take the seat of another player of the game, going on with the cards and the score of
+// the seat - the player replaced is no longer in the game and can enter the Osteria again as a player not
+// playing any game
+// It is used to rebuild the games read from a store, while the players take the seats whose grace period has
+// expired joining the game, see AddPlayerToGame and AddBotToGame
+func (s *Scopone) TakeSeat(ctx context.Context, playerName string, gameName string, replacedName string) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	p, pfound := s.Players[playerName]
+	if !pfound {
+		return fmt.Errorf("%w - There is no Player with name %v", ErrPlayerNotFound, playerName)
+	}
+	replaced, rfound := g.Players[replacedName]
+	if !rfound {
+		return fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, replacedName, gameName)
+	}
+	err := s.takeSeat(g, replaced, p)
+	if err != nil {
+		return err
+	}
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// takeSeat makes a player take the seat of the player replaced
+func (s *Scopone) takeSeat(g *Game, replaced *player.Player, p *player.Player) error {
+	if _, found := g.Players[p.Name]; found && !(p.Name == replaced.Name && g.SeatsLeft[p.Name]) {
+		return fmt.Errorf("%w - Player %v is already present in game %v", ErrPlayerAlreadyInGame, p.Name, g.Name)
+	}
+	g.replacePlayer(replaced, p)
+	delete(g.SeatsLeft, replaced.Name)
+	delete(s.seatTokens, replaced.Name)
+	p.Status = player.PlayerPlaying
+	setStatusWhenHandClosed(g, p)
+	return g.CalculateState()
+}
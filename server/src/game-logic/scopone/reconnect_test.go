@@ -0,0 +1,112 @@
+package scopone
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+// leftSeat returns an Osteria with a game whose hand has started and which Player_2 has left, together with the
+// time, which the tests can move on to let the grace period of the seat expire
+func leftSeat(t *testing.T) (*Scopone, *Game, *time.Time) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	s.now = func() time.Time { return now }
+	g := newTestGameFactory(s, "game")
+	if _, _, err := s.NewHand(ctx, g); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	s.RemovePlayer("Player_2")
+	return s, g, &now
+}
+
+func TestResumeSeatWithReconnectToken(t *testing.T) {
+	s, g, _ := leftSeat(t)
+	token, found := s.ReconnectToken("Player_2")
+	if !found {
+		t.Fatalf("The seat of Player_2 should have a reconnect token")
+	}
+	if _, err := s.PlayerEnters(ctx, "Player_2", ""); !errors.Is(err, ErrInvalidReconnectToken) {
+		t.Errorf("Resuming the seat without the token should return ErrInvalidReconnectToken but returns %v", err)
+	}
+	if _, err := s.PlayerEnters(ctx, "Player_2", "not the token"); !errors.Is(err, ErrInvalidReconnectToken) {
+		t.Errorf("Resuming the seat with a wrong token should return ErrInvalidReconnectToken but returns %v", err)
+	}
+	if g.Players["Player_2"].Status != player.PlayerLeftOsteria {
+		t.Errorf("Player_2 should still have left the Osteria but is %v", g.Players["Player_2"].Status)
+	}
+	if _, err := s.PlayerEnters(ctx, "Player_2", token); err != nil {
+		t.Fatalf("Resuming the seat with the token returns an error %v", err)
+	}
+	if g.Players["Player_2"].Status != player.PlayerPlaying {
+		t.Errorf("Player_2 should be playing but is %v", g.Players["Player_2"].Status)
+	}
+	// the token can be used only once
+	if newToken, _ := s.ReconnectToken("Player_2"); newToken == token || newToken == "" {
+		t.Errorf("The seat resumed should have a new token but has %q", newToken)
+	}
+}
+
+func TestSeatTakenAfterGracePeriod(t *testing.T) {
+	s, g, now := leftSeat(t)
+	token, _ := s.ReconnectToken("Player_2")
+	cards := g.Players["Player_2"].Cards
+	tm2, _ := teamOfPlayer("Player_2", g)
+	g.Score[team.Name(tm2)] = 3
+	s.PlayerEnters(ctx, "Player_5", "")
+
+	*now = now.Add(s.ReconnectGracePeriod - time.Second)
+	if err := s.AddPlayerToGame(ctx, "Player_5", "game"); !errors.Is(err, ErrGameFull) {
+		t.Fatalf("The seat should be kept during the grace period but joining returns %v", err)
+	}
+
+	*now = now.Add(time.Second)
+	if err := s.AddPlayerToGame(ctx, "Player_5", "game"); err != nil {
+		t.Fatalf("Player_5 should take the seat after the grace period but joining returns %v", err)
+	}
+	p5 := g.Players["Player_5"]
+	if _, found := g.Players["Player_2"]; found || p5 == nil {
+		t.Fatalf("Player_5 should have replaced Player_2 but the players of the game are %v", g.Players)
+	}
+	if !reflect.DeepEqual(p5.Cards, cards) || p5.Status != player.PlayerPlaying || g.State != GameOpen {
+		t.Errorf("Player_5 should be playing with the cards of the seat in the open game but has %v, status %v and "+
+			"the game is %v", p5.Cards, p5.Status, g.State)
+	}
+	tm, err := teamOfPlayer("Player_5", g)
+	if err != nil {
+		t.Fatalf("Player_5 should be in a team: %v", err)
+	}
+	if g.Score[team.Name(tm)] != 3 {
+		t.Errorf("The score of the seat should pass to the team of Player_5 but the scores are %v", g.Score)
+	}
+	if _, found := s.ReconnectToken("Player_5"); !found {
+		t.Errorf("The seat taken should have a reconnect token for Player_5")
+	}
+
+	// the player replaced comes back as a player not playing any game, even with the old token
+	if _, err := s.PlayerEnters(ctx, "Player_2", token); err != nil {
+		t.Fatalf("Player_2 should enter the Osteria again but gets %v", err)
+	}
+	if s.Players["Player_2"].Status != player.PlayerNotPlaying {
+		t.Errorf("Player_2 should not be playing any game but is %v", s.Players["Player_2"].Status)
+	}
+}
+
+func TestBotTakesExpiredSeat(t *testing.T) {
+	s, g, now := leftSeat(t)
+	*now = now.Add(s.ReconnectGracePeriod)
+	botName, err := s.AddBotToGame(ctx, "game", &firstMoveBot{})
+	if err != nil {
+		t.Fatalf("The bot should take the seat of Player_2 but gets %v", err)
+	}
+	if _, found := g.Players["Player_2"]; found || g.Players[botName] == nil {
+		t.Fatalf("%v should have replaced Player_2 but the players of the game are %v", botName, g.Players)
+	}
+	if _, found := s.ReconnectToken(botName); found {
+		t.Errorf("A bot should not get a reconnect token")
+	}
+}
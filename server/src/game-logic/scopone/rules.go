@@ -0,0 +1,228 @@
+package scopone
+
+import (
+	"fmt"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+// Variant is the name of a variant of the rules of the game
+type Variant string
+
+// Variants of the rules available in the Osteria
+const (
+	// ScoponeScientifico deals all the 40 cards to the 4 players, 10 each, with no card on the table
+	// and scores Napoli from 3 Denari in sequence
+	ScoponeScientifico Variant = "scientifico"
+	// ScoponeClassico deals 9 cards to each player and 4 on the table and does not score Napoli
+	ScoponeClassico Variant = "classico"
+	// ScoponeTrentino is like Scopone Scientifico but scores also Re Bello (the King of Denari) and the Ace takes
+	// all the cards on the table ("Asso piglia tutto")
+	ScoponeTrentino Variant = "trentino"
+	// Scopa deals 3 cards at a time to each player, with 4 cards on the table at the beginning of the hand
+	Scopa Variant = "scopa"
+)
+
+// DefaultVariant is the variant used when no variant is specified
+const DefaultVariant = ScoponeScientifico
+
+// Rules are the rules of a variant of the game
+// They control how the cards are dealt, which cards can be taken from the table, how the score is calculated
+// and who takes the cards left on the table at the end of an hand
+type Rules interface {
+	// Variant returns the variant these rules implement
+	Variant() Variant
+	// AllowsNumberOfPlayers returns true if the variant can be played by the number of players passed in
+	AllowsNumberOfPlayers(numberOfPlayers int) bool
+	// Deal deals the cards of the deck of a new hand to the players, passed in the order they play, and to the table
+	Deal(hand *Hand, players []*player.Player)
+	// DealNextRound deals more cards to the players, passed in the order they play, when all of them have played
+	// all their cards - it returns false if there are no more cards to deal and therefore the hand is over
+	DealNextRound(hand *Hand, players []*player.Player) bool
+	// Captures returns all the combinations of cards that can be taken from the table playing a card - if no
+	// combination is returned the card played has to be placed on the table
+	Captures(cardPlayed deck.Card, table []deck.Card) [][]deck.Card
+	// IsScopa returns true if taking the cards with the card played, leaving the table empty, counts as Scopa
+	IsScopa(cardPlayed deck.Card, cardsTaken []deck.Card) bool
+	// Score calculates the score of the teams at the end of a hand given the cards they have taken
+	Score(teams []*team.Team) []TeamScore
+}
+
+// RulesFor returns the rules of a variant - if the variant is empty the rules of the DefaultVariant are returned
+// while if the variant is not known an error wrapping ErrInvalidGameOptions is returned
+func RulesFor(variant Variant) (Rules, error) {
+	if variant == "" {
+		variant = DefaultVariant
+	}
+	rules, found := variants[variant]
+	if !found {
+		return nil, fmt.Errorf("%w - Variant \"%v\" is not known", ErrInvalidGameOptions, variant)
+	}
+	return rules, nil
+}
+
+// defaultRules returns the rules of the DefaultVariant
+func defaultRules() Rules {
+	return variants[DefaultVariant]
+}
+
+var variants = map[Variant]*standardRules{
+	ScoponeScientifico: {variant: ScoponeScientifico, numberOfPlayers: []int{4}, napoliMinimum: 3},
+	ScoponeClassico:    {variant: ScoponeClassico, numberOfPlayers: []int{4}, tableCards: 4},
+	ScoponeTrentino: {variant: ScoponeTrentino, numberOfPlayers: []int{4}, napoliMinimum: 3, reBello: true,
+		assoPigliaTutto: true},
+	Scopa: {variant: Scopa, numberOfPlayers: []int{2, 3, 4}, tableCards: 4, cardsPerRound: 3},
+}
+
+// standardRules implements the Rules shared by all the variants of the game - each variant configures them
+type standardRules struct {
+	variant Variant
+	// numberOfPlayers are the numbers of players which can play the variant
+	numberOfPlayers []int
+	// tableCards are the cards placed on the table at the beginning of the hand
+	tableCards int
+	// cardsPerRound are the cards dealt to each player at each round - if 0 all the cards are dealt at the beginning
+	cardsPerRound int
+	// napoliMinimum is the minimum number of Denari in sequence which score as Napoli - if 0 Napoli does not score
+	napoliMinimum int
+	// reBello is true if the King of Denari scores one point
+	reBello bool
+	// assoPigliaTutto is true if the Ace takes all the cards on the table, unless there is an Ace on the table
+	assoPigliaTutto bool
+}
+
+// Variant returns the variant of the rules
+func (r *standardRules) Variant() Variant {
+	return r.variant
+}
+
+// AllowsNumberOfPlayers returns true if the number of players is one of those of the variant
+func (r *standardRules) AllowsNumberOfPlayers(numberOfPlayers int) bool {
+	for _, n := range r.numberOfPlayers {
+		if n == numberOfPlayers {
+			return true
+		}
+	}
+	return false
+}
+
+// Deal places the table cards on the table and then deals the first round
+func (r *standardRules) Deal(hand *Hand, players []*player.Player) {
+	hand.Table = make([]deck.Card, r.tableCards)
+	copy(hand.Table, hand.Deck[:r.tableCards])
+	hand.DealtCards = r.tableCards
+	cardsPerPlayer := r.cardsPerRound
+	if cardsPerPlayer == 0 {
+		cardsPerPlayer = (len(hand.Deck) - r.tableCards) / len(players)
+	}
+	r.dealRound(hand, players, cardsPerPlayer)
+}
+
+// DealNextRound deals the next round of cards, if there are still cards to deal
+func (r *standardRules) DealNextRound(hand *Hand, players []*player.Player) bool {
+	if r.cardsPerRound == 0 || hand.DealtCards >= len(hand.Deck) {
+		return false
+	}
+	r.dealRound(hand, players, r.cardsPerRound)
+	return true
+}
+
+// dealRound gives to each player a block of cards taken from the cards of the deck still to be dealt
+func (r *standardRules) dealRound(hand *Hand, players []*player.Player, cardsPerPlayer int) {
+	for _, p := range players {
+		start := hand.DealtCards
+		// copy so that the cards of the players do not share the same array of the deck
+		p.Cards = make([]deck.Card, cardsPerPlayer)
+		copy(p.Cards, hand.Deck[start:start+cardsPerPlayer])
+		hand.DealtCards = hand.DealtCards + cardsPerPlayer
+	}
+}
+
+// Captures returns the standard captures unless the Ace takes all the cards on the table
+func (r *standardRules) Captures(cardPlayed deck.Card, table []deck.Card) [][]deck.Card {
+	if r.takesAllTable(cardPlayed, table) {
+		allTable := make([]deck.Card, len(table))
+		copy(allTable, table)
+		return [][]deck.Card{allTable}
+	}
+	return legalCaptures(cardPlayed, table)
+}
+
+// takesAllTable returns true if the card played is an Ace that takes all the cards on the table
+func (r *standardRules) takesAllTable(cardPlayed deck.Card, table []deck.Card) bool {
+	if !r.assoPigliaTutto || cardPlayed.Type != "Ace" || len(table) == 0 {
+		return false
+	}
+	for _, c := range table {
+		if c.Type == "Ace" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsScopa returns true unless the table has been taken by an Ace with "Asso piglia tutto"
+func (r *standardRules) IsScopa(cardPlayed deck.Card, cardsTaken []deck.Card) bool {
+	return !r.takesAllTable(cardPlayed, cardsTaken)
+}
+
+// Score calculates the score for the teams
+// - Carte, Denari, Primiera: one point to the team which has more cards, more Denari, highest Primiera - if more
+// teams have the same highest number no one gets the point
+// - Settebello: one point to the team which has the Seven of Denari
+// - Scope: one point for each Scopa
+// - Napoli: one point for each Denari in sequence, starting from the Ace, if they are at least napoliMinimum
+// - Re Bello: one point to the team which has the King of Denari, if the variant scores it
+func (r *standardRules) Score(teams []*team.Team) (scores []TeamScore) {
+	scores = make([]TeamScore, len(teams))
+	carte := make([]int, len(teams))
+	denari := make([]int, len(teams))
+	primiera := make([]int, len(teams))
+	for i, t := range teams {
+		scores[i].ScoreCard = fillScoreCard(t)
+		carte[i] = len(scores[i].ScoreCard.Carte)
+		denari[i] = len(scores[i].ScoreCard.Denari)
+		// Settebello
+		if scores[i].ScoreCard.Settebello {
+			scores[i].Score++
+		}
+		// Scope
+		scores[i].Score = scores[i].Score + len(scores[i].ScoreCard.Scope)
+		// Napoli
+		if r.napoliMinimum > 0 && len(scores[i].ScoreCard.Napoli) >= r.napoliMinimum {
+			scores[i].Score = scores[i].Score + len(scores[i].ScoreCard.Napoli)
+		}
+		// Re Bello
+		if r.reBello && scores[i].ScoreCard.ReBello {
+			scores[i].Score++
+		}
+		// calculate primieraScore
+		scores[i].PrimieraScore = calculatePrimieraScore(scores[i].ScoreCard.PrimieraSuits)
+		primiera[i] = scores[i].PrimieraScore
+	}
+	// Carte, Denari and Primiera
+	for _, values := range [][]int{carte, denari, primiera} {
+		if i, found := highest(values); found {
+			scores[i].Score++
+		}
+	}
+	return
+}
+
+// highest returns the index of the highest value - if more values are the highest it returns false
+func highest(values []int) (index int, found bool) {
+	for i, v := range values {
+		if !found || v > values[index] {
+			index = i
+			found = true
+		}
+	}
+	for i, v := range values {
+		if i != index && v == values[index] {
+			return index, false
+		}
+	}
+	return
+}
@@ -0,0 +1,288 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/team"
+)
+
+// newVariantGame creates a game with 4 players which plays with the rules of a variant
+func newVariantGame(s *Scopone, gName string, variant Variant) *Game {
+	return newGameWithOptions(s, gName, GameOptions{Variant: variant})
+}
+
+// newGameWithOptions creates a game with some options and adds to it all the players it needs
+func newGameWithOptions(s *Scopone, gName string, options GameOptions) *Game {
+	g, err := s.NewGame(ctx, gName, options)
+	if err != nil {
+		panic(err)
+	}
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"}[:g.seats()] {
+		s.PlayerEnters(ctx, pName, "")
+		if err := s.AddPlayerToGame(ctx, pName, gName); err != nil {
+			panic(err)
+		}
+	}
+	readyToStart(s, g)
+	return g
+}
+
+// playHandWithFirstLegalMove plays the current hand of the game until it is closed, each player always making the
+// first of its legal moves - it returns the number of cards played
+func playHandWithFirstLegalMove(t *testing.T, s *Scopone, g *Game) int {
+	cardsPlayed := 0
+	for IsCurrentHandActive(g) {
+		moves := g.LegalMoves()
+		if len(moves) == 0 {
+			t.Fatalf("The current player %v has no legal move", currentPlayer(g).Name)
+		}
+		_, _, err := s.Play(ctx, g, currentPlayer(g).Name, moves[0].CardPlayed, moves[0].CardsTaken)
+		if err != nil {
+			t.Fatalf("Playing the legal move %v returns an error %v", moves[0], err)
+		}
+		cardsPlayed++
+	}
+	return cardsPlayed
+}
+
+func TestRulesFor(t *testing.T) {
+	rules, err := RulesFor("")
+	if err != nil || rules.Variant() != DefaultVariant {
+		t.Errorf("The rules with no variant should be the ones of %v but are %v - error %v", DefaultVariant, rules, err)
+	}
+	for _, v := range []Variant{ScoponeScientifico, ScoponeClassico, ScoponeTrentino, Scopa} {
+		rules, err := RulesFor(v)
+		if err != nil || rules.Variant() != v {
+			t.Errorf("The rules of %v should be found but are %v - error %v", v, rules, err)
+		}
+	}
+	_, err = RulesFor("briscola")
+	if !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("An unknown variant should return ErrInvalidGameOptions but returns %v", err)
+	}
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	_, err = s.NewGame(ctx, "TestRulesFor", GameOptions{Variant: "briscola"})
+	if !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A game with an unknown variant should return ErrInvalidGameOptions but returns %v", err)
+	}
+}
+
+func TestGameRulesFromVariant(t *testing.T) {
+	// a game read from a store has only the variant
+	g := &Game{Variant: ScoponeTrentino}
+	if g.Rules().Variant() != ScoponeTrentino {
+		t.Errorf("The rules of the game should be %v but are %v", ScoponeTrentino, g.Rules().Variant())
+	}
+	// a game stored before the variants were introduced uses the default rules
+	g = &Game{}
+	if g.Rules().Variant() != DefaultVariant {
+		t.Errorf("The rules of the game should be %v but are %v", DefaultVariant, g.Rules().Variant())
+	}
+}
+
+func TestDealForVariants(t *testing.T) {
+	variantCases := []struct {
+		variant     Variant
+		playerCards int
+		tableCards  int
+	}{
+		{ScoponeScientifico, 10, 0},
+		{ScoponeClassico, 9, 4},
+		{ScoponeTrentino, 10, 0},
+		{Scopa, 3, 4},
+	}
+	for _, c := range variantCases {
+		s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+		g := newVariantGame(s, "TestDealForVariants", c.variant)
+		s.NewHand(ctx, g)
+		hand := currentHand(g)
+		if len(hand.Table) != c.tableCards {
+			t.Errorf("%v should have %v cards on the table but has %v", c.variant, c.tableCards, len(hand.Table))
+		}
+		allCards := hand.Table
+		for _, p := range g.Players {
+			if len(p.Cards) != c.playerCards {
+				t.Errorf("In %v %v should have %v cards but has %v", c.variant, p.Name, c.playerCards, len(p.Cards))
+			}
+			allCards = append(allCards, p.Cards...)
+		}
+		for i, card := range allCards {
+			if _, found := deck.Find(allCards[i+1:], card); found {
+				t.Errorf("In %v the card %v has been dealt twice", c.variant, card)
+			}
+		}
+	}
+}
+
+// For each variant a full hand is played and at the end all the cards of the deck must have been taken
+func TestPlayHandForVariants(t *testing.T) {
+	for _, v := range []Variant{ScoponeScientifico, ScoponeClassico, ScoponeTrentino, Scopa} {
+		s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+		g := newVariantGame(s, "TestPlayHandForVariants", v)
+		s.NewHand(ctx, g)
+		hand := currentHand(g)
+		tableCards := len(hand.Table)
+
+		cardsPlayed := playHandWithFirstLegalMove(t, s, g)
+
+		if cardsPlayed != 40-tableCards {
+			t.Errorf("In %v the cards played should be %v but are %v", v, 40-tableCards, cardsPlayed)
+		}
+		if hand.State != HandClosed {
+			t.Errorf("In %v the hand should be closed but is %v", v, hand.State)
+		}
+		takenCards := len(g.Teams[0].TakenCards) + len(g.Teams[1].TakenCards)
+		if takenCards != 40 {
+			t.Errorf("In %v the teams should have taken 40 cards but have taken %v", v, takenCards)
+		}
+	}
+}
+
+func TestAssoPigliaTutto(t *testing.T) {
+	trentino, _ := RulesFor(ScoponeTrentino)
+	scientifico, _ := RulesFor(ScoponeScientifico)
+	ace := deck.Card{Type: "Ace", Suit: deck.Denari}
+	table := []deck.Card{
+		{Type: "Five", Suit: "Spade"},
+		{Type: "King", Suit: "Bastoni"},
+		{Type: "Two", Suit: deck.Denari},
+	}
+
+	captures := trentino.Captures(ace, table)
+	if len(captures) != 1 || !sameCards(captures[0], table) {
+		t.Errorf("The Ace should take all the cards on the table but takes %v", captures)
+	}
+	if trentino.IsScopa(ace, table) {
+		t.Errorf("Taking the table with the Ace should not be Scopa")
+	}
+	if len(scientifico.Captures(ace, table)) != 0 {
+		t.Errorf("In %v the Ace should not take any card but takes %v", ScoponeScientifico, scientifico.Captures(ace, table))
+	}
+
+	// if there is an Ace on the table the Ace takes the Ace
+	aceOnTable := deck.Card{Type: "Ace", Suit: "Coppe"}
+	tableWithAce := append([]deck.Card{aceOnTable}, table...)
+	captures = trentino.Captures(ace, tableWithAce)
+	if len(captures) != 1 || !sameCards(captures[0], []deck.Card{aceOnTable}) {
+		t.Errorf("The Ace should take the Ace on the table but takes %v", captures)
+	}
+	if !trentino.IsScopa(ace, []deck.Card{aceOnTable}) {
+		t.Errorf("Taking the last Ace on the table with the Ace should be Scopa")
+	}
+}
+
+func TestScoreForVariants(t *testing.T) {
+	// the first team has the Denari from Ace to Four, the King of Denari and nothing else
+	takenCards1 := []deck.Card{
+		{Type: "Ace", Suit: deck.Denari},
+		{Type: "Two", Suit: deck.Denari},
+		{Type: "Three", Suit: deck.Denari},
+		{Type: "Four", Suit: deck.Denari},
+		{Type: "King", Suit: deck.Denari},
+	}
+	takenCards2, _ := deck.RemoveCards(deck.New(), takenCards1)
+	teams := []*team.Team{team.New(), team.New()}
+	teams[0].TakenCards = takenCards1
+	teams[1].TakenCards = takenCards2
+
+	scoreCases := []struct {
+		variant Variant
+		score   int
+	}{
+		// Napoli
+		{ScoponeScientifico, 4},
+		// no Napoli
+		{ScoponeClassico, 0},
+		// Napoli and Re Bello
+		{ScoponeTrentino, 5},
+		// no Napoli
+		{Scopa, 0},
+	}
+	for _, c := range scoreCases {
+		rules, _ := RulesFor(c.variant)
+		scores := rules.Score(teams)
+		if scores[0].Score != c.score {
+			t.Errorf("In %v the score of the first team should be %v but is %v", c.variant, c.score, scores[0].Score)
+		}
+		if !scores[0].ScoreCard.ReBello {
+			t.Errorf("The score card of the first team should have Re Bello")
+		}
+	}
+}
+
+func TestGameOptionsNumberOfPlayers(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	invalidOptions := []GameOptions{
+		{Variant: ScoponeScientifico, NumberOfPlayers: 2},
+		{Variant: ScoponeClassico, NumberOfPlayers: 3},
+		{Variant: Scopa, NumberOfPlayers: 5},
+		{Variant: Scopa, NumberOfPlayers: 1},
+	}
+	for _, o := range invalidOptions {
+		_, err := s.NewGame(ctx, "TestGameOptionsNumberOfPlayers", o)
+		if !errors.Is(err, ErrInvalidGameOptions) {
+			t.Errorf("A game with options %v should return ErrInvalidGameOptions but returns %v", o, err)
+		}
+	}
+}
+
+func TestScopaWithTwoAndThreePlayers(t *testing.T) {
+	for _, numberOfPlayers := range []int{2, 3} {
+		s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+		g := newGameWithOptions(s, "TestScopaWithTwoAndThreePlayers", GameOptions{Variant: Scopa, NumberOfPlayers: numberOfPlayers})
+		if len(g.Teams) != numberOfPlayers {
+			t.Errorf("A game of %v players should have %v teams but has %v", numberOfPlayers, numberOfPlayers, len(g.Teams))
+		}
+		if g.State != GameOpen {
+			t.Errorf("A game of %v players with all the players should be open but is %v", numberOfPlayers, g.State)
+		}
+		s.PlayerEnters(ctx, "Player_5", "")
+		err := s.AddPlayerToGame(ctx, "Player_5", g.Name)
+		if !errors.Is(err, ErrGameFull) {
+			t.Errorf("Adding a player to a game of 3 players already full should return ErrGameFull but returns %v", err)
+		}
+
+		_, handViews, _ := s.NewHand(ctx, g)
+		for _, p := range g.Players {
+			if len(p.Cards) != 3 {
+				t.Errorf("%v should have 3 cards but has %v", p.Name, len(p.Cards))
+			}
+			if len(handViews[p.Name].Teams) != numberOfPlayers {
+				t.Errorf("The view of %v should have %v teams but has %v", p.Name, numberOfPlayers, len(handViews[p.Name].Teams))
+			}
+		}
+		// each player plays after the previous one and after the last one it is again the turn of the first one
+		order := playersInPlayingOrder(g)
+		if len(order) != numberOfPlayers {
+			t.Errorf("The players in playing order should be %v but are %v", numberOfPlayers, len(order))
+		}
+		for i, p := range order {
+			next := nextPlayer(g)
+			if currentPlayer(g) != p {
+				t.Errorf("The current player should be %v but is %v", p.Name, currentPlayer(g).Name)
+			}
+			if next != order[(i+1)%numberOfPlayers] {
+				t.Errorf("The player after %v should be %v but is %v", p.Name, order[(i+1)%numberOfPlayers].Name, next.Name)
+			}
+			moves := g.LegalMoves()
+			s.Play(ctx, g, p.Name, moves[0].CardPlayed, moves[0].CardsTaken)
+		}
+
+		cardsPlayed := numberOfPlayers + playHandWithFirstLegalMove(t, s, g)
+		if cardsPlayed != 36 {
+			t.Errorf("In a game of %v players the cards played should be 36 but are %v", numberOfPlayers, cardsPlayed)
+		}
+		takenCards := 0
+		for _, team := range g.Teams {
+			takenCards = takenCards + len(team.TakenCards)
+		}
+		if takenCards != 40 {
+			t.Errorf("In a game of %v players the teams should have taken 40 cards but have taken %v", numberOfPlayers, takenCards)
+		}
+		if len(currentHand(g).Score) != numberOfPlayers {
+			t.Errorf("In a game of %v players there should be %v scores but are %v", numberOfPlayers, numberOfPlayers, len(currentHand(g).Score))
+		}
+	}
+}
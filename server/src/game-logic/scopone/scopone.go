@@ -2,10 +2,16 @@
 package scopone
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/player"
@@ -16,14 +22,32 @@ import (
 
 // Scopone is a traditional italian card game usually played in the Osteria which is a traditional bar
 type Scopone struct {
-	Players     map[string]*player.Player
-	Games       map[string]*Game
+	Players map[string]*player.Player
+	Games   map[string]*Game
+	// Bots are the strategies of the players controlled by the computer, with the name of the bot as key
+	Bots map[string]BotStrategy
+	// Shuffler shuffles the decks of the hands of the games which have no seed
+	Shuffler    deck.Shuffler
 	PlayerStore PlayerWriter
 	GameStore   GameReadWriter
+	// ReconnectGracePeriod is the time for which the seat of a player who has left the Osteria is kept for the
+	// player, see ReconnectToken
+	ReconnectGracePeriod time.Duration
+	// TimeoutStrategy chooses the move played for the players whose turn has expired, see PlayTimedOutTurn - if it
+	// is nil the first legal move is played
+	TimeoutStrategy BotStrategy
+	// seatTokens are the reconnect tokens of the seats of the players, with the name of the player as key
+	seatTokens map[string]*SeatToken
+	// now returns the current time - it is nil if the time is the time of the clock
+	now func() time.Time
+	// mu is the lock of the Osteria - see LockOsteria and LockGame
+	mu sync.RWMutex
 }
 
 // New Scopone
-func New(playerStore PlayerWriter, gameStore GameReadWriter) *Scopone {
+// The open games are read from the game store - if they can not be read the Osteria starts empty rather than not
+// starting at all, so that the players can still play while the store is unreachable
+func New(ctx context.Context, playerStore PlayerWriter, gameStore GameReadWriter) *Scopone {
 	fmt.Println("Start Scopone")
 
 	viper.SetDefault("VERSION", "no version set")
@@ -47,34 +71,45 @@ func New(playerStore PlayerWriter, gameStore GameReadWriter) *Scopone {
 
 	s.PlayerStore = playerStore
 	s.GameStore = gameStore
-	games, players, err := gameStore.ReadOpenGames()
+	games, players, err := gameStore.ReadOpenGames(ctx)
 	if err != nil {
-		log.Println("Error occurred while reading games from store")
+		log.Printf("Error occurred while reading games from store, the Osteria starts with no games: %v\n", err)
+		games = make(map[string]*Game)
+		players = make(map[string]*player.Player)
 	}
 	s.Games = games
 	s.Players = players
+	s.Bots = make(map[string]BotStrategy)
+	s.Shuffler = &deck.CryptoShuffler{}
+	s.ReconnectGracePeriod = DefaultReconnectGracePeriod
+	s.detachSeatsLeft()
+	s.keepSeatsOfGamesRead()
+	s.restartTurnsOfGamesRead()
 	return &s
 }
 
 // PlayerEnters creates a player if it was never in the Osteria, reactivate the player if it was inactive because
-// got disconnected and returns an error if the Player is already in the Osteria and is active
-func (s *Scopone) PlayerEnters(pName string) (handViews map[string]HandPlayerView, alreadyIn bool) {
-	alreadyIn = false
-
+// got disconnected and returns an error wrapping ErrPlayerAlreadyInOsteria if the Player is already in the Osteria
+// and is active
+// A player who comes back to a game has to present the reconnect token of the seat, see ReconnectToken, otherwise
+// an error wrapping ErrInvalidReconnectToken is returned and the player does not enter - the token is not needed
+// to enter the Osteria when the player has no seat in any game
+func (s *Scopone) PlayerEnters(ctx context.Context, pName string, reconnectToken string) (handViews map[string]HandPlayerView, err error) {
 	if pName == "" {
-		panic("The name of the player you want to add is empty\n")
+		return nil, ErrEmptyPlayerName
 	}
 
 	plr, found := s.Players[pName]
 	// the player is new and therefore needs to be created
 	if !found {
 		p := player.New(pName)
-		s.Players[pName] = p
 		p.Status = player.PlayerNotPlaying
-		err := s.PlayerStore.AddPlayerEntry(p)
+		// the entry is written first so that, if the store fails, the player is not left in the Osteria
+		err = storeError(s.PlayerStore.AddPlayerEntry(ctx, p))
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
+		s.Players[pName] = p
 		return
 	}
 
@@ -83,14 +118,20 @@ func (s *Scopone) PlayerEnters(pName string) (handViews map[string]HandPlayerVie
 	pStatus := plr.Status
 	switch pStatus {
 	case player.PlayerLeftOsteria:
-		fmt.Printf("Player %v returned to the Osteria\n", pName)
-		err := s.PlayerStore.AddPlayerEntry(plr)
-		if err != nil {
-			panic(err)
-		}
 		// find if the player was playeing or observing any game
 		gameOfPlayer, pFound := findGameForPlayer(plr, s.Games)
 		_, oFound := findGameForObserver(plr.Name, s.Games)
+		if pFound {
+			err = s.resumeSeat(pName, gameOfPlayer.Name, reconnectToken)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fmt.Printf("Player %v returned to the Osteria\n", pName)
+		err = storeError(s.PlayerStore.AddPlayerEntry(ctx, plr))
+		if err != nil {
+			return nil, err
+		}
 		if !pFound && !oFound {
 			// if the player was not in any game, then the player has just come back to the Osteria
 			plr.Status = player.PlayerNotPlaying
@@ -101,32 +142,34 @@ func (s *Scopone) PlayerEnters(pName string) (handViews map[string]HandPlayerVie
 		// that the player is coming back to the game
 		if oFound {
 			plr.Status = player.PlayerObserving
-			return handViews, false
+			return handViews, nil
 		}
 
 		plr.Status = player.PlayerPlaying
 		setStatusWhenHandClosed(gameOfPlayer, plr)
 		// the state needs to be calculated after the status of the player has been updated - this piece is a bit
 		// too much of stateful logic but this is how it is, at least for the moment
-		gameOfPlayer.CalculateState()
-		handViews := buildCurrentHandView(gameOfPlayer)
-		return handViews, false
+		err = gameOfPlayer.CalculateState()
+		if err != nil {
+			return nil, err
+		}
+		handViews := s.currentHandViews(gameOfPlayer)
+		return handViews, nil
 
 	default:
-		alreadyIn = true
-		return
+		return nil, fmt.Errorf("%w - Player \"%v\" is already in the Osteria", ErrPlayerAlreadyInOsteria, pName)
 	}
 }
 
 // findGameForPlayer returns the game the player is playing - if the player is not playing then it returns false
 // in the second returned value
-// There must be ONLY ONE game at most that a player plays
+// There must be ONLY ONE game at most that a player plays - the players of a finished game are free to play another
 func findGameForPlayer(player *player.Player, games map[string]*Game) (*Game, bool) {
 	for gK := range games {
 		gameK := games[gK]
-		if gameK.State != GameClosed {
+		if !gameK.over() {
 			for pK := range gameK.Players {
-				if gameK.Players[pK].Name == player.Name {
+				if gameK.Players[pK].Name == player.Name && !gameK.SeatsLeft[pK] {
 					return gameK, true
 				}
 			}
@@ -135,6 +178,15 @@ func findGameForPlayer(player *player.Player, games map[string]*Game) (*Game, bo
 	return nil, false
 }
 
+// GameOfPlayer returns the game the player is playing, if any - the caller has to hold the lock of the Osteria
+func (s *Scopone) GameOfPlayer(pName string) (*Game, bool) {
+	p, found := s.Players[pName]
+	if !found {
+		return nil, false
+	}
+	return findGameForPlayer(p, s.Games)
+}
+
 // setStatusWhenHandClosed set the status to "PlayerLookingAtHandResult" if the hand is closed
 func setStatusWhenHandClosed(g *Game, p *player.Player) {
 	// if the Game has already one hand and the last hand is closed, it means that the player is just looking at the results
@@ -153,7 +205,7 @@ func setStatusWhenHandClosed(g *Game, p *player.Player) {
 func findGameForObserver(observerName string, games map[string]*Game) (*Game, bool) {
 	for gK := range games {
 		gameK := games[gK]
-		if gameK.State != GameClosed {
+		if !gameK.over() {
 			for pK := range gameK.Observers {
 				if gameK.Observers[pK].Name == observerName {
 					return gameK, true
@@ -180,93 +232,124 @@ func (s *Scopone) AllPlayers() (allPlayers []*player.Player) {
 // then the Player is brought back to his previous state (see addPlayer function)
 // If the player was playing a game, then the players of the game are returned
 // so that the server can update the clients
-func (s *Scopone) RemovePlayer(playerName string) (players map[string]*player.Player, wasPlaying bool) {
+func (s *Scopone) RemovePlayer(playerName string) (players map[string]*player.Player, wasPlaying bool, err error) {
 	plr, found := s.Players[playerName]
 	if !found {
-		msg := fmt.Sprintf("We are trying to remove Player %v but the player is not in the Osteria", playerName)
-		panic(msg)
+		err = fmt.Errorf("%w - We are trying to remove Player %v but the player is not in the Osteria", ErrPlayerNotFound, playerName)
+		return
 	}
 	if plr.Status == player.PlayerLeftOsteria {
-		msg := fmt.Sprintf("We are trying to remove Player %v but the player has been already removed", playerName)
-		panic(msg)
+		err = fmt.Errorf("%w - We are trying to remove Player %v but the player has been already removed", ErrPlayerAlreadyLeft, playerName)
+		return
 	}
 	plr.Status = player.PlayerLeftOsteria
 	playerGame, found := findGameForPlayer(plr, s.Games)
 	if found {
+		s.seatLeft(playerName)
 		playerGame.Suspend()
-		return playerGame.Players, true
+		return playerGame.Players, true, nil
 	}
 	observerGame, found := findGameForObserver(playerName, s.Games)
 	if found {
 		delete(observerGame.Observers, playerName)
 	}
-	return nil, false
+	return nil, false, nil
 }
 
-// NewGame creates a new Game unsless a Game with the same name is already present
-func (s *Scopone) NewGame(gName string) (g *Game, e error) {
+// NewGame creates a new Game with the options passed in unsless a Game with the same name is already present
+func (s *Scopone) NewGame(ctx context.Context, gName string, options GameOptions) (g *Game, e error) {
 	_, found := s.Games[gName]
 	if found {
-		e = fmt.Errorf("Game %v already present in the Osteria", gName)
+		e = fmt.Errorf("%w - Game \"%v\" with the same name already created", ErrGameAlreadyPresent, gName)
+		return
+	}
+	e = options.validate()
+	if e != nil {
+		return
+	}
+	rules, e := RulesFor(options.Variant)
+	if e != nil {
 		return
 	}
-	game := NewGame()
+	numberOfPlayers := options.NumberOfPlayers
+	if numberOfPlayers == 0 {
+		numberOfPlayers = 4
+	}
+	game := newGameWithPlayers(numberOfPlayers)
 	game.Name = gName
-	g = game
-	s.Games[gName] = g
-	err := s.GameStore.WriteGame(g)
+	game.TargetScore = options.TargetScore
+	game.Seed = options.Seed
+	game.MoveTimeLimit = options.MoveTimeLimit
+	game.ObserverMode = options.ObserverMode
+	game.ObserverDelay = options.ObserverDelay
+	if err := game.setPrivacy(options); err != nil {
+		return nil, err
+	}
+	game.NoTeamChatInHands = options.NoTeamChatInHands
+	game.setRules(rules)
+	err := storeError(s.GameStore.WriteGame(ctx, game))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	g = game
+	s.Games[gName] = g
 	return
 }
 
-// AddPlayerToGame sends the request to the game to add one player
-func (s *Scopone) AddPlayerToGame(playerName string, gameName string) (e error) {
-	g, gfound := s.Games[gameName]
-	if !gfound {
-		e = fmt.Errorf("There is no Game with name %v", gameName)
-		return
+// AddPlayerToGame sends the request to the game to add one player, who gets the reconnect token of the seat
+// If the game has all its players, the player takes the seat of a player who has left the Osteria for longer than
+// the grace period, if any, see TakeSeat
+func (s *Scopone) AddPlayerToGame(ctx context.Context, playerName string, gameName string) error {
+	return s.AddPlayerToSeat(ctx, playerName, gameName, AnySeat)
+}
+
+// addToSeat adds the player to the seat of the game, or to the first free seat with AnySeat, or, if the game has
+// all its players, to the seat of a player whose grace period has expired
+func (s *Scopone) addToSeat(g *Game, p *player.Player, seat int) error {
+	err := g.addPlayerToSeat(p, seat)
+	if !errors.Is(err, ErrGameFull) {
+		return err
 	}
-	p, pfound := s.Players[playerName]
-	if !pfound {
-		e = fmt.Errorf("There is no Player with name %v", playerName)
-		return
+	replaced, expired := s.expiredSeat(g)
+	if g.SeatsLeft[p.Name] {
+		// the player takes back the seat left
+		replaced, expired = g.Players[p.Name], true
 	}
-	err := g.AddPlayer(p)
-	if err == nil {
-		err_ := s.GameStore.WriteGame(g)
-		if err_ != nil {
-			panic(err_)
-		}
+	if !expired {
+		return err
 	}
-	return err
+	if replacedSeat, _ := g.SeatOf(replaced.Name); seat != AnySeat && seat != replacedSeat {
+		return err
+	}
+	return s.takeSeat(g, replaced, p)
 }
 
 // AddObserverToGame sends the request to the game to add one observer
-func (s *Scopone) AddObserverToGame(playerName string, gameName string) (handViews map[string]HandPlayerView, e error) {
+func (s *Scopone) AddObserverToGame(ctx context.Context, playerName string, gameName string) (handViews map[string]HandPlayerView, e error) {
 	g, gfound := s.Games[gameName]
 	if !gfound {
-		e = fmt.Errorf("There is no Game with name %v", gameName)
+		e = fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
 		return
 	}
 	p, pfound := s.Players[playerName]
 	if !pfound {
-		e = fmt.Errorf("There is no Player with name %v", playerName)
+		e = fmt.Errorf("%w - There is no Player with name %v", ErrPlayerNotFound, playerName)
 		return
 	}
-	handViews = buildCurrentHandView(g)
+	handViews = s.currentHandViews(g)
 	e = g.AddObserver(p)
-	if e == nil {
-		err_ := s.GameStore.WriteGame(g)
-		if err_ != nil {
-			panic(err_)
-		}
+	if e != nil {
+		return nil, e
+	}
+	err := storeError(s.GameStore.WriteGame(ctx, g))
+	if err != nil {
+		return nil, err
 	}
-	return handViews, e
+	return handViews, nil
 }
 
-// AllGames returns all the games in the Osteria
+// AllGames returns all the games in the Osteria, private games included - see GamesVisibleTo for the games a
+// player can see
 func (s *Scopone) AllGames() (allGames []*Game) {
 	allGames = make([]*Game, 0)
 	for gK := range s.Games {
@@ -277,19 +360,43 @@ func (s *Scopone) AllGames() (allGames []*Game) {
 }
 
 // NewHand creates a new hand and saves it in the store
-func (s *Scopone) NewHand(g *Game) (hand Hand, handView map[string]HandPlayerView, handCreated bool) {
-	handCreated = true
+// If the last hand of the game is still active no hand is created and an error wrapping ErrHandStillActive is
+// returned - this can happen when more players ask for a new hand at the same time and so it is not necessarily
+// something the player should be notified about
+func (s *Scopone) NewHand(ctx context.Context, g *Game) (hand Hand, handView map[string]HandPlayerView, err error) {
+	if g == nil {
+		err = ErrGameNotFound
+		return
+	}
+	if len(g.Players) < g.seats() {
+		err = fmt.Errorf("%w - A new hand can not be started in game %v before the teams are made", ErrGameNotStarted, g.Name)
+		return
+	}
+	if g.State == GameFinished || g.State == GameClosed {
+		err = fmt.Errorf("%w - A new hand can not be started in game %v which is %v", ErrGameFinished, g.Name, g.State)
+		return
+	}
+	if len(g.Hands) == 0 && !g.allReady() {
+		err = fmt.Errorf("%w - The first hand of game %v can not be started before all the players are ready",
+			ErrPlayersNotReady, g.Name)
+		return
+	}
 	if len(g.Hands) > 0 {
 		lastHand := g.Hands[len(g.Hands)-1]
 		if lastHand.State == HandActive {
-			handCreated = false
+			err = fmt.Errorf("%w - A new hand can not be started in game %v", ErrHandStillActive, g.Name)
 			return
 		}
 	}
 	newDeck := deck.New()
-	deck.Shuffle(newDeck)
+	var seed int64
+	if g.Seed != 0 {
+		seed = handSeed(g.Seed, len(g.Hands))
+		deck.ShuffleWithSeed(newDeck, seed)
+	} else {
+		seed = s.Shuffler.Shuffle(newDeck)
+	}
 	hand.Deck = newDeck
-	hand.Table = make([]deck.Card, 0)
 	hand.Score = make(map[string]TeamScore)
 	if len(g.Hands) == 0 {
 		hand.FirstPlayer = g.Teams[0].Players[0]
@@ -299,30 +406,30 @@ func (s *Scopone) NewHand(g *Game) (hand Hand, handView map[string]HandPlayerVie
 	}
 	hand.CurrentPlayer = hand.FirstPlayer
 	g.Hands = append(g.Hands, &hand)
-	// gives cards to the Players and initializes Scope and TakenCards
+	// initializes Scope and TakenCards and gives cards to the Players and to the table as the rules say
 	for i := range g.Teams {
 		g.Teams[i].ScopeDiScopone = []deck.Card{}
 		g.Teams[i].TakenCards = []deck.Card{}
-		for j := range g.Teams[i].Players {
-			start := i*20 + j*10
-			g.Teams[i].Players[j].Cards = currentHand(g).Deck[start : start+10]
-		}
 	}
+	g.Rules().Deal(&hand, playersInPlayingOrder(g))
 	hand.State = HandActive
-	hand.History = HandHistory{}
+	hand.History = HandHistory{Seed: seed, Deck: newDeck}
 	g.History = append(g.History, &hand.History)
 	var playerDecks = make(map[string][]deck.Card)
 	for _, p := range g.Players {
 		playerDecks[p.Name] = p.Cards
 	}
 	hand.History.PlayerDecks = playerDecks
-	if handCreated {
-		err_ := s.GameStore.WriteGame(g)
-		if err_ != nil {
-			panic(err_)
-		}
-	}
-	return hand, buildHandView(&hand, g), handCreated
+	s.startTurn(&hand)
+	err = storeError(s.GameStore.WriteGame(ctx, g))
+	return hand, s.handViews(&hand, g), err
+}
+
+// handSeed returns the seed of a hand of a game with a seed - the seeds of the hands are sent to the players at the
+// end of the hands and so they are derived with a hash from which the seed of the game can not be guessed
+func handSeed(gameSeed int64, handIndex int) int64 {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%v-%v", gameSeed, handIndex)))
+	return int64(binary.BigEndian.Uint64(h[:8]))
 }
 
 // FinalTableTake represents the cards, if any, taken from the table as result o the LAST card played
@@ -338,34 +445,97 @@ type FinalTableTake struct {
 // - set the Scopa if the table is left empty
 // - if this is the last card of the last player closes the hand
 // - otherwise set the next player as current player
-func (s *Scopone) Play(pName string, cardPlayed deck.Card, cardsTaken []deck.Card) (handViews map[string]HandPlayerView,
-	finalTableTake FinalTableTake, g *Game) {
+// If the card played is not in the hands of the player or the cards taken are not a legal capture an
+// IllegalPlayError is returned and the game is left unchanged
+func (s *Scopone) Play(ctx context.Context, g *Game, pName string, cardPlayed deck.Card, cardsTaken []deck.Card) (
+	handViews map[string]HandPlayerView, finalTableTake FinalTableTake, err error) {
+	if g == nil {
+		err = ErrGameNotFound
+		return
+	}
 	p, pFound := s.Players[pName]
 	if !pFound {
-		panic(fmt.Sprintf("Panicking! No player with name %v\n", pName))
+		err = fmt.Errorf("%w - No player with name %v", ErrPlayerNotFound, pName)
+		return
+	}
+	if _, found := g.Players[pName]; !found || g.SeatsLeft[pName] || g.State == GameClosed {
+		err = fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, pName, g.Name)
+		return
 	}
-	g, gFound := findGameForPlayer(p, s.Games)
-	if !gFound {
-		panic(fmt.Sprintf("Panicking! Player %v is not playing any game\n", pName))
+	if g.State == GameFinished {
+		err = fmt.Errorf("%w - %v tries to play in game %v which is finished", ErrGameFinished, pName, g.Name)
+		return
 	}
-	if len(g.Players) < 4 {
-		panicMessage := fmt.Sprintf("%v tries to play before the teams are made and the game is started\n", pName)
-		panic(panicMessage)
+	if len(g.Players) < g.seats() || !IsCurrentHandActive(g) {
+		err = fmt.Errorf("%w - %v tries to play before the teams are made and the hand is started", ErrGameNotStarted, pName)
+		return
 	}
 	if pName != currentPlayer(g).Name {
 		// if by chance we receive the command to play a card from a player who is not the current player
-		// we log a warning and ignore the command - this situation should not happen but we have seen it happen
+		// we return an error and ignore the command - this situation should not happen but we have seen it happen
 		// when the current user clicks twice fast and the front end does not check this situation
-		log.Printf("Warning! Player with name %v is not the current player %v\n", pName, currentPlayer(g).Name)
+		err = fmt.Errorf("%w - Player with name %v is not the current player %v", ErrNotYourTurn, pName, currentPlayer(g).Name)
 		return
 	}
 	if cardPlayed.Suit == "" || cardPlayed.Type == "" {
-		panicMessage := fmt.Sprintf("The card played %v has either no suit or no type or none of these things\n", cardPlayed)
-		panic(panicMessage)
+		err = fmt.Errorf("%w - The card played %v has either no suit or no type or none of these things", ErrInvalidCard, cardPlayed)
+		return
 	}
 
+	finalTableTake, handOver, err := playCard(g, p, cardPlayed, cardsTaken)
+	if err != nil {
+		return nil, finalTableTake, err
+	}
+	if handOver {
+		err = closeCurrentHand(g)
+		if err != nil {
+			return nil, finalTableTake, err
+		}
+	} else {
+		s.startTurn(currentHand(g))
+	}
+
+	handViews = s.handViews(currentHand(g), g)
+	err = storeError(s.GameStore.WriteGame(ctx, g))
+	if err != nil {
+		// the card has been played anyway, so the views are returned together with the error
+		return handViews, finalTableTake, err
+	}
+	return handViews, finalTableTake, nil
+}
+
+// playCard plays a card of the current player of the game, validating the play against the rules of the game
+// It returns true if the card is the last card of the hand, in which case the hand has to be closed
+// If the play is not valid an error is returned and the game is left unchanged
+func playCard(g *Game, p *player.Player, cardPlayed deck.Card, cardsTaken []deck.Card) (finalTableTake FinalTableTake,
+	handOver bool, err error) {
+	pName := p.Name
 	hand := currentHand(g)
 
+	rules := g.Rules()
+	err = validatePlay(pName, p.Cards, hand.Table, cardPlayed, cardsTaken, rules)
+	if err != nil {
+		return finalTableTake, false, err
+	}
+
+	playerTeam, e := teamOfPlayer(pName, g)
+	if e != nil {
+		err = fmt.Errorf("%w - No team for player with name %v", ErrPlayerNotFound, pName)
+		return
+	}
+
+	// take the cardPlayed card out of the cards of the Player and, if there are cards taken, remove them from the table
+	// the play has been validated so these operations are expected to succeed and are performed before changing
+	// the state of the game so that, in case of error, the game is left unchanged
+	playerCards, err := deck.RemoveCard(p.Cards, cardPlayed)
+	if err != nil {
+		return finalTableTake, false, fmt.Errorf("%w - %v", ErrCardNotInHand, err)
+	}
+	table, err := deck.RemoveCards(hand.Table, cardsTaken)
+	if err != nil {
+		return finalTableTake, false, fmt.Errorf("%w - %v", ErrIllegalPlay, err)
+	}
+
 	// register the data relative to the card played, the state of the game at that moment and the cards taken
 	var playerDecks = make(map[string][]deck.Card)
 	for _, p := range g.Players {
@@ -380,12 +550,13 @@ func (s *Scopone) Play(pName string, cardPlayed deck.Card, cardsTaken []deck.Car
 	}
 	hand.History.CardPlaySequence = append(hand.History.CardPlaySequence, cardPlay)
 
-	// take the cardPlayed card out of the cards of the Player
-	p.Cards = deck.RemoveCard(p.Cards, cardPlayed)
+	p.Cards = playerCards
 
-	playerTeam, e := teamOfPlayer(pName, g)
-	if e != nil {
-		panic(fmt.Sprintf("Panicking! No team for player with name %v\n", pName))
+	// when the last player of a round has played the last card more cards are dealt, if the rules say so,
+	// otherwise the hand is over
+	lastCardOfHand := false
+	if len(p.Cards) == 0 && isLastPlayer(pName, g) {
+		lastCardOfHand = !rules.DealNextRound(hand, playersInPlayingOrder(g))
 	}
 
 	// if there are no cards taken, add the card played to the table
@@ -393,19 +564,21 @@ func (s *Scopone) Play(pName string, cardPlayed deck.Card, cardsTaken []deck.Car
 		hand.Table = append(hand.Table, cardPlayed)
 	} else {
 		// if there are cards taken, remove them from the table
-		hand.Table = deck.RemoveCards(hand.Table, cardsTaken)
+		hand.Table = table
 		// and add them to the cardsTaken by the team
 		playerTeam.TakenCards = append(playerTeam.TakenCards, cardsTaken...)
 		// and add the card played to the cards of the team
 		playerTeam.TakenCards = append(playerTeam.TakenCards, cardPlayed)
-		// if there are no cards on the table and it is not the last player on the last round, then this is Scopa
-		if len(hand.Table) == 0 && !(isLastPlayer(pName, g) && len(p.Cards) == 0) {
+		// if there are no cards on the table and it is not the last card of the hand, then this is Scopa
+		// unless the rules say otherwise
+		if len(hand.Table) == 0 && !lastCardOfHand && rules.IsScopa(cardPlayed, cardsTaken) {
 			playerTeam.ScopeDiScopone = append(playerTeam.ScopeDiScopone, cardPlayed)
 		}
 	}
 
-	// if this is the last card of the last player, give all cards on the table to the last team which took some cards
-	if len(p.Cards) == 0 && isLastPlayer(pName, g) {
+	// if this is the last card of the hand, give all cards on the table to the last team which took some cards, as
+	// in all the variants
+	if lastCardOfHand {
 		//
 		// this block is to manage in the history the fact that we can have to add a final CardPlay, with no card played
 		// but with cards taken from the table to represent the fact that it is not the last team to get the cards left
@@ -420,11 +593,11 @@ func (s *Scopone) Play(pName string, cardPlayed deck.Card, cardsTaken []deck.Car
 					lastCardPlayResultingInTakingCards = cardPlay
 				}
 			}
-			lastTeamTakingCards, _ = teamOfPlayer(lastCardPlayResultingInTakingCards.Player, g)
+			playerTakingLastHandName = lastCardPlayResultingInTakingCards.Player
+			lastTeamTakingCards, _ = teamOfPlayer(playerTakingLastHandName, g)
 			lastTeamTakingCards.TakenCards = append(lastTeamTakingCards.TakenCards, hand.Table...)
 			// add to history the cardPlay representing the cards on the table when the last card of the game is played
 			if lastTeamTakingCards != playerTeam {
-				playerTakingLastHandName = lastCardPlayResultingInTakingCards.Player
 				finalTableCardPlay := HandCardPlay{
 					Player:     playerTakingLastHandName,
 					CardPlayed: deck.Card{},
@@ -440,29 +613,26 @@ func (s *Scopone) Play(pName string, cardPlayed deck.Card, cardsTaken []deck.Car
 			//
 		}
 		hand.Table = []deck.Card{}
-		closeCurrentHand(g)
-	} else {
-		// otherwise sets the next player as current
-		hand.CurrentPlayer = nextPlayer(g)
+		return finalTableTake, true, nil
 	}
 
-	handViews = buildHandView(hand, g)
-	err_ := s.GameStore.WriteGame(g)
-	if err_ != nil {
-		panic(err_)
-	}
-	return handViews, finalTableTake, g
+	// otherwise sets the next player as current
+	hand.CurrentPlayer = nextPlayer(g)
+	return finalTableTake, false, nil
 }
 
 // Close the game and sets all other players as not playing
 // this means that if just ONE player leaves the game, all other players leave it
-func (s *Scopone) Close(gName string, playerClosing string) {
-	g := s.Games[gName]
-	g.Close(playerClosing)
-	err_ := s.GameStore.WriteGame(g)
-	if err_ != nil {
-		panic(err_)
+func (s *Scopone) Close(ctx context.Context, gName string, playerClosing string) error {
+	g, found := s.Games[gName]
+	if !found {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gName)
 	}
+	if err := g.CheckPlaying(playerClosing); err != nil {
+		return err
+	}
+	g.Close(playerClosing)
+	return storeError(s.GameStore.WriteGame(ctx, g))
 }
 
 // teamOfPlayer returns the teamOfPlayer of the Player
@@ -499,75 +669,58 @@ func isLastPlayer(pName string, g *Game) bool {
 
 // playersSequence returns a map of Players where the key is a Player and the value is the next player
 func playersSequence(game *Game) map[string]*player.Player {
-	teams := game.Teams
-	sequence := map[string]*player.Player{
-		teams[0].Players[0].Name: teams[1].Players[0],
-		teams[1].Players[0].Name: teams[0].Players[1],
-		teams[0].Players[1].Name: teams[1].Players[1],
-		teams[1].Players[1].Name: teams[0].Players[0],
+	players := game.seatingOrder()
+	sequence := make(map[string]*player.Player)
+	for i, p := range players {
+		sequence[p.Name] = players[(i+1)%len(players)]
 	}
 	return sequence
 }
 
+// playersInPlayingOrder returns the players of the game in the order they play in the current hand, starting
+// from the first player
+func playersInPlayingOrder(g *Game) []*player.Player {
+	sequence := playersSequence(g)
+	first := currentHand(g).FirstPlayer
+	players := []*player.Player{first}
+	for p := sequence[first.Name]; p.Name != first.Name; p = sequence[p.Name] {
+		players = append(players, p)
+	}
+	return players
+}
+
 // nextPlayer returns the player who has to play next in the game
 func nextPlayer(g *Game) *player.Player {
 	return playersSequence(g)[currentPlayer(g).Name]
 }
 
 // closeCurrentHand closes the hand which is currently played
-func closeCurrentHand(g *Game) {
+// If at the end of the hand the teams do not have all the cards of the deck, an error wrapping ErrInconsistentHand
+// is returned
+func closeCurrentHand(g *Game) error {
 	var cards []deck.Card
 	for _, tt := range g.Teams {
 		cards = append(cards, tt.TakenCards...)
 	}
 	if len(cards) != 40 {
-		panic(fmt.Sprintf("Panicking! At the end the teams have %v cards\n", len(cards)))
+		return fmt.Errorf("%w - At the end the teams have %v cards", ErrInconsistentHand, len(cards))
 	}
+	scoreCurrentHand(g)
+	fmt.Printf("Hand %v closed\n", len(g.Hands))
+	g.checkFinished()
+	return nil
+}
+
+// scoreCurrentHand closes the hand which is currently played, registers the scores of the teams in the hand and
+// adds them to the scores of the game
+func scoreCurrentHand(g *Game) {
 	currentHand := currentHand(g)
 	currentHand.State = HandClosed
-	scores := calculateScore([]*team.Team{g.Teams[0], g.Teams[1]})
+	scores := g.Rules().Score(g.Teams)
 	for i, s := range scores {
 		currentHand.Score[team.Name(g.Teams[i])] = s
 		g.Score[team.Name(g.Teams[i])] = g.Score[team.Name(g.Teams[i])] + s.Score
 	}
-	fmt.Printf("Hand %v closed\n", len(g.Hands))
-}
-
-// calculateScore calculate the score for the teams
-func calculateScore(teams []*team.Team) (scores []TeamScore) {
-	for i, t := range teams {
-		var score TeamScore
-		scores = append(scores, score)
-		scores[i].ScoreCard = fillScoreCard(t)
-		// Carte
-		if len(scores[i].ScoreCard.Carte) > 20 {
-			scores[i].Score++
-		}
-		// Denari
-		if len(scores[i].ScoreCard.Denari) > 5 {
-			scores[i].Score++
-		}
-		// Settebello
-		if scores[i].ScoreCard.Settebello {
-			scores[i].Score++
-		}
-		// Scope
-		scores[i].Score = scores[i].Score + len(scores[i].ScoreCard.Scope)
-		// Napoli
-		if len(scores[i].ScoreCard.Napoli) > 2 {
-			scores[i].Score = scores[i].Score + len(scores[i].ScoreCard.Napoli)
-		}
-		// calculate primieraScore
-		scores[i].PrimieraScore = calculatePrimieraScore(scores[i].ScoreCard.PrimieraSuits)
-	}
-	// Primiera
-	if scores[0].PrimieraScore > scores[1].PrimieraScore {
-		scores[0].Score++
-	} else if scores[0].PrimieraScore < scores[1].PrimieraScore {
-		scores[1].Score++
-	}
-	return
-
 }
 
 // calculatePrimieraScore returns the value of the Primiera
@@ -648,6 +801,13 @@ func fillScoreCard(t *team.Team) (sc ScoreCard) {
 	}
 	sc.Napoli = napoli
 
+	// re bello
+	reBello := deck.Card{
+		Type: "King",
+		Suit: deck.Denari,
+	}
+	_, sc.ReBello = deck.Find(d, reBello)
+
 	return
 }
 
@@ -676,6 +836,10 @@ func cardsWithSuit(suit string, d []deck.Card) (cardsSuit []deck.Card) {
 // buildHandView returns the views of the hand for each player
 func buildHandView(hand *Hand, g *Game) map[string]HandPlayerView {
 	var handView = make(map[string]HandPlayerView)
+	playersCardsCount := make(map[string]int)
+	for _, p := range g.Players {
+		playersCardsCount[p.Name] = len(p.Cards)
+	}
 	for _, p := range g.Players {
 		pTeam, _ := teamOfPlayer(p.Name, g)
 		others := otherTeam(p.Name, g)
@@ -703,35 +867,75 @@ func buildHandView(hand *Hand, g *Game) map[string]HandPlayerView {
 		if hand.State == HandClosed {
 			hv.History = hand.History
 		}
+		if hand.State == HandActive && hand.CurrentPlayer.Name == p.Name {
+			hv.LegalMoves = legalMoves(p.Cards, hand.Table, g.Rules())
+		}
+		hv.Teams = buildTeamViews(hand, g)
+		hv.PlayerName = p.Name
+		hv.Variant = g.Rules().Variant()
+		hv.PlayersCardsCount = playersCardsCount
+		hv.CardsToDeal = cardsToDeal(hand)
 		handView[p.Name] = hv
 	}
 	return handView
 }
 
-// buildCurrentHandView returns the hand views for the current hand
-func buildCurrentHandView(g *Game) map[string]HandPlayerView {
+// cardsToDeal returns the number of cards of the deck of the hand still to be dealt - the hands stored before the
+// rounds of dealing were introduced have no dealt cards registered but all their cards were dealt at the beginning
+func cardsToDeal(hand *Hand) int {
+	if hand.DealtCards == 0 {
+		return 0
+	}
+	return len(hand.Deck) - hand.DealtCards
+}
+
+// buildTeamViews returns the views of all the teams of the game, in the order of the teams of the game
+func buildTeamViews(hand *Hand, g *Game) []TeamHandView {
+	teamViews := make([]TeamHandView, 0)
+	for _, t := range g.Teams {
+		tName := team.Name(t)
+		tv := TeamHandView{
+			Scope:            t.ScopeDiScopone,
+			Scorecard:        hand.Score[tName].ScoreCard,
+			CurrentGameScore: g.Score[tName],
+		}
+		for _, p := range t.Players {
+			tv.Players = append(tv.Players, p.Name)
+		}
+		if s, OK := hand.Score[tName]; OK {
+			tv.FinalHandScore = s.Score
+		}
+		teamViews = append(teamViews, tv)
+	}
+	return teamViews
+}
+
+// CurrentHandViews returns the views of the current hand of the game for each player, or nil if the game has no
+// hands - the caller has to hold the lock of the game or of the Osteria
+func (s *Scopone) CurrentHandViews(g *Game) map[string]HandPlayerView {
+	return s.currentHandViews(g)
+}
+
+// currentHandViews returns the hand views for the current hand with the time the current player has left to move
+func (s *Scopone) currentHandViews(g *Game) map[string]HandPlayerView {
 	cHand := currentHand(g)
 	if cHand == nil {
 		return nil
 	}
-	return buildHandView(currentHand(g), g)
+	return s.handViews(cHand, g)
 }
 
-// otherTeam returns the other team, i.e. the opposite team
+// otherTeam returns the other team, i.e. the opposite team - in games with more than 2 teams it returns the team
+// which plays after the team of the player
 func otherTeam(pName string, g *Game) (otherTeam *team.Team) {
-	firstTeam := g.Teams[0]
-	secondTeam := g.Teams[1]
-	isFirstTeam := false
-	for _, p := range firstTeam.Players {
-		if p.Name == pName {
-			isFirstTeam = true
+	for i, t := range g.Teams {
+		for _, p := range t.Players {
+			if p.Name == pName {
+				return g.Teams[(i+1)%len(g.Teams)]
+			}
 		}
 	}
-	if isFirstTeam {
-		return secondTeam
-	}
-	return firstTeam
-
+	return g.Teams[0]
 }
 
 // IsCurrentHandActive returns true if the current hand is active
@@ -1,7 +1,10 @@
 package scopone
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -10,44 +13,76 @@ import (
 	"go-scopone/src/game-logic/team"
 )
 
+// ctx is the context of the commands of the tests
+var ctx = context.Background()
+
 func newTestGameFactory(scopone *Scopone, gName string) *Game {
 	return newGame("Player_1", "Player_2", "Player_3", "Player_4", scopone, gName)
 }
 func newGame(p1 string, p2 string, p3 string, p4 string, scopone *Scopone, gName string) *Game {
-	g, _ := scopone.NewGame(gName)
-	scopone.PlayerEnters(p1)
-	scopone.PlayerEnters(p2)
-	scopone.PlayerEnters(p3)
-	scopone.PlayerEnters(p4)
-	err_ := scopone.AddPlayerToGame(p1, gName)
+	g, _ := scopone.NewGame(ctx, gName, GameOptions{})
+	scopone.PlayerEnters(ctx, p1, "")
+	scopone.PlayerEnters(ctx, p2, "")
+	scopone.PlayerEnters(ctx, p3, "")
+	scopone.PlayerEnters(ctx, p4, "")
+	err_ := scopone.AddPlayerToGame(ctx, p1, gName)
 	if err_ != nil {
 		panic(err_)
 	}
-	err_ = scopone.AddPlayerToGame(p2, gName)
+	err_ = scopone.AddPlayerToGame(ctx, p2, gName)
 	if err_ != nil {
 		panic(err_)
 	}
-	err_ = scopone.AddPlayerToGame(p3, gName)
+	err_ = scopone.AddPlayerToGame(ctx, p3, gName)
 	if err_ != nil {
 		panic(err_)
 	}
-	err_ = scopone.AddPlayerToGame(p4, gName)
+	err_ = scopone.AddPlayerToGame(ctx, p4, gName)
 	if err_ != nil {
 		panic(err_)
 	}
+	readyToStart(scopone, g)
 	return g
 }
 
+// readyToStart makes all the players of the game confirm to be ready, so that its first hand can be dealt
+func readyToStart(s *Scopone, g *Game) {
+	for pName := range g.Players {
+		if err := s.PlayerReady(ctx, pName, g.Name); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// arrangeCardsSecondPlayerTakes gives the cards of the current hand to the players so that, if each player always
+// plays his first card, the first player puts a card on the table and the second player takes it with a card of the
+// same value, then the third player puts a card on the table and the fourth player takes it and so on until the end
+// of the hand - this way the team which plays as second takes all the cards following the rules of the game
+func arrangeCardsSecondPlayerTakes(g *Game) {
+	hand := currentHand(g)
+	sequence := playersSequence(g)
+	p := hand.FirstPlayer
+	for _, suit := range deck.Suits {
+		cards := make([]deck.Card, 0)
+		for _, cType := range deck.Types {
+			cards = append(cards, deck.Card{Type: cType, Suit: suit})
+		}
+		p.Cards = cards
+		hand.History.PlayerDecks[p.Name] = cards
+		p = sequence[p.Name]
+	}
+}
+
 func TestPlayCard(t *testing.T) {
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	firstPlayerSecondTeam := "Player_3"
 	g := newGame("Player_1", "Player_2", firstPlayerSecondTeam, "Player_4", scopone, "TestPlayCard")
-	scopone.NewHand(g)
+	scopone.NewHand(ctx, g)
 	hand := currentHand(g)
 	player := currentPlayer(g)
 	card := g.Players[player.Name].Cards[1]
 	numberOfCards := len(player.Cards)
-	handView, _, _ := scopone.Play(player.Name, card, []deck.Card{})
+	handView, _, _ := scopone.Play(ctx, g, player.Name, card, []deck.Card{})
 	// the player has 1 card less
 	if len(player.Cards) != numberOfCards-1 {
 		t.Errorf("Number of cards is %v and not %v as expected", len(player.Cards), numberOfCards-1)
@@ -139,9 +174,9 @@ func TestPlayCard(t *testing.T) {
 
 // The first player plays a card and the second player has a card for Scopa
 func TestPlayCardWithScopa(t *testing.T) {
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", scopone, "TestPlayCardWithScopa")
-	scopone.NewHand(g)
+	scopone.NewHand(ctx, g)
 	hand := currentHand(g)
 	player1 := currentPlayer(g)
 	player2 := nextPlayer(g)
@@ -156,9 +191,9 @@ func TestPlayCardWithScopa(t *testing.T) {
 	player2.Cards[0] = card2
 
 	// player1 plays the card
-	scopone.Play(player1.Name, card1, []deck.Card{})
+	scopone.Play(ctx, g, player1.Name, card1, []deck.Card{})
 	// player2 palys a card to make Scopa on the card played by player1
-	handView, _, _ := scopone.Play(player2.Name, card2, []deck.Card{card1})
+	handView, _, _ := scopone.Play(ctx, g, player2.Name, card2, []deck.Card{card1})
 
 	// test that no card played is on the table
 	if len(hand.Table) != 0 {
@@ -219,12 +254,13 @@ func TestPlayCardWithScopa(t *testing.T) {
 }
 
 // All the cards are played - the second team takes all cards and the first team takes none
-// the rules of Scopone are not followed in this test, all cards on the table are just taken, regardless of the card played
+// the cards are arranged so that each card put on the table is taken by the next player with a card of the same value
 // this is just to test that the closure of the hand is reached and the calculations of the score triggered
 func TestPlayAllCards(t *testing.T) {
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", scopone, "TestPlayAllCards")
-	scopone.NewHand(g)
+	scopone.NewHand(ctx, g)
+	arrangeCardsSecondPlayerTakes(g)
 	hand := currentHand(g)
 	var cardOfLastPlayer deck.Card
 	var handView map[string]HandPlayerView
@@ -235,7 +271,7 @@ func TestPlayAllCards(t *testing.T) {
 		if len(hand.Table) > 0 {
 			cardsTaken = []deck.Card{cardOfLastPlayer}
 		}
-		handView, _, _ = scopone.Play(player.Name, c, cardsTaken)
+		handView, _, _ = scopone.Play(ctx, g, player.Name, c, cardsTaken)
 		cardOfLastPlayer = c
 	}
 
@@ -337,16 +373,17 @@ func TestPlayAllCards(t *testing.T) {
 }
 
 // Two hands where all the cards are won by the team which starts playing as second
-// the rules of Scopone are not followed in this test, all cards on the table are just taken, regardless of the card played
+// the cards are arranged so that each card put on the table is taken by the next player with a card of the same value
 // This is to test that the game keeps the right score for the teams
 // In the first hand, the second team wins all the cards because it is the second team to play the cards
 // In the second hand the opposite happens, the first team is the team playing second, and so it wins all the cards
 // The team which wins all the cards scores 24 point, made of 4 of Mazzo, 10 Napoli and 10 Scope
 func TestPlayAllCardsTwoHands(t *testing.T) {
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", scopone, "TestPlayAllCardsTwoHands")
 	// First Hand
-	scopone.NewHand(g)
+	scopone.NewHand(ctx, g)
+	arrangeCardsSecondPlayerTakes(g)
 	hand := currentHand(g)
 	var cardOfLastPlayer deck.Card
 	for range hand.Deck {
@@ -356,11 +393,12 @@ func TestPlayAllCardsTwoHands(t *testing.T) {
 		if len(hand.Table) > 0 {
 			cardsTaken = []deck.Card{cardOfLastPlayer}
 		}
-		scopone.Play(player.Name, c, cardsTaken)
+		scopone.Play(ctx, g, player.Name, c, cardsTaken)
 		cardOfLastPlayer = c
 	}
 	// Second Hand
-	scopone.NewHand(g)
+	scopone.NewHand(ctx, g)
+	arrangeCardsSecondPlayerTakes(g)
 	hand = currentHand(g)
 	for range hand.Deck {
 		player := currentPlayer(g)
@@ -369,7 +407,7 @@ func TestPlayAllCardsTwoHands(t *testing.T) {
 		if len(hand.Table) > 0 {
 			cardsTaken = []deck.Card{cardOfLastPlayer}
 		}
-		scopone.Play(player.Name, c, cardsTaken)
+		scopone.Play(ctx, g, player.Name, c, cardsTaken)
 		cardOfLastPlayer = c
 	}
 
@@ -394,9 +432,9 @@ func TestPlayAllCardsTwoHands(t *testing.T) {
 }
 
 func TestNewHand(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newTestGameFactory(s, "TestNewHand")
-	hand, handPlayersView, _ := s.NewHand(g)
+	hand, handPlayersView, _ := s.NewHand(ctx, g)
 	// test that a new Game has one Hand
 	if len(g.Hands) != 1 {
 		t.Errorf("The current game has %v hands and not 1", len(g.Hands))
@@ -493,9 +531,9 @@ func TestNewHand(t *testing.T) {
 }
 
 func TestCurrentHand(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newTestGameFactory(s, "TestCurrentHand")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	hand := currentHand(g)
 	// fmt.Printf("hand %v", hand)
 	// I test simply that there is a FirstPlayer defined to test that the current hand is not empty
@@ -506,9 +544,9 @@ func TestCurrentHand(t *testing.T) {
 }
 
 func TestCurrentPlayer(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newTestGameFactory(s, "TestCurrentPlayer")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	player := currentPlayer(g)
 	if player.Name == "" {
 		t.Errorf("The current player is not defined")
@@ -517,9 +555,9 @@ func TestCurrentPlayer(t *testing.T) {
 
 // Test which is the next player for a brand new game - this must be the first player of the second team
 func TestNextPlayerForNewGame(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newTestGameFactory(s, "TestNextPlayerForNewGame")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	player := nextPlayer(g)
 	if player.Name != g.Teams[1].Players[0].Name {
 		t.Errorf("For a new Game the next player should be the first player of the second team but is not")
@@ -527,7 +565,7 @@ func TestNextPlayerForNewGame(t *testing.T) {
 }
 
 func TestTeamOfPlayer(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	pName := "This_Player"
 	g := newGame(pName, "Player_2", "Player_3", "Player_4", s, "TestTeamOfPlayer")
 	playerTeam, e := teamOfPlayer(pName, g)
@@ -546,7 +584,7 @@ func TestTeamOfPlayer(t *testing.T) {
 }
 
 func TestOtherTeam(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	pName := "This_Player"
 	g := newGame(pName, "Player_2", "Player_3", "Player_4", s, "TestOtherTeam")
 	notThePlayerTeam := otherTeam(pName, g)
@@ -562,7 +600,7 @@ func TestOtherTeam(t *testing.T) {
 }
 
 func TestTeamForPlayerNotPresent(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	pName := "Player_not_Present"
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestTeamForPlayerNotPresent")
 	_, e := teamOfPlayer(pName, g)
@@ -572,9 +610,9 @@ func TestTeamForPlayerNotPresent(t *testing.T) {
 }
 
 func TestSortForPrimiera(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestSortForPrimiera")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	hand := currentHand(g)
 	// take all denari from the shuffled deck
 	var denari byPrimiera = cardsWithSuit(deck.Denari, hand.Deck)
@@ -602,9 +640,9 @@ func TestSortForPrimiera(t *testing.T) {
 
 // Test if sorting all suits works
 func TestSortForPrimieraTwoSuits(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestSortForPrimieraTwoSuits")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	hand := currentHand(g)
 	// take all suits from the shuffled deck
 	var denari byPrimiera
@@ -641,9 +679,9 @@ func TestSortForPrimieraTwoSuits(t *testing.T) {
 }
 
 func TestSortForNapoli(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestSortForNapoli")
-	s.NewHand(g)
+	s.NewHand(ctx, g)
 	hand := currentHand(g)
 	// take all denari from the shuffled deck
 	var denari byNapoli = cardsWithSuit(deck.Denari, hand.Deck)
@@ -718,9 +756,9 @@ func TestPrimieraScoreShuffle(t *testing.T) {
 
 // Test the primiera score with the entire deck
 func TestPrimieraScoreWholeDeck(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestPrimieraScoreWholeDeck")
-	hand, _, _ := s.NewHand(g)
+	hand, _, _ := s.NewHand(ctx, g)
 	d := hand.Deck
 
 	pSuits := primieraSuits(d)
@@ -757,9 +795,9 @@ func TestPrimieraScoreMissingSuit(t *testing.T) {
 }
 
 func TestCalculateScore(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestCalculateScore")
-	hand, _, _ := s.NewHand(g)
+	hand, _, _ := s.NewHand(ctx, g)
 
 	// Settebello is the only point scored by this team
 	takenCards1 := []deck.Card{
@@ -777,7 +815,7 @@ func TestCalculateScore(t *testing.T) {
 	g.Teams[0].TakenCards = takenCards1
 
 	// This teams scores Carte, Denari and Primiera
-	takenCards2 := deck.RemoveCards(hand.Deck, takenCards1)
+	takenCards2, _ := deck.RemoveCards(hand.Deck, takenCards1)
 	g.Teams[1].TakenCards = takenCards2
 
 	if len(takenCards1)+len(takenCards2) != 40 {
@@ -786,7 +824,7 @@ func TestCalculateScore(t *testing.T) {
 
 	var teams []*team.Team
 	teams = append(teams, g.Teams[0], g.Teams[1])
-	scores := calculateScore(teams)
+	scores := defaultRules().Score(teams)
 
 	if scores[0].Score != 1 {
 		t.Errorf("The score of first team should be 1 but is %v\n", scores[0].PrimieraScore)
@@ -804,9 +842,9 @@ func TestCalculateScore(t *testing.T) {
 }
 
 func TestCalculateScoreWithScope(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestCalculateScoreWithScope")
-	hand, _, _ := s.NewHand(g)
+	hand, _, _ := s.NewHand(ctx, g)
 
 	// Settebello and one Scopa is scored by this team
 	takenCards1 := []deck.Card{
@@ -825,7 +863,7 @@ func TestCalculateScoreWithScope(t *testing.T) {
 	g.Teams[0].ScopeDiScopone = []deck.Card{takenCards1[2]}
 
 	// This teams scores Carte, Denari and Primiera
-	takenCards2 := deck.RemoveCards(hand.Deck, takenCards1)
+	takenCards2, _ := deck.RemoveCards(hand.Deck, takenCards1)
 	g.Teams[1].TakenCards = takenCards2
 
 	if len(takenCards1)+len(takenCards2) != 40 {
@@ -834,7 +872,7 @@ func TestCalculateScoreWithScope(t *testing.T) {
 
 	var teams []*team.Team
 	teams = append(teams, g.Teams[0], g.Teams[1])
-	scores := calculateScore(teams)
+	scores := defaultRules().Score(teams)
 
 	if scores[0].Score != 2 {
 		t.Errorf("The score of first team should be 2 but is %v\n", scores[0])
@@ -846,9 +884,9 @@ func TestCalculateScoreWithScope(t *testing.T) {
 }
 
 func TestCalculateScoreWithScopeAndNapoli(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestCalculateScoreWithScopeAndNapoli")
-	hand, _, _ := s.NewHand(g)
+	hand, _, _ := s.NewHand(ctx, g)
 
 	// Settebello, one Scopa and 3 Napoli is scored by this team
 	takenCards1 := []deck.Card{
@@ -869,7 +907,7 @@ func TestCalculateScoreWithScopeAndNapoli(t *testing.T) {
 	g.Teams[0].ScopeDiScopone = []deck.Card{takenCards1[2]}
 
 	// This teams scores Carte and Primiera - Denari are five so no point is scored for it
-	takenCards2 := deck.RemoveCards(hand.Deck, takenCards1)
+	takenCards2, _ := deck.RemoveCards(hand.Deck, takenCards1)
 	g.Teams[1].TakenCards = takenCards2
 
 	if len(takenCards1)+len(takenCards2) != 40 {
@@ -878,7 +916,7 @@ func TestCalculateScoreWithScopeAndNapoli(t *testing.T) {
 
 	var teams []*team.Team
 	teams = append(teams, g.Teams[0], g.Teams[1])
-	scores := calculateScore(teams)
+	scores := defaultRules().Score(teams)
 
 	if scores[0].Score != 5 {
 		t.Errorf("The score of first team should be 5 but is %v\n", scores[0].Score)
@@ -890,9 +928,9 @@ func TestCalculateScoreWithScopeAndNapoli(t *testing.T) {
 }
 
 func TestCalculateScoreWithWholeNapoli(t *testing.T) {
-	s := New(&DoNothingStore{}, &DoNothingStore{})
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 	g := newGame("Player_1", "Player_2", "Player_3", "Player_4", s, "TestCalculateScoreWithWholeNapoli")
-	hand, _, _ := s.NewHand(g)
+	hand, _, _ := s.NewHand(ctx, g)
 
 	// no point is scored by this team
 	takenCards1 := []deck.Card{
@@ -906,7 +944,7 @@ func TestCalculateScoreWithWholeNapoli(t *testing.T) {
 	}
 
 	// This teams scores all points and 10 Napoli
-	takenCards2 := deck.RemoveCards(hand.Deck, takenCards1)
+	takenCards2, _ := deck.RemoveCards(hand.Deck, takenCards1)
 	g.Teams[1].TakenCards = takenCards2
 
 	if len(takenCards1)+len(takenCards2) != 40 {
@@ -915,7 +953,7 @@ func TestCalculateScoreWithWholeNapoli(t *testing.T) {
 
 	var teams []*team.Team
 	teams = append(teams, g.Teams[0], g.Teams[1])
-	scores := calculateScore(teams)
+	scores := defaultRules().Score(teams)
 
 	if scores[0].Score != 0 {
 		t.Errorf("The score of first team should be 0 but is %v\n", scores[0].Score)
@@ -928,40 +966,45 @@ func TestCalculateScoreWithWholeNapoli(t *testing.T) {
 
 func TestPlayerEnters(t *testing.T) {
 	playerName := "Biscazziere"
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 
 	// test that if we add a Player we do not get an error
-	hv, alreadyIn := scopone.PlayerEnters(playerName)
-	if alreadyIn {
-		t.Errorf("We can not add the player %v to the Osteria", playerName)
+	hv, err := scopone.PlayerEnters(ctx, playerName, "")
+	if err != nil {
+		t.Errorf("We can not add the player %v to the Osteria - error %v", playerName, err)
 	}
 	if hv != nil {
 		t.Errorf("We should not receive handViews but rather we are receiving %v", hv)
 	}
 
 	// test that if we add 2 times the same Player we get an error since the player is already in the Osteria
-	hv, alreadyIn = scopone.PlayerEnters(playerName)
-	if !alreadyIn {
-		t.Errorf("We should not let the player %v enter the Osteria since he is already in", playerName)
+	hv, err = scopone.PlayerEnters(ctx, playerName, "")
+	if !errors.Is(err, ErrPlayerAlreadyInOsteria) {
+		t.Errorf("We should not let the player %v enter the Osteria since he is already in - error is %v", playerName, err)
 	}
 	if hv != nil {
 		t.Errorf("We should not receive handViews but rather we are receiving %v", hv)
 	}
 
+	// test that a player with no name can not enter
+	_, err = scopone.PlayerEnters(ctx, "", "")
+	if !errors.Is(err, ErrEmptyPlayerName) {
+		t.Errorf("A player with no name should not enter the Osteria - error is %v", err)
+	}
 }
 
 func TestNewGame(t *testing.T) {
 	gameName := "A new game"
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 
 	// test that if we create a new game we get no error
-	_, err := scopone.NewGame(gameName)
+	_, err := scopone.NewGame(ctx, gameName, GameOptions{})
 	if err != nil {
 		t.Errorf("We should be able to create a new game with name %v but we get an error %v", gameName, err)
 	}
 
 	// test that if we try to create a game with the same name of an existing game we get an error
-	_, err = scopone.NewGame(gameName)
+	_, err = scopone.NewGame(ctx, gameName, GameOptions{})
 	if err == nil {
 		t.Errorf("We should not create the game with name %v since there is already one", gameName)
 	}
@@ -981,20 +1024,20 @@ func TestAddPlayersToGame(t *testing.T) {
 		playerName4,
 	}
 	gameName := "A new game where to add players"
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
-	_, err_ := scopone.NewGame(gameName)
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	_, err_ := scopone.NewGame(ctx, gameName, GameOptions{})
 	if err_ != nil {
 		panic(err_)
 	}
 	g := scopone.Games[gameName]
 	for _, name := range playerNames {
-		scopone.PlayerEnters(name)
-		e := scopone.AddPlayerToGame(name, gameName)
+		scopone.PlayerEnters(ctx, name, "")
+		e := scopone.AddPlayerToGame(ctx, name, gameName)
 		if e != nil {
 			t.Errorf("Player %v can not be added to the new game %v - error %v is returned", name, gameName, e)
 		}
 		// Test that we can NOT add add again the same player to the game
-		e = scopone.AddPlayerToGame(playerName1, gameName)
+		e = scopone.AddPlayerToGame(ctx, playerName1, gameName)
 		if e == nil {
 			t.Errorf("Player %v can not be added 2 times to the new game %v", playerName1, gameName)
 		}
@@ -1010,20 +1053,20 @@ func TestAddPlayersToGame(t *testing.T) {
 	}
 
 	// Test that we can not add the fifth player
-	scopone.PlayerEnters(playerName5)
-	e := scopone.AddPlayerToGame(playerName5, gameName)
+	scopone.PlayerEnters(ctx, playerName5, "")
+	e := scopone.AddPlayerToGame(ctx, playerName5, gameName)
 	if e == nil {
 		t.Errorf("Player %v should not be added to the new game %v since it has already 4 players", playerName5, gameName)
 	}
 
 	// Test that we can not add a player to a game that does not exist
-	e = scopone.AddPlayerToGame(playerName5, "a game that does not exist")
+	e = scopone.AddPlayerToGame(ctx, playerName5, "a game that does not exist")
 	if e == nil {
 		t.Errorf("Player %v should not be added to a game that does not exist", playerName5)
 	}
 
 	// Test that we can not add a player that does not exist
-	e = scopone.AddPlayerToGame("a player that does not exist", gameName)
+	e = scopone.AddPlayerToGame(ctx, "a player that does not exist", gameName)
 	if e == nil {
 		t.Errorf("Player that does not exist should not be added to the game %v", gameName)
 	}
@@ -1031,16 +1074,16 @@ func TestAddPlayersToGame(t *testing.T) {
 
 func TestAddPlayerToOsteriaAndThenRemove(t *testing.T) {
 	playerName := "Player who leaves"
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
 
-	scopone.PlayerEnters(playerName)
+	scopone.PlayerEnters(ctx, playerName, "")
 	scopone.RemovePlayer(playerName)
 
 	// test that if I add again the same player (i.e. the player comes back to the Osteria after he left)
 	// I receive no handViews and no error
-	hv, alreadyIn := scopone.PlayerEnters(playerName)
-	if alreadyIn {
-		t.Errorf("We can not add the player \"%v\" to the Osteria", playerName)
+	hv, err := scopone.PlayerEnters(ctx, playerName, "")
+	if err != nil {
+		t.Errorf("We can not add the player \"%v\" to the Osteria - error %v", playerName, err)
 	}
 	if hv != nil {
 		t.Errorf("We should not receive handViews but rather we are receiving %v", hv)
@@ -1057,13 +1100,13 @@ func TestAddPlayerToGameAndThenRomove(t *testing.T) {
 	playerName := "Player who enter the a game and then leaves"
 	gameName := "A new game where the player comes and leaves"
 
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
-	scopone.PlayerEnters(playerName)
-	_, err_ := scopone.NewGame(gameName)
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	scopone.PlayerEnters(ctx, playerName, "")
+	_, err_ := scopone.NewGame(ctx, gameName, GameOptions{})
 	if err_ != nil {
 		panic(err_)
 	}
-	err_ = scopone.AddPlayerToGame(playerName, gameName)
+	err_ = scopone.AddPlayerToGame(ctx, playerName, gameName)
 	if err_ != nil {
 		panic(err_)
 	}
@@ -1077,10 +1120,11 @@ func TestAddPlayerToGameAndThenRomove(t *testing.T) {
 		t.Errorf("Player %v should be in the PlayerLeftTheGame status but is in %v status", playerName, pStatus)
 	}
 
-	// Test that we can add again the player if he comes back and that he will be back in the game
-	hv, alreadyIn := scopone.PlayerEnters(playerName)
-	if alreadyIn {
-		t.Errorf("Could not add Player %v to the new game \"%v\" - because aready in", playerName, gameName)
+	// Test that we can add again the player if he comes back with the reconnect token and that he will be back in the game
+	token, _ := scopone.ReconnectToken(playerName)
+	hv, err := scopone.PlayerEnters(ctx, playerName, token)
+	if err != nil {
+		t.Errorf("Could not add Player %v to the new game \"%v\" - error %v", playerName, gameName, err)
 	}
 	if hv != nil {
 		t.Errorf("We should not receive handViews but rather we are receiving %v", hv)
@@ -1104,26 +1148,28 @@ func TestStartGameAndThenRemovePlayers(t *testing.T) {
 	}
 	gameName := "A new game where players come and go"
 
-	scopone := New(&DoNothingStore{}, &DoNothingStore{})
-	_, err_ := scopone.NewGame(gameName)
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	_, err_ := scopone.NewGame(ctx, gameName, GameOptions{})
 	if err_ != nil {
 		panic(err_)
 	}
 	for _, name := range playerNames {
-		scopone.PlayerEnters(name)
-		err_ := scopone.AddPlayerToGame(name, gameName)
+		scopone.PlayerEnters(ctx, name, "")
+		err_ := scopone.AddPlayerToGame(ctx, name, gameName)
 		if err_ != nil {
 			panic(err_)
 		}
 	}
 
 	g := scopone.Games[gameName]
-	scopone.NewHand(g)
+	readyToStart(scopone, g)
+	scopone.NewHand(ctx, g)
 
 	// test that the game is open
 	if g.State != GameOpen {
 		t.Errorf("Game \"%v\" should be open but is %v", gameName, g.State)
 	}
+	token3, _ := scopone.ReconnectToken(playerName3)
 	scopone.RemovePlayer(playerName3)
 
 	// test that the game is suspended
@@ -1131,31 +1177,185 @@ func TestStartGameAndThenRemovePlayers(t *testing.T) {
 		t.Errorf("Game \"%v\" should be suspended but is in state %v", gameName, g.State)
 	}
 
-	scopone.PlayerEnters(playerName3)
+	scopone.PlayerEnters(ctx, playerName3, token3)
 	// test that the game returns open
 	if g.State != GameOpen {
 		t.Errorf("Game \"%v\" should be open but is in state %v", gameName, g.State)
 	}
 
 	// test that after removing all players and adding back 1, the game is still suspended
+	tokens := make(map[string]string)
+	for _, name := range playerNames {
+		tokens[name], _ = scopone.ReconnectToken(name)
+	}
 	scopone.RemovePlayer(playerName1)
 	scopone.RemovePlayer(playerName2)
 	scopone.RemovePlayer(playerName3)
 	scopone.RemovePlayer(playerName4)
-	scopone.PlayerEnters(playerName2)
+	scopone.PlayerEnters(ctx, playerName2, tokens[playerName2])
 	if g.State != GameSuspended {
 		t.Errorf("Game \"%v\" should be suspended but is in state %v", gameName, g.State)
 	}
 	// add 2 more players and the game is still suspended
-	scopone.PlayerEnters(playerName3)
-	scopone.PlayerEnters(playerName4)
+	scopone.PlayerEnters(ctx, playerName3, tokens[playerName3])
+	scopone.PlayerEnters(ctx, playerName4, tokens[playerName4])
 	if g.State != GameSuspended {
 		t.Errorf("Game \"%v\" should be suspended but is in state %v", gameName, g.State)
 	}
 	// add the last one and the game is open again
-	scopone.PlayerEnters(playerName1)
+	scopone.PlayerEnters(ctx, playerName1, tokens[playerName1])
 	if g.State != GameOpen {
 		t.Errorf("Game \"%v\" should be open but is in state %v", gameName, g.State)
 	}
 
 }
+
+// The second team takes all the cards in the first hand and so reaches the target score and wins the game
+func TestGameFinishedWhenTargetScoreIsReached(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	gName := "TestGameFinishedWhenTargetScoreIsReached"
+	g, _ := scopone.NewGame(ctx, gName, GameOptions{TargetScore: 21})
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+		scopone.PlayerEnters(ctx, pName, "")
+		scopone.AddPlayerToGame(ctx, pName, gName)
+	}
+	if g.TargetScore != 21 {
+		t.Errorf("The target score should be 21 but is %v", g.TargetScore)
+	}
+	readyToStart(scopone, g)
+	scopone.NewHand(ctx, g)
+	arrangeCardsSecondPlayerTakes(g)
+	hand := currentHand(g)
+	var cardOfLastPlayer deck.Card
+	for range hand.Deck {
+		player := currentPlayer(g)
+		c := player.Cards[0]
+		var cardsTaken []deck.Card
+		if len(hand.Table) > 0 {
+			cardsTaken = []deck.Card{cardOfLastPlayer}
+		}
+		scopone.Play(ctx, g, player.Name, c, cardsTaken)
+		cardOfLastPlayer = c
+	}
+
+	if g.State != GameFinished {
+		t.Errorf("The game should be finished but is %v", g.State)
+	}
+	for _, p := range g.Teams[1].Players {
+		if !containsName(g.Winners, p.Name) {
+			t.Errorf("Player %v should be among the winners %v", p.Name, g.Winners)
+		}
+	}
+	// no more hands can be played
+	_, _, err := scopone.NewHand(ctx, g)
+	if !errors.Is(err, ErrGameFinished) {
+		t.Errorf("A new hand in a finished game should return ErrGameFinished but returns %v", err)
+	}
+}
+
+func TestWinnerJoinsNewGame(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newGameWithOptions(scopone, "finished", GameOptions{TargetScore: 21})
+	scopone.NewHand(ctx, g)
+	arrangeCardsSecondPlayerTakes(g)
+	hand := currentHand(g)
+	var cardOfLastPlayer deck.Card
+	for range hand.Deck {
+		player := currentPlayer(g)
+		c := player.Cards[0]
+		var cardsTaken []deck.Card
+		if len(hand.Table) > 0 {
+			cardsTaken = []deck.Card{cardOfLastPlayer}
+		}
+		scopone.Play(ctx, g, player.Name, c, cardsTaken)
+		cardOfLastPlayer = c
+	}
+	if g.State != GameFinished {
+		t.Fatalf("The game should be finished but is %v", g.State)
+	}
+	winner := g.Winners[0]
+	if _, playing := scopone.GameOfPlayer(winner); playing {
+		t.Errorf("The winner %v should not be playing the finished game any more", winner)
+	}
+
+	scopone.NewGame(ctx, "next", GameOptions{})
+	if err := scopone.AddPlayerToGame(ctx, winner, "next"); err != nil {
+		t.Fatalf("The winner %v should join a new game but gets error %v", winner, err)
+	}
+	next, playing := scopone.GameOfPlayer(winner)
+	if !playing || next.Name != "next" {
+		t.Errorf("The game of the winner %v should be the new game but is %v", winner, next)
+	}
+
+	// a player of the finished game who comes back to the Osteria does not resume the finished game
+	loser := g.Teams[0].Players[0].Name
+	scopone.RemovePlayer(loser)
+	hv, err := scopone.PlayerEnters(ctx, loser, "")
+	if err != nil || hv != nil {
+		t.Errorf("%v should come back to the Osteria without resuming the finished game - views %v error %v", loser, hv, err)
+	}
+	if status := scopone.Players[loser].Status; status != player.PlayerNotPlaying {
+		t.Errorf("%v should not be playing after coming back but is %v", loser, status)
+	}
+}
+
+func TestNewGameWithInvalidTargetScore(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	_, err := scopone.NewGame(ctx, "TestNewGameWithInvalidTargetScore", GameOptions{TargetScore: -1})
+	if !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A game with a negative target score should return ErrInvalidGameOptions but returns %v", err)
+	}
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNewHandWithArrangedDeck(t *testing.T) {
+	scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	arranged := deck.New()
+	scopone.Shuffler = deck.NewArrangedShuffler(arranged)
+	g := newTestGameFactory(scopone, "TestNewHandWithArrangedDeck")
+	scopone.NewHand(ctx, g)
+	// the first player gets the first 10 cards of the arranged deck
+	firstPlayer := currentHand(g).FirstPlayer
+	for i, c := range firstPlayer.Cards {
+		if c != arranged[i] {
+			t.Errorf("Card %v of the first player should be %v but is %v", i, arranged[i], c)
+		}
+	}
+}
+
+// Two games with the same seed have the same hands and the seed of each hand, stored in the history, re-deals the
+// same deck
+func TestNewHandWithGameSeed(t *testing.T) {
+	var decks [2][]deck.Card
+	for i := range decks {
+		scopone := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+		gName := "TestNewHandWithGameSeed"
+		g, _ := scopone.NewGame(ctx, gName, GameOptions{Seed: 123})
+		for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+			scopone.PlayerEnters(ctx, pName, "")
+			scopone.AddPlayerToGame(ctx, pName, gName)
+		}
+		readyToStart(scopone, g)
+		scopone.NewHand(ctx, g)
+		history := currentHand(g).History
+		decks[i] = history.Deck
+		reDealt := deck.ShuffleWithSeed(deck.New(), history.Seed)
+		if !reflect.DeepEqual(deck.Deck(history.Deck), reDealt) {
+			t.Errorf("The deck shuffled with the seed of the hand should be %v but is %v", history.Deck, reDealt)
+		}
+		if history.Seed == g.Seed {
+			t.Errorf("The seed of the hand should not be the seed of the game")
+		}
+	}
+	if !reflect.DeepEqual(decks[0], decks[1]) {
+		t.Errorf("Games with the same seed should have the same decks but have %v and %v", decks[0], decks[1])
+	}
+}
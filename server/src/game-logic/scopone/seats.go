@@ -0,0 +1,336 @@
+package scopone
+
+import (
+	"context"
+	"fmt"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+// AnySeat is the seat asked by a player who joins a game without choosing a seat, who takes the first seat free
+const AnySeat = -1
+
+// The seats of a game are numbered team after team, i.e. in a game of Scopone seats 0 and 1 are the seats of the
+// first team and seats 2 and 3 the seats of the second team, while in the games of Scopa each seat is a team
+// While the teams are forming, i.e. before the first hand is dealt, the players choose their seats, and so their
+// partners, when they join the game, can swap their seat with another one and can kick a player out of a seat to
+// make it free again - then each player confirms to be ready and the first hand can be dealt only when all the
+// players are ready, the bots being always ready
+// Any change of the seats makes the players confirm again, since the teams are no longer the ones they have confirmed
+
+// slot returns the team of a seat and the position of the seat in the team
+func (game *Game) slot(seat int) (*team.Team, int, error) {
+	if seat < 0 || seat >= game.seats() {
+		return nil, 0, fmt.Errorf("%w - Game %v has no seat %v", ErrInvalidSeat, game.Name, seat)
+	}
+	teamSize := len(game.Teams[0].Players)
+	return game.Teams[seat/teamSize], seat % teamSize, nil
+}
+
+// playersBySeat returns the players of the game in the order of their seats, with nil for the seats which are free
+func (game *Game) playersBySeat() []*player.Player {
+	players := make([]*player.Player, 0, game.seats())
+	for _, t := range game.Teams {
+		players = append(players, t.Players...)
+	}
+	return players
+}
+
+// Seats returns the names of the players of the game in the order of their seats, with an empty name for the seats
+// which are free
+func (game *Game) Seats() []string {
+	names := make([]string, 0, game.seats())
+	for _, p := range game.playersBySeat() {
+		name := ""
+		if p != nil {
+			name = p.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// TeamOf returns the index of the team of a player of the game and the names of the players of the team - the
+// second value returned is false if the player has no seat, or has left it
+func (game *Game) TeamOf(pName string) (int, []string, bool) {
+	seat, seated := game.SeatOf(pName)
+	if !seated || game.SeatsLeft[pName] {
+		return 0, nil, false
+	}
+	teamSize := len(game.Teams[0].Players)
+	teamIndex := seat / teamSize
+	names := make([]string, 0, teamSize)
+	for _, p := range game.Teams[teamIndex].Players {
+		if p != nil && !game.SeatsLeft[p.Name] {
+			names = append(names, p.Name)
+		}
+	}
+	return teamIndex, names, true
+}
+
+// SeatOf returns the seat of a player of the game - the second value returned is false if the player has no seat
+func (game *Game) SeatOf(pName string) (int, bool) {
+	for seat, p := range game.playersBySeat() {
+		if p != nil && p.Name == pName {
+			return seat, true
+		}
+	}
+	return 0, false
+}
+
+// CheckPlaying returns an error wrapping ErrPlayerNotPlaying unless the player is seated in the game and has not
+// left the seat - only the players of a game can start its hands or close it
+func (game *Game) CheckPlaying(pName string) error {
+	if _, seated := game.Players[pName]; !seated || game.SeatsLeft[pName] {
+		return fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, pName, game.Name)
+	}
+	return nil
+}
+
+// freeSeat returns the first seat free from seat 'from' included to seat 'to' excluded - the second value returned
+// is false if all those seats are taken
+func (game *Game) freeSeat(from int, to int) (int, bool) {
+	players := game.playersBySeat()
+	for seat := from; seat < to && seat < len(players); seat++ {
+		if players[seat] == nil {
+			return seat, true
+		}
+	}
+	return 0, false
+}
+
+// seatsFixed returns an error if the seats of the game can not be changed any more, i.e. if its first hand has
+// been dealt
+func (game *Game) seatsFixed() error {
+	if len(game.Hands) > 0 {
+		return fmt.Errorf("%w - The seats of game %v can not be changed after the first hand", ErrGameStarted, game.Name)
+	}
+	return nil
+}
+
+// allReady returns true if all the players of the game have confirmed to be ready
+func (game *Game) allReady() bool {
+	for pName, p := range game.Players {
+		if p.Bot == "" && !game.Ready[pName] {
+			return false
+		}
+	}
+	return true
+}
+
+// unready makes all the players of the game confirm again to be ready
+func (game *Game) unready() {
+	game.Ready = nil
+}
+
+// IsReady returns true if the player of the game has confirmed to be ready to start the game
+func (game *Game) IsReady(pName string) bool {
+	p, found := game.Players[pName]
+	return found && (p.Bot != "" || game.Ready[pName])
+}
+
+// FreeSeatOfTeam returns the first seat free of a team of the game, to be used by the players who want to join a
+// team without choosing the seat
+func FreeSeatOfTeam(g *Game, teamIndex int) (int, error) {
+	if teamIndex < 0 || teamIndex >= len(g.Teams) {
+		return 0, fmt.Errorf("%w - Game %v has no team %v", ErrInvalidSeat, g.Name, teamIndex)
+	}
+	teamSize := len(g.Teams[0].Players)
+	seat, free := g.freeSeat(teamIndex*teamSize, (teamIndex+1)*teamSize)
+	if !free {
+		return 0, fmt.Errorf("%w - Team %v of game %v has already all its players", ErrTeamFull, teamIndex, g.Name)
+	}
+	return seat, nil
+}
+
+// AddPlayerToSeat sends the request to the game to add one player in the seat chosen, who gets the reconnect
+// token of the seat - with AnySeat the player takes the first seat free, see AddPlayerToGame
+// If the seat chosen is the seat of a player who has left the Osteria for longer than the grace period, the player
+// takes that seat, see TakeSeat
+func (s *Scopone) AddPlayerToSeat(ctx context.Context, playerName string, gameName string, seat int) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	p, pfound := s.Players[playerName]
+	if !pfound {
+		return fmt.Errorf("%w - There is no Player with name %v", ErrPlayerNotFound, playerName)
+	}
+	err := s.addToSeat(g, p, seat)
+	if err != nil {
+		return err
+	}
+	s.issueReconnectToken(playerName)
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// SwapSeats moves a player of the game to another seat - if the seat is taken, its player takes the seat left
+// The seats can be swapped only before the first hand is dealt, and all the players have to confirm again to be
+// ready
+func (s *Scopone) SwapSeats(ctx context.Context, gameName string, playerName string, seat int) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	from, seated := g.SeatOf(playerName)
+	if !seated {
+		return fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, playerName, gameName)
+	}
+	if err := g.seatsFixed(); err != nil {
+		return err
+	}
+	toTeam, toSlot, err := g.slot(seat)
+	if err != nil {
+		return err
+	}
+	if from == seat {
+		return nil
+	}
+	fromTeam, fromSlot, _ := g.slot(from)
+	fromTeam.Players[fromSlot], toTeam.Players[toSlot] = toTeam.Players[toSlot], fromTeam.Players[fromSlot]
+	if fromTeam != toTeam {
+		// the players who change team do not get what their new team has said before
+		for _, p := range []*player.Player{fromTeam.Players[fromSlot], toTeam.Players[toSlot]} {
+			if p != nil {
+				g.seatedNow(p.Name)
+			}
+		}
+	}
+	g.unready()
+	if err := g.CalculateState(); err != nil {
+		return err
+	}
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// KickFromSeat makes the seat of the game free again, sending its player out of the game - a player kicked out
+// stays in the Osteria as a player not playing any game, while a bot kicked out leaves the Osteria
+// A seat can be made free by any player of the game, only before the first hand is dealt, and all the players
+// have to confirm again to be ready
+// The name of the player kicked out is returned
+func (s *Scopone) KickFromSeat(ctx context.Context, gameName string, kickedBy string, seat int) (string, error) {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return "", fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	if _, found := g.Players[kickedBy]; !found {
+		return "", fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, kickedBy, gameName)
+	}
+	if err := g.seatsFixed(); err != nil {
+		return "", err
+	}
+	t, slot, err := g.slot(seat)
+	if err != nil {
+		return "", err
+	}
+	kicked := t.Players[slot]
+	if kicked == nil {
+		return "", fmt.Errorf("%w - Seat %v of game %v is already free", ErrInvalidSeat, seat, gameName)
+	}
+	s.removeFromSeat(g, kicked)
+	g.unready()
+	if err := g.CalculateState(); err != nil {
+		return "", err
+	}
+	return kicked.Name, storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// removeFromSeat sends the player out of the game
+func (s *Scopone) removeFromSeat(g *Game, p *player.Player) {
+	for _, t := range g.Teams {
+		for i, tp := range t.Players {
+			if tp == p {
+				t.Players[i] = nil
+			}
+		}
+	}
+	delete(g.Players, p.Name)
+	delete(g.Ready, p.Name)
+	delete(g.Seated, p.Name)
+	delete(s.seatTokens, p.Name)
+	if p.Bot != "" {
+		delete(s.Players, p.Name)
+		delete(s.Bots, p.Name)
+		return
+	}
+	if p.Status != player.PlayerLeftOsteria {
+		p.Status = player.PlayerNotPlaying
+	}
+}
+
+// PlayerReady records that the player confirms to be ready to start the game with the teams as they are
+// When all the players are ready the game is open and its first hand can be dealt
+func (s *Scopone) PlayerReady(ctx context.Context, playerName string, gameName string) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	if _, found := g.Players[playerName]; !found {
+		return fmt.Errorf("%w - Player %v is not playing game %v", ErrPlayerNotPlaying, playerName, gameName)
+	}
+	if err := g.seatsFixed(); err != nil {
+		return err
+	}
+	if g.Ready == nil {
+		g.Ready = make(map[string]bool)
+	}
+	g.Ready[playerName] = true
+	if err := g.CalculateState(); err != nil {
+		return err
+	}
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
+
+// ArrangeSeats puts the players of the game in the seats given, in the order of the seats and with an empty name
+// for the seats which are free - the players of the game not in the seats are sent out of the game as if they were
+// kicked out, and all the players have to confirm again to be ready
+// It is used to rebuild the games read from a store, while the players change their seats with SwapSeats and
+// KickFromSeat
+func (s *Scopone) ArrangeSeats(ctx context.Context, gameName string, seats []string) error {
+	g, gfound := s.Games[gameName]
+	if !gfound {
+		return fmt.Errorf("%w - There is no Game with name %v", ErrGameNotFound, gameName)
+	}
+	if err := g.seatsFixed(); err != nil {
+		return err
+	}
+	if len(seats) > g.seats() {
+		return fmt.Errorf("%w - Game %v has only %v seats", ErrInvalidSeat, gameName, g.seats())
+	}
+	seated := make(map[string]bool)
+	for _, pName := range seats {
+		if pName == "" {
+			continue
+		}
+		if _, found := g.Players[pName]; !found || seated[pName] {
+			return fmt.Errorf("%w - Player %v can not be seated in game %v", ErrPlayerNotPlaying, pName, gameName)
+		}
+		seated[pName] = true
+	}
+	for _, p := range g.playersBySeat() {
+		if p != nil && !seated[p.Name] {
+			s.removeFromSeat(g, p)
+		}
+	}
+	players := g.Players
+	for _, t := range g.Teams {
+		for i := range t.Players {
+			t.Players[i] = nil
+		}
+	}
+	g.Players = make(map[string]*player.Player)
+	for seat, pName := range seats {
+		if pName == "" {
+			continue
+		}
+		if err := g.sit(players[pName], seat); err != nil {
+			return err
+		}
+	}
+	g.unready()
+	if err := g.CalculateState(); err != nil {
+		return err
+	}
+	return storeError(s.GameStore.WriteGame(ctx, g))
+}
@@ -0,0 +1,131 @@
+package scopone
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go-scopone/src/game-logic/player"
+)
+
+// formingGame returns an Osteria with a game whose teams are forming and with the players passed in in the Osteria
+func formingGame(t *testing.T, pNames ...string) (*Scopone, *Game) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g, err := s.NewGame(ctx, "game", GameOptions{})
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for _, pName := range pNames {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	return s, g
+}
+
+func TestAddPlayerToSeat(t *testing.T) {
+	s, g := formingGame(t, "Player_1", "Player_2", "Player_3")
+	if err := s.AddPlayerToSeat(ctx, "Player_1", "game", 2); err != nil {
+		t.Fatalf("Player_1 could not sit in seat 2: %v", err)
+	}
+	if err := s.AddPlayerToSeat(ctx, "Player_2", "game", 2); !errors.Is(err, ErrSeatTaken) {
+		t.Errorf("Sitting in a seat taken should return ErrSeatTaken but returns %v", err)
+	}
+	if err := s.AddPlayerToSeat(ctx, "Player_2", "game", 4); !errors.Is(err, ErrInvalidSeat) {
+		t.Errorf("Sitting in a seat the game does not have should return ErrInvalidSeat but returns %v", err)
+	}
+	seat, err := FreeSeatOfTeam(g, 1)
+	if err != nil || seat != 3 {
+		t.Fatalf("The seat free of the second team should be 3 but is %v with error %v", seat, err)
+	}
+	s.AddPlayerToSeat(ctx, "Player_2", "game", seat)
+	if _, err := FreeSeatOfTeam(g, 1); !errors.Is(err, ErrTeamFull) {
+		t.Errorf("A team with all its players should return ErrTeamFull but returns %v", err)
+	}
+	// the player who does not choose takes the first seat free
+	s.AddPlayerToGame(ctx, "Player_3", "game")
+	if seats := g.Seats(); !reflect.DeepEqual(seats, []string{"Player_3", "", "Player_1", "Player_2"}) {
+		t.Errorf("The seats should be Player_3, free, Player_1 and Player_2 but are %v", seats)
+	}
+}
+
+func TestSwapAndKick(t *testing.T) {
+	s, g := formingGame(t, "Player_1", "Player_2", "Player_3")
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3"} {
+		s.AddPlayerToGame(ctx, pName, "game")
+		s.PlayerReady(ctx, pName, "game")
+	}
+	botName, _ := s.AddBotToGame(ctx, "game", &firstMoveBot{})
+	if g.State != GameOpen {
+		t.Fatalf("The game with all the players ready should be open but is %v", g.State)
+	}
+
+	// Player_1 moves to the seat of Player_3, who takes the seat of Player_1, and the players are no longer ready
+	if err := s.SwapSeats(ctx, "game", "Player_1", 2); err != nil {
+		t.Fatalf("Player_1 could not move to seat 2: %v", err)
+	}
+	if seats := g.Seats(); !reflect.DeepEqual(seats, []string{"Player_3", "Player_2", "Player_1", botName}) {
+		t.Errorf("The seats should be Player_3, Player_2, Player_1 and the bot but are %v", seats)
+	}
+	if g.State != TeamsForming || g.IsReady("Player_2") || !g.IsReady(botName) {
+		t.Errorf("After the swap the teams should be forming with only the bot ready but the game is %v", g.State)
+	}
+
+	// the bot kicked out leaves the Osteria while a player kicked out stays in it
+	kicked, err := s.KickFromSeat(ctx, "game", "Player_1", 3)
+	if err != nil || kicked != botName {
+		t.Fatalf("The bot should be kicked out of seat 3 but %v is kicked out with error %v", kicked, err)
+	}
+	if _, found := s.Players[botName]; found {
+		t.Errorf("The bot kicked out should leave the Osteria")
+	}
+	s.KickFromSeat(ctx, "game", "Player_1", 1)
+	if p := s.Players["Player_2"]; p.Status != player.PlayerNotPlaying {
+		t.Errorf("Player_2 kicked out should not be playing but has status %v", p.Status)
+	}
+	if _, err := s.KickFromSeat(ctx, "game", "Player_1", 1); !errors.Is(err, ErrInvalidSeat) {
+		t.Errorf("Kicking out a seat free should return ErrInvalidSeat but returns %v", err)
+	}
+	if _, err := s.KickFromSeat(ctx, "game", "Player_2", 0); !errors.Is(err, ErrPlayerNotPlaying) {
+		t.Errorf("A player not in the game kicking out should return ErrPlayerNotPlaying but returns %v", err)
+	}
+}
+
+func TestFirstHandWaitsForPlayersReady(t *testing.T) {
+	s, g := formingGame(t, "Player_1", "Player_2", "Player_3", "Player_4")
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+		s.AddPlayerToGame(ctx, pName, "game")
+	}
+	s.PlayerReady(ctx, "Player_1", "game")
+	if _, _, err := s.NewHand(ctx, g); !errors.Is(err, ErrPlayersNotReady) {
+		t.Errorf("The first hand should not start before all the players are ready but the error is %v", err)
+	}
+	readyToStart(s, g)
+	if _, _, err := s.NewHand(ctx, g); err != nil {
+		t.Fatalf("The first hand should start when all the players are ready but the error is %v", err)
+	}
+	if err := s.SwapSeats(ctx, "game", "Player_1", 1); !errors.Is(err, ErrGameStarted) {
+		t.Errorf("Swapping seats after the first hand should return ErrGameStarted but returns %v", err)
+	}
+	if _, err := s.KickFromSeat(ctx, "game", "Player_1", 1); !errors.Is(err, ErrGameStarted) {
+		t.Errorf("Kicking out a player after the first hand should return ErrGameStarted but returns %v", err)
+	}
+}
+
+func TestArrangeSeats(t *testing.T) {
+	s, g := formingGame(t, "Player_1", "Player_2", "Player_3")
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3"} {
+		s.AddPlayerToGame(ctx, pName, "game")
+		s.PlayerReady(ctx, pName, "game")
+	}
+	if err := s.ArrangeSeats(ctx, "game", []string{"", "Player_3", "", "Player_1"}); err != nil {
+		t.Fatalf("The seats could not be arranged: %v", err)
+	}
+	if seats := g.Seats(); !reflect.DeepEqual(seats, []string{"", "Player_3", "", "Player_1"}) {
+		t.Errorf("The seats should be arranged as asked but are %v", seats)
+	}
+	if _, found := g.Players["Player_2"]; found || g.IsReady("Player_1") {
+		t.Errorf("Player_2 should be out of the game and the players no longer ready")
+	}
+	if err := s.ArrangeSeats(ctx, "game", []string{"Player_2"}); !errors.Is(err, ErrPlayerNotPlaying) {
+		t.Errorf("Arranging a player not in the game should return ErrPlayerNotPlaying but returns %v", err)
+	}
+}
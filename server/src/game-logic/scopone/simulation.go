@@ -0,0 +1,134 @@
+package scopone
+
+import (
+	"fmt"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/team"
+)
+
+// Simulation is a hand played outside of the Osteria, starting from what a player sees of a real hand and from a
+// guess of the cards the player does not see - it is used by the bots to explore the consequences of their moves
+// and it is played with the same rules, and scored in the same way, as the real hands
+type Simulation struct {
+	game *Game
+}
+
+// NewSimulation creates a simulation from the view of a hand, which must contain the public history of the hand,
+// the cards guessed for the other players and the cards guessed to be still dealt, in the order they are dealt
+func NewSimulation(view HandPlayerView, otherPlayersCards map[string][]deck.Card, cardsToDeal []deck.Card) (*Simulation, error) {
+	rules, err := RulesFor(view.Variant)
+	if err != nil {
+		return nil, err
+	}
+	g := &Game{
+		Name:    view.GameName,
+		Players: make(map[string]*player.Player),
+		Score:   make(map[string]int),
+		rules:   rules,
+	}
+	for _, tv := range view.Teams {
+		t := team.NewWithSize(len(tv.Players))
+		for i, pName := range tv.Players {
+			p := player.New(pName)
+			p.Status = player.PlayerPlaying
+			p.Cards = copyCards(otherPlayersCards[pName])
+			if pName == view.PlayerName {
+				p.Cards = copyCards(view.PlayerCards)
+			}
+			t.Players[i] = p
+			g.Players[pName] = p
+		}
+		t.ScopeDiScopone = copyCards(tv.Scope)
+		g.Teams = append(g.Teams, t)
+	}
+	g.NumberOfPlayers = len(g.Players)
+	firstPlayer, fFound := g.Players[view.FirstPlayerName]
+	currentPlayer, cFound := g.Players[view.CurrentPlayerName]
+	if !fFound || !cFound {
+		return nil, fmt.Errorf("%w - The first player %v or the current player %v are not in the teams of the view",
+			ErrPlayerNotFound, view.FirstPlayerName, view.CurrentPlayerName)
+	}
+	hand := &Hand{
+		Deck:          copyCards(cardsToDeal),
+		State:         HandActive,
+		FirstPlayer:   firstPlayer,
+		CurrentPlayer: currentPlayer,
+		Table:         copyCards(view.Table),
+		Score:         make(map[string]TeamScore),
+	}
+	// the cards taken so far by the teams are the ones registered in the history
+	for _, cardPlay := range view.History.CardPlaySequence {
+		hand.History.CardPlaySequence = append(hand.History.CardPlaySequence, cardPlay)
+		if len(cardPlay.CardsTaken) == 0 {
+			continue
+		}
+		t, err := teamOfPlayer(cardPlay.Player, g)
+		if err != nil {
+			return nil, fmt.Errorf("%w - %v", ErrPlayerNotFound, err)
+		}
+		t.TakenCards = append(t.TakenCards, cardPlay.CardsTaken...)
+		t.TakenCards = append(t.TakenCards, cardPlay.CardPlayed)
+	}
+	g.Hands = []*Hand{hand}
+	return &Simulation{game: g}, nil
+}
+
+// copyCards returns a copy of the cards so that the simulation does not change the cards it has been created with
+func copyCards(cards []deck.Card) []deck.Card {
+	copied := make([]deck.Card, len(cards))
+	copy(copied, cards)
+	return copied
+}
+
+// CurrentPlayer returns the name of the player who has to play
+func (sim *Simulation) CurrentPlayer() string {
+	return currentPlayer(sim.game).Name
+}
+
+// Table returns the cards on the table
+func (sim *Simulation) Table() []deck.Card {
+	return currentHand(sim.game).Table
+}
+
+// LegalMoves returns the legal moves of the player who has to play
+func (sim *Simulation) LegalMoves() []Move {
+	return sim.game.LegalMoves()
+}
+
+// IsOver returns true if all the cards of the hand have been played
+func (sim *Simulation) IsOver() bool {
+	return !IsCurrentHandActive(sim.game)
+}
+
+// Play plays a move of the player who has to play - an error is returned if the move is not legal
+func (sim *Simulation) Play(move Move) error {
+	if sim.IsOver() {
+		return fmt.Errorf("%w - The simulated hand is over", ErrGameFinished)
+	}
+	_, handOver, err := playCard(sim.game, currentPlayer(sim.game), move.CardPlayed, move.CardsTaken)
+	if err != nil {
+		return err
+	}
+	if handOver {
+		scoreCurrentHand(sim.game)
+	}
+	return nil
+}
+
+// HandViews returns the views of the simulated hand of all the players, the same views the players of a real hand
+// get after each card played - when the hand is over they contain the final scores of the hand
+func (sim *Simulation) HandViews() map[string]HandPlayerView {
+	return buildHandView(currentHand(sim.game), sim.game)
+}
+
+// Scores returns the scores the teams would get if the hand ended now, in the order of the teams of the view the
+// simulation has been created with - at the end of the hand they are the final scores of the hand
+func (sim *Simulation) Scores() []int {
+	scores := make([]int, 0)
+	for _, s := range sim.game.Rules().Score(sim.game.Teams) {
+		scores = append(scores, s.Score)
+	}
+	return scores
+}
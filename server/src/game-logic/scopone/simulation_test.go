@@ -0,0 +1,71 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/deck"
+)
+
+// A simulation created with the real cards of the other players plays the hand as the real game would
+func TestSimulationWithRealCards(t *testing.T) {
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	g := newTestGameFactory(s, "TestSimulationWithRealCards")
+	s.NewHand(ctx, g)
+	for i := 0; i < 3; i++ {
+		moves := g.LegalMoves()
+		s.Play(ctx, g, currentPlayer(g).Name, moves[0].CardPlayed, moves[0].CardsTaken)
+	}
+	hand := currentHand(g)
+	view := buildHandView(hand, g)[currentPlayer(g).Name]
+	view.History = publicHistory(hand.History)
+	otherPlayersCards := make(map[string][]deck.Card)
+	for _, p := range g.Players {
+		otherPlayersCards[p.Name] = p.Cards
+	}
+	sim, err := NewSimulation(view, otherPlayersCards, []deck.Card{})
+	if err != nil {
+		t.Fatalf("The simulation could not be created: %v", err)
+	}
+
+	for !sim.IsOver() {
+		if sim.CurrentPlayer() != currentPlayer(g).Name {
+			t.Fatalf("The current player of the simulation should be %v but is %v", currentPlayer(g).Name, sim.CurrentPlayer())
+		}
+		move := sim.LegalMoves()[0]
+		if err := sim.Play(move); err != nil {
+			t.Fatalf("The legal move %v returns an error %v", move, err)
+		}
+		s.Play(ctx, g, currentPlayer(g).Name, move.CardPlayed, move.CardsTaken)
+	}
+
+	if hand.State != HandClosed {
+		t.Errorf("The real hand should be closed as the simulated one but is %v", hand.State)
+	}
+	for i, score := range sim.Scores() {
+		realScore := hand.Score[teamNameOfView(view, i)].Score
+		if score != realScore {
+			t.Errorf("The simulated score of team %v should be %v but is %v", i, realScore, score)
+		}
+	}
+	// no more moves can be played when the hand is over
+	if err := sim.Play(Move{}); !errors.Is(err, ErrGameFinished) {
+		t.Errorf("Playing in a simulation which is over should return ErrGameFinished but returns %v", err)
+	}
+}
+
+func TestSimulationWithInvalidView(t *testing.T) {
+	view := HandPlayerView{FirstPlayerName: "Player_1", CurrentPlayerName: "Player_1"}
+	if _, err := NewSimulation(view, nil, nil); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("A simulation from a view with no teams should return ErrPlayerNotFound but returns %v", err)
+	}
+}
+
+// teamNameOfView returns the name of a team of the view
+func teamNameOfView(view HandPlayerView, i int) string {
+	name := view.Teams[i].Players[0]
+	for _, pName := range view.Teams[i].Players[1:] {
+		name = name + "_" + pName
+	}
+	return name
+}
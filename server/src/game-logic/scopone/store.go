@@ -1,20 +1,29 @@
 package scopone
 
-import "go-scopone/src/game-logic/player"
+import (
+	"context"
+
+	"go-scopone/src/game-logic/player"
+)
+
+// The methods of the stores take the context of the command which reads or writes, so that a store can give up
+// when the command is cancelled or its deadline expires
+// A store which can not be reached returns an error wrapping ErrStoreUnavailable, see Scopone for how the Osteria
+// degrades in this case
 
 // PlayerWriter adds, updates, deletes a Player in the sotre
 type PlayerWriter interface {
-	AddPlayerEntry(player *player.Player) error
+	AddPlayerEntry(ctx context.Context, player *player.Player) error
 }
 
 // GameWriter saves a game in the store
 type GameWriter interface {
-	WriteGame(game *Game) error
+	WriteGame(ctx context.Context, game *Game) error
 }
 
 // GameReader reads the games from the store
 type GameReader interface {
-	ReadOpenGames() (map[string]*Game, map[string]*player.Player, error)
+	ReadOpenGames(ctx context.Context) (map[string]*Game, map[string]*player.Player, error)
 }
 
 // GameReadWriter reads and writes the games with mongo
@@ -30,17 +39,17 @@ type DoNothingStore struct {
 }
 
 // AddPlayerEntry does nothing
-func (store *DoNothingStore) AddPlayerEntry(player *player.Player) error {
+func (store *DoNothingStore) AddPlayerEntry(ctx context.Context, player *player.Player) error {
 	return nil
 }
 
 // WriteGame does nothing
-func (store *DoNothingStore) WriteGame(game *Game) error {
+func (store *DoNothingStore) WriteGame(ctx context.Context, game *Game) error {
 	return nil
 }
 
 // ReadOpenGames does nothing
-func (store *DoNothingStore) ReadOpenGames() (games map[string]*Game, players map[string]*player.Player, err error) {
+func (store *DoNothingStore) ReadOpenGames(ctx context.Context) (games map[string]*Game, players map[string]*player.Player, err error) {
 	games = make(map[string]*Game)
 	players = make(map[string]*player.Player)
 	return
@@ -0,0 +1,96 @@
+package scopone
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TurnWarningBefore is how long before the end of the turn the current player is warned that the time to move is
+// running out - in games whose time limit is shorter than twice this time the player is warned half way
+const TurnWarningBefore = 10 * time.Second
+
+// The games created with a MoveTimeLimit give each player that time to play a card: the turn starts when the hand
+// is dealt or the previous card is played, and when it expires a legal move is played for the current player, chosen
+// by the TimeoutStrategy of the Osteria, so that a player who walks away does not leave the others stuck
+// The Osteria only tells when the turn expires and plays the move when asked to, while the timers which ask for it
+// are kept by the servers, see actor.TurnTimer
+// The turns of the hands read from a store which does not keep the start of the turn start again when the Osteria
+// starts
+
+// moveTimeLimit returns the time each player has to play a card in the game, 0 if there is no limit
+func (game *Game) moveTimeLimit() time.Duration {
+	return time.Duration(game.MoveTimeLimit) * time.Second
+}
+
+// startTurn starts the turn of the current player of the hand
+func (s *Scopone) startTurn(hand *Hand) {
+	hand.TurnStarted = s.clock()
+}
+
+// restartTurnsOfGamesRead starts the turns of the active hands read from the store without the start of the turn
+func (s *Scopone) restartTurnsOfGamesRead() {
+	for _, g := range s.Games {
+		if hand := currentHand(g); hand != nil && hand.State == HandActive && hand.TurnStarted.IsZero() {
+			s.startTurn(hand)
+		}
+	}
+}
+
+// TurnTimer returns the current player of the game together with when the player is warned that the time to move is
+// running out and when the turn expires - the last value returned is false if the turn has no time limit, i.e. if
+// the game has no MoveTimeLimit or no hand is active, or the hand can not go on since the game has been abandoned or
+// the current player has left the seat
+func (s *Scopone) TurnTimer(g *Game) (pName string, warning time.Time, deadline time.Time, running bool) {
+	limit := g.moveTimeLimit()
+	if limit == 0 || !IsCurrentHandActive(g) || g.handStopped() {
+		return "", time.Time{}, time.Time{}, false
+	}
+	hand := currentHand(g)
+	if hand.TurnStarted.IsZero() {
+		s.startTurn(hand)
+	}
+	deadline = hand.TurnStarted.Add(limit)
+	before := TurnWarningBefore
+	if limit < 2*before {
+		before = limit / 2
+	}
+	return hand.CurrentPlayer.Name, deadline.Add(-before), deadline, true
+}
+
+// PlayTimedOutTurn plays for the current player of the game, whose turn has expired, the move chosen by the
+// TimeoutStrategy of the Osteria or, if there is none, the first legal move
+// If the turn has not expired, or has no time limit, an error wrapping ErrTurnNotExpired is returned
+func (s *Scopone) PlayTimedOutTurn(ctx context.Context, g *Game) (pName string, move Move,
+	handViews map[string]HandPlayerView, finalTableTake FinalTableTake, err error) {
+	pName, _, deadline, running := s.TurnTimer(g)
+	if !running || s.clock().Before(deadline) {
+		err = fmt.Errorf("%w - The turn of the current player of game %v has not expired", ErrTurnNotExpired, g.Name)
+		return
+	}
+	hand := currentHand(g)
+	view := buildHandView(hand, g)[pName]
+	view.History = publicHistory(hand.History)
+	move = view.LegalMoves[0]
+	if s.TimeoutStrategy != nil {
+		move = s.TimeoutStrategy.ChooseMove(view)
+	}
+	handViews, finalTableTake, err = s.Play(ctx, g, pName, move.CardPlayed, move.CardsTaken)
+	return
+}
+
+// handViews returns the views of the hand for each player with the time the current player has left to move
+func (s *Scopone) handViews(hand *Hand, g *Game) map[string]HandPlayerView {
+	views := buildHandView(hand, g)
+	if _, _, deadline, running := s.TurnTimer(g); running && hand == currentHand(g) {
+		remaining := deadline.Sub(s.clock())
+		if remaining < 0 {
+			remaining = 0
+		}
+		for pName, view := range views {
+			view.RemainingTime = remaining.Milliseconds()
+			views[pName] = view
+		}
+	}
+	return views
+}
@@ -0,0 +1,87 @@
+package scopone
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// timedGame returns an Osteria with a game of 4 players with a move time limit whose first hand has started,
+// together with the time, which the tests can move on to let the turn expire
+func timedGame(t *testing.T, moveTimeLimit int) (*Scopone, *Game, *time.Time) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(ctx, &DoNothingStore{}, &DoNothingStore{})
+	s.now = func() time.Time { return now }
+	g, err := s.NewGame(ctx, "game", GameOptions{MoveTimeLimit: moveTimeLimit})
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for _, pName := range []string{"Player_1", "Player_2", "Player_3", "Player_4"} {
+		s.PlayerEnters(ctx, pName, "")
+		s.AddPlayerToGame(ctx, pName, "game")
+	}
+	readyToStart(s, g)
+	if _, _, err := s.NewHand(ctx, g); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	return s, g, &now
+}
+
+func TestTurnTimer(t *testing.T) {
+	s, g, now := timedGame(t, 30)
+	start := *now
+	pName, warning, deadline, running := s.TurnTimer(g)
+	if !running || pName != "Player_1" || !warning.Equal(start.Add(20*time.Second)) ||
+		!deadline.Equal(start.Add(30*time.Second)) {
+		t.Errorf("The turn of Player_1 should be warned after 20s and expire after 30s but the timer is %v %v %v %v",
+			pName, warning, deadline, running)
+	}
+	*now = now.Add(10 * time.Second)
+	if v := s.CurrentHandViews(g)["Player_2"]; v.RemainingTime != 20000 {
+		t.Errorf("The players should see that 20s are left to move but see %vms", v.RemainingTime)
+	}
+
+	// a card played starts the turn of the next player
+	move := s.CurrentHandViews(g)["Player_1"].LegalMoves[0]
+	if _, _, err := s.Play(ctx, g, "Player_1", move.CardPlayed, move.CardsTaken); err != nil {
+		t.Fatalf("The card could not be played: %v", err)
+	}
+	next := currentPlayer(g).Name
+	if pName, _, deadline, _ := s.TurnTimer(g); pName != next || !deadline.Equal(now.Add(30*time.Second)) {
+		t.Errorf("The turn of %v should expire 30s after the card played but is %v %v", next, pName, deadline)
+	}
+
+	// the games with no time limit have no timer
+	untimed, _ := s.NewGame(ctx, "untimed", GameOptions{})
+	if _, _, _, running := s.TurnTimer(untimed); running {
+		t.Errorf("A game without move time limit should have no timer")
+	}
+	if _, err := s.NewGame(ctx, "negative", GameOptions{MoveTimeLimit: -1}); !errors.Is(err, ErrInvalidGameOptions) {
+		t.Errorf("A negative move time limit should return ErrInvalidGameOptions but returns %v", err)
+	}
+}
+
+func TestPlayTimedOutTurn(t *testing.T) {
+	s, g, now := timedGame(t, 15)
+	// the limit is shorter than twice the warning, so the player is warned half way
+	if _, warning, _, _ := s.TurnTimer(g); !warning.Equal(now.Add(7500 * time.Millisecond)) {
+		t.Errorf("Player_1 should be warned after 7.5s but is warned at %v", warning)
+	}
+	if _, _, _, _, err := s.PlayTimedOutTurn(ctx, g); !errors.Is(err, ErrTurnNotExpired) {
+		t.Errorf("A turn not expired should return ErrTurnNotExpired but returns %v", err)
+	}
+	*now = now.Add(15 * time.Second)
+	expected := s.CurrentHandViews(g)["Player_1"].LegalMoves[0]
+	pName, move, _, _, err := s.PlayTimedOutTurn(ctx, g)
+	if err != nil || pName != "Player_1" || move.CardPlayed != expected.CardPlayed {
+		t.Errorf("The first legal move of Player_1 should be played but %v plays %v with error %v", pName, move, err)
+	}
+
+	// the move can be chosen by a strategy
+	s.TimeoutStrategy = &firstMoveBot{}
+	*now = now.Add(15 * time.Second)
+	next := currentPlayer(g).Name
+	if pName, _, _, _, err := s.PlayTimedOutTurn(ctx, g); err != nil || pName != next {
+		t.Errorf("A card of %v should be played but %v plays with error %v", next, pName, err)
+	}
+}
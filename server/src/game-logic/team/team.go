@@ -6,7 +6,8 @@ import (
 	"go-scopone/src/game-logic/player"
 )
 
-// Team is made of 2 Players, has some Cards taken and can have some SCOPE_OF_SCOPONE
+// Team is made of 2 Players, or just one Player in the games of Scopa with 2 or 3 Players, has some Cards taken
+// and can have some SCOPE_OF_SCOPONE
 type Team struct {
 	Players        []*player.Player
 	TakenCards     []deck.Card
@@ -15,14 +16,22 @@ type Team struct {
 
 // New returns a team with 2 players
 func New() *Team {
+	return NewWithSize(2)
+}
+
+// NewWithSize returns a team with the number of players specified
+func NewWithSize(size int) *Team {
 	team := Team{}
-	players := make([]*player.Player, 2)
+	players := make([]*player.Player, size)
 	team.Players = players
 	return &team
 }
 
-// Name returns the name of the team
+// Name returns the name of the team, made of the names of its players
 func Name(t *Team) string {
-	name := t.Players[0].Name + "_" + t.Players[1].Name
+	name := t.Players[0].Name
+	for _, p := range t.Players[1:] {
+		name = name + "_" + p.Name
+	}
 	return name
 }
@@ -22,3 +22,13 @@ func TestName(t *testing.T) {
 		t.Errorf("Expected %v to contain %v", teamName, n2)
 	}
 }
+
+func TestNameOfTeamWithOnePlayer(t *testing.T) {
+	n1 := "Player_1"
+	team := NewWithSize(1)
+	team.Players[0] = player.New(n1)
+	teamName := Name(team)
+	if teamName != n1 {
+		t.Errorf("Expected %v to be %v", teamName, n1)
+	}
+}
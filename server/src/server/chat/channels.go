@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"fmt"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Kind is the kind of a channel of the chat
+type Kind string
+
+// the kinds of the channels
+const (
+	// Lobby is the channel of all the players in the Osteria
+	Lobby Kind = "lobby"
+	// Table is the channel of the players and of the observers of a game
+	Table Kind = "table"
+	// Team is the channel of the players of a team of a game, who can be kept from using it while a hand is played,
+	// see scopone.Game.TeamChatAllowed
+	Team Kind = "team"
+)
+
+// Channel is a channel of the chat - the table and the team channels have the name of their game and the team
+// channels the index of their team, which does not change when a player of the team is replaced
+type Channel struct {
+	Kind     Kind   `json:"kind"`
+	GameName string `json:"gameName,omitempty"`
+	Team     int    `json:"team,omitempty"`
+}
+
+// LobbyChannel is the channel of all the players in the Osteria
+var LobbyChannel = Channel{Kind: Lobby}
+
+// GameChannel returns the channel of the game, of the kind passed in, where the player can send messages: the table
+// of the game, if the player plays or observes it, or the team of the player in the game
+// An error wrapping ErrTeamChatDisabled is returned if the player can not send messages to the team now
+func GameChannel(g *scopone.Game, playerName string, kind Kind) (Channel, error) {
+	switch kind {
+	case Table:
+		if !atTable(g, playerName) {
+			return Channel{}, fmt.Errorf("%w - %v does not play or observe game %v", ErrNotInChannel, playerName, g.Name)
+		}
+		return Channel{Kind: Table, GameName: g.Name}, nil
+	case Team:
+		teamIndex, _, seated := g.TeamOf(playerName)
+		if !seated {
+			return Channel{}, fmt.Errorf("%w - %v does not play game %v", ErrNotInChannel, playerName, g.Name)
+		}
+		if len(g.Teams[teamIndex].Players) < 2 {
+			return Channel{}, fmt.Errorf("%w - The players of game %v have no partner", ErrInvalidChannel, g.Name)
+		}
+		if !g.TeamChatAllowed() {
+			return Channel{}, fmt.Errorf("%w - Game %v", ErrTeamChatDisabled, g.Name)
+		}
+		return Channel{Kind: Team, GameName: g.Name, Team: teamIndex}, nil
+	default:
+		return Channel{}, fmt.Errorf("%w - There is no channel %q for game %v", ErrInvalidChannel, kind, g.Name)
+	}
+}
+
+// GameChannels returns the channels of the game whose messages the player gets: the table, if the player plays or
+// observes the game, and the team of the player, if the player has a partner
+func GameChannels(g *scopone.Game, playerName string) []Channel {
+	channels := make([]Channel, 0)
+	if !atTable(g, playerName) {
+		return channels
+	}
+	channels = append(channels, Channel{Kind: Table, GameName: g.Name})
+	if teamIndex, _, seated := g.TeamOf(playerName); seated && len(g.Teams[teamIndex].Players) > 1 {
+		channels = append(channels, Channel{Kind: Team, GameName: g.Name, Team: teamIndex})
+	}
+	return channels
+}
+
+// Members returns the players who get the messages of a channel of the game - the messages of the lobby are sent
+// to all the players in the Osteria
+func Members(g *scopone.Game, channel Channel) []string {
+	names := make([]string, 0)
+	switch channel.Kind {
+	case Table:
+		for pName := range g.Players {
+			if !g.SeatsLeft[pName] {
+				names = append(names, pName)
+			}
+		}
+		for oName := range g.Observers {
+			names = append(names, oName)
+		}
+	case Team:
+		if channel.Team < 0 || channel.Team >= len(g.Teams) {
+			return names
+		}
+		for _, p := range g.Teams[channel.Team].Players {
+			if p != nil && !g.SeatsLeft[p.Name] {
+				names = append(names, p.Name)
+			}
+		}
+	}
+	return names
+}
+
+// JoinedAt returns the time the player has joined the channel of the game, so that the player does not get the
+// messages sent before - a player who takes the seat of another player gets only what the team has said since then,
+// while the table and the lobby have no secrets, and the zero time is returned for them
+func JoinedAt(g *scopone.Game, playerName string, channel Channel) time.Time {
+	if channel.Kind != Team || g == nil {
+		return time.Time{}
+	}
+	return g.Seated[playerName]
+}
+
+// atTable returns true if the player plays the game, and has not left the seat, or observes it
+func atTable(g *scopone.Game, playerName string) bool {
+	_, seated := g.Players[playerName]
+	_, observing := g.Observers[playerName]
+	return seated && !g.SeatsLeft[playerName] || observing
+}
@@ -0,0 +1,123 @@
+// Package chat implements the chat of the Osteria: the lobby channel of all the players in the Osteria, the table
+// channel of the players and the observers of each game and the team channel of the players of each team
+// The last messages of each channel are kept, so that the players entering the Osteria or a game get them, and a
+// player can send only a limited number of messages in a while
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Errors returned by the chat
+var (
+	ErrEmptyMessage     = errors.New("Empty chat message")
+	ErrMessageTooLong   = errors.New("Chat message too long")
+	ErrInvalidChannel   = errors.New("Invalid chat channel")
+	ErrNotInChannel     = errors.New("Player not in the chat channel")
+	ErrTeamChatDisabled = errors.New("Team chat disabled while the hand is played")
+	ErrTooManyMessages  = errors.New("Too many chat messages")
+	ErrChatStoreFailure = errors.New("Chat store failure")
+)
+
+const (
+	// HistorySize is the number of the last messages of a channel sent to a player entering the channel
+	HistorySize = 50
+	// MaxTextLength is the maximum number of characters of a message
+	MaxTextLength = 500
+)
+
+// Message is a message sent by a player to a channel
+type Message struct {
+	Channel Channel   `json:"channel"`
+	Sender  string    `json:"sender"`
+	Text    string    `json:"text"`
+	Sent    time.Time `json:"sent"`
+}
+
+// Store keeps the messages of the chat
+type Store interface {
+	// AddMessage adds a message to its channel
+	AddMessage(ctx context.Context, msg Message) error
+	// Messages returns the last messages of the channel, at most the number passed in, from the oldest one
+	Messages(ctx context.Context, channel Channel, last int) ([]Message, error)
+	// SentSince returns the number of messages sent by the player, to any channel, since the time passed in
+	SentSince(ctx context.Context, sender string, since time.Time) (int, error)
+	// ClearGame removes the messages of the table and of the team channels of the game
+	ClearGame(ctx context.Context, gameName string) error
+}
+
+// RateLimit is the maximum number of messages a player can send in a window of time
+type RateLimit struct {
+	Messages int
+	Window   time.Duration
+}
+
+// DefaultRateLimit is the rate limit of the chat of the servers
+var DefaultRateLimit = RateLimit{Messages: 5, Window: 10 * time.Second}
+
+// Chat sends the messages of the players to the channels, keeping them in its store
+type Chat struct {
+	store Store
+	limit RateLimit
+}
+
+// New returns the chat which keeps the messages in the store passed in and limits the messages of each player with
+// the rate limit passed in
+func New(store Store, limit RateLimit) *Chat {
+	return &Chat{store: store, limit: limit}
+}
+
+// Send sends a message of the player to the channel, which has to be a channel the player can send to, see
+// ChannelFor, and returns the message sent
+// An error wrapping ErrTooManyMessages is returned if the player has already sent all the messages allowed by the
+// rate limit in its window
+func (c *Chat) Send(ctx context.Context, sender string, channel Channel, text string) (Message, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Message{}, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return Message{}, fmt.Errorf("%w - A message can have at most %v characters", ErrMessageTooLong, MaxTextLength)
+	}
+	now := time.Now()
+	sent, err := c.store.SentSince(ctx, sender, now.Add(-c.limit.Window))
+	if err != nil {
+		return Message{}, fmt.Errorf("%w - %v", ErrChatStoreFailure, err)
+	}
+	if sent >= c.limit.Messages {
+		return Message{}, fmt.Errorf("%w - %v can send at most %v messages every %v", ErrTooManyMessages, sender,
+			c.limit.Messages, c.limit.Window)
+	}
+	msg := Message{Channel: channel, Sender: sender, Text: text, Sent: now}
+	if err := c.store.AddMessage(ctx, msg); err != nil {
+		return Message{}, fmt.Errorf("%w - %v", ErrChatStoreFailure, err)
+	}
+	return msg, nil
+}
+
+// History returns the last messages of the channel sent since the time passed in, from the oldest one - see
+// JoinedAt for the time a player has joined a channel
+func (c *Chat) History(ctx context.Context, channel Channel, since time.Time) ([]Message, error) {
+	messages, err := c.store.Messages(ctx, channel, HistorySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w - %v", ErrChatStoreFailure, err)
+	}
+	for len(messages) > 0 && messages[0].Sent.Before(since) {
+		messages = messages[1:]
+	}
+	return messages, nil
+}
+
+// ClearGame removes the messages of the channels of the game, so that a new game with the same name does not get
+// them - it is called when a game is created and when it is closed
+func (c *Chat) ClearGame(ctx context.Context, gameName string) error {
+	if err := c.store.ClearGame(ctx, gameName); err != nil {
+		return fmt.Errorf("%w - %v", ErrChatStoreFailure, err)
+	}
+	return nil
+}
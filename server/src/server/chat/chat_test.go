@@ -0,0 +1,194 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the calls of the tests
+var ctx = context.Background()
+
+func TestSend(t *testing.T) {
+	c := New(NewMemoryStore(), DefaultRateLimit)
+	if _, err := c.Send(ctx, "p1", LobbyChannel, "  "); !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("An empty message should return ErrEmptyMessage but returns %v", err)
+	}
+	if _, err := c.Send(ctx, "p1", LobbyChannel, strings.Repeat("a", MaxTextLength+1)); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("A message too long should return ErrMessageTooLong but returns %v", err)
+	}
+	msg, err := c.Send(ctx, "p1", LobbyChannel, " hello ")
+	if err != nil {
+		t.Fatalf("The message could not be sent: %v", err)
+	}
+	if msg.Text != "hello" || msg.Sender != "p1" || msg.Sent.IsZero() {
+		t.Errorf("The message sent should be hello from p1 but is %v", msg)
+	}
+	c.Send(ctx, "p2", LobbyChannel, "hi")
+	c.Send(ctx, "p2", Channel{Kind: Table, GameName: "game"}, "at the table")
+	history, _ := c.History(ctx, LobbyChannel, time.Time{})
+	if len(history) != 2 || history[0].Text != "hello" || history[1].Text != "hi" {
+		t.Errorf("The history of the lobby should be the 2 messages sent to the lobby but is %v", history)
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	c := New(NewMemoryStore(), RateLimit{Messages: HistorySize + 5, Window: time.Hour})
+	for i := 0; i < HistorySize+5; i++ {
+		c.Send(ctx, "p1", LobbyChannel, fmt.Sprintf("message %v", i))
+	}
+	history, _ := c.History(ctx, LobbyChannel, time.Time{})
+	if len(history) != HistorySize || history[0].Text != "message 5" {
+		t.Errorf("The history should have the last %v messages but has %v starting from %v", HistorySize,
+			len(history), history[0].Text)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	c := New(NewMemoryStore(), RateLimit{Messages: 2, Window: 50 * time.Millisecond})
+	for i := 0; i < 2; i++ {
+		if _, err := c.Send(ctx, "p1", LobbyChannel, "hello"); err != nil {
+			t.Fatalf("The message %v could not be sent: %v", i, err)
+		}
+	}
+	if _, err := c.Send(ctx, "p1", Channel{Kind: Table, GameName: "game"}, "hello"); !errors.Is(err, ErrTooManyMessages) {
+		t.Errorf("The third message in the window should return ErrTooManyMessages but returns %v", err)
+	}
+	if _, err := c.Send(ctx, "p2", LobbyChannel, "hello"); err != nil {
+		t.Errorf("The rate limit of p1 should not stop p2 but the error is %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.Send(ctx, "p1", LobbyChannel, "hello again"); err != nil {
+		t.Errorf("p1 should send again once the window has passed but the error is %v", err)
+	}
+}
+
+// gameOf4 returns a game of Scopone with the players p1, p2, p3 and p4, in this order, and the observer o1
+func gameOf4(t *testing.T, options scopone.GameOptions) (*scopone.Scopone, *scopone.Game) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, err := s.NewGame(ctx, "game", options)
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for _, pName := range []string{"p1", "p2", "p3", "p4", "o1"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	for _, pName := range []string{"p1", "p2", "p3", "p4"} {
+		s.AddPlayerToGame(ctx, pName, "game")
+		s.PlayerReady(ctx, pName, "game")
+	}
+	s.AddObserverToGame(ctx, "o1", "game")
+	return s, g
+}
+
+func TestGameChannels(t *testing.T) {
+	_, g := gameOf4(t, scopone.GameOptions{})
+	table, err := GameChannel(g, "o1", Table)
+	if err != nil {
+		t.Fatalf("o1 who observes the game should send to the table but the error is %v", err)
+	}
+	if members := Members(g, table); len(members) != 5 {
+		t.Errorf("The table should have the 4 players and the observer but has %v", members)
+	}
+	if _, err := GameChannel(g, "o1", Team); !errors.Is(err, ErrNotInChannel) {
+		t.Errorf("An observer should not send to a team but the error is %v", err)
+	}
+	if _, err := GameChannel(g, "p5", Table); !errors.Is(err, ErrNotInChannel) {
+		t.Errorf("A player not in the game should not send to its table but the error is %v", err)
+	}
+	team, err := GameChannel(g, "p4", Team)
+	if err != nil {
+		t.Fatalf("p4 should send to its team but the error is %v", err)
+	}
+	if members := Members(g, team); len(members) != 2 || members[0] != "p3" || members[1] != "p4" {
+		t.Errorf("The team of p4 should be p3 and p4 but is %v", members)
+	}
+	if channels := GameChannels(g, "p1"); len(channels) != 2 || channels[1].Team != 0 {
+		t.Errorf("p1 should get the messages of the table and of the first team but gets %v", channels)
+	}
+	if channels := GameChannels(g, "o1"); len(channels) != 1 {
+		t.Errorf("o1 should get only the messages of the table but gets %v", channels)
+	}
+}
+
+func TestNoTeamChatInHands(t *testing.T) {
+	s, g := gameOf4(t, scopone.GameOptions{NoTeamChatInHands: true})
+	if _, err := GameChannel(g, "p1", Team); err != nil {
+		t.Errorf("The team should chat before the first hand but the error is %v", err)
+	}
+	s.NewHand(ctx, g)
+	if _, err := GameChannel(g, "p1", Team); !errors.Is(err, ErrTeamChatDisabled) {
+		t.Errorf("The team should not chat while the hand is played but the error is %v", err)
+	}
+	if _, err := GameChannel(g, "p1", Table); err != nil {
+		t.Errorf("The table should chat while the hand is played but the error is %v", err)
+	}
+}
+
+func TestNoTeamChatWithoutPartner(t *testing.T) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, _ := s.NewGame(ctx, "game", scopone.GameOptions{Variant: scopone.Scopa, NumberOfPlayers: 2})
+	s.PlayerEnters(ctx, "p1", "")
+	s.AddPlayerToGame(ctx, "p1", "game")
+	if _, err := GameChannel(g, "p1", Team); !errors.Is(err, ErrInvalidChannel) {
+		t.Errorf("A player without partner should not chat with the team but the error is %v", err)
+	}
+}
+
+func TestClearGame(t *testing.T) {
+	c := New(NewMemoryStore(), RateLimit{Messages: 10, Window: time.Hour})
+	table := Channel{Kind: Table, GameName: "game"}
+	team := Channel{Kind: Team, GameName: "game", Team: 1}
+	other := Channel{Kind: Table, GameName: "other"}
+	for _, channel := range []Channel{LobbyChannel, table, team, other} {
+		c.Send(ctx, "p1", channel, "hello")
+	}
+	if err := c.ClearGame(ctx, "game"); err != nil {
+		t.Fatalf("The chat of the game could not be cleared: %v", err)
+	}
+	for _, channel := range []Channel{table, team} {
+		if history, _ := c.History(ctx, channel, time.Time{}); len(history) != 0 {
+			t.Errorf("The channel %v of the game cleared should have no messages but has %v", channel, history)
+		}
+	}
+	for _, channel := range []Channel{LobbyChannel, other} {
+		if history, _ := c.History(ctx, channel, time.Time{}); len(history) != 1 {
+			t.Errorf("The channel %v should keep its message but has %v", channel, history)
+		}
+	}
+}
+
+func TestTeamHistoryOfSeatTaken(t *testing.T) {
+	s, g := gameOf4(t, scopone.GameOptions{})
+	c := New(NewMemoryStore(), DefaultRateLimit)
+	team, _ := GameChannel(g, "p1", Team)
+	table, _ := GameChannel(g, "p1", Table)
+	c.Send(ctx, "p1", team, "before")
+	c.Send(ctx, "p1", table, "at the table")
+	s.NewHand(ctx, g)
+	if err := s.LeaveSeat(ctx, "game", "p2"); err != nil {
+		t.Fatalf("p2 could not leave the seat: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	s.PlayerEnters(ctx, "p5", "")
+	if err := s.TakeSeat(ctx, "p5", "game", "p2"); err != nil {
+		t.Fatalf("p5 could not take the seat of p2: %v", err)
+	}
+	c.Send(ctx, "p1", team, "after")
+
+	history, _ := c.History(ctx, team, JoinedAt(g, "p5", team))
+	if len(history) != 1 || history[0].Text != "after" {
+		t.Errorf("p5 should get only the team messages sent after taking the seat but gets %v", history)
+	}
+	if history, _ := c.History(ctx, team, JoinedAt(g, "p1", team)); len(history) != 2 {
+		t.Errorf("p1 should get all the team messages but gets %v", history)
+	}
+	if history, _ := c.History(ctx, table, JoinedAt(g, "p5", table)); len(history) != 1 {
+		t.Errorf("p5 should get the messages of the table sent before taking the seat but gets %v", history)
+	}
+}
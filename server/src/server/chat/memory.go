@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the last messages of each channel in memory - they are lost when the program stops
+type MemoryStore struct {
+	mu       sync.Mutex
+	channels map[Channel][]Message
+	// sent are the times of the messages sent by each player, kept only as long as the rate limit needs them
+	sent map[string][]time.Time
+}
+
+// NewMemoryStore returns a store with no messages
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{channels: make(map[Channel][]Message), sent: make(map[string][]time.Time)}
+}
+
+// AddMessage adds a message to its channel, dropping the oldest message of the channel if it has already
+// HistorySize messages
+func (store *MemoryStore) AddMessage(ctx context.Context, msg Message) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	messages := append(store.channels[msg.Channel], msg)
+	if len(messages) > HistorySize {
+		messages = messages[len(messages)-HistorySize:]
+	}
+	store.channels[msg.Channel] = messages
+	store.sent[msg.Sender] = append(store.sent[msg.Sender], msg.Sent)
+	return nil
+}
+
+// Messages returns the last messages of the channel, from the oldest one
+func (store *MemoryStore) Messages(ctx context.Context, channel Channel, last int) ([]Message, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	messages := store.channels[channel]
+	if len(messages) > last {
+		messages = messages[len(messages)-last:]
+	}
+	return append([]Message{}, messages...), nil
+}
+
+// SentSince returns the number of messages sent by the player since the time passed in - the times of the messages
+// sent before are dropped, since the rate limit asks always for the messages of a window ending now
+func (store *MemoryStore) SentSince(ctx context.Context, sender string, since time.Time) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	sent := store.sent[sender]
+	for len(sent) > 0 && sent[0].Before(since) {
+		sent = sent[1:]
+	}
+	if len(sent) == 0 {
+		delete(store.sent, sender)
+		return 0, nil
+	}
+	store.sent[sender] = sent
+	return len(sent), nil
+}
+
+// ClearGame removes the messages of the table and of the team channels of the game
+func (store *MemoryStore) ClearGame(ctx context.Context, gameName string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for channel := range store.channels {
+		if channel.Kind != Lobby && channel.GameName == gameName {
+			delete(store.channels, channel)
+		}
+	}
+	return nil
+}
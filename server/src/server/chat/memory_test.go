@@ -0,0 +1,13 @@
+// the conformance tests of the stores import chat, so they are run from a test package of their own
+package chat_test
+
+import (
+	"testing"
+
+	"go-scopone/src/server/chat"
+	"go-scopone/src/store/storetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.RunChat(t, chat.NewMemoryStore())
+}
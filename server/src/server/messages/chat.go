@@ -0,0 +1,22 @@
+package server
+
+import (
+	"go-scopone/src/server/chat"
+)
+
+// NewChatMessage creates the message with a message of the chat sent to a channel of the player
+func NewChatMessage(playerName string, msg chat.Message) MessageToOnePlayer {
+	chatMsg := NewMessageToOnePlayer(ChatMsgID, playerName)
+	chatMsg.GameName = msg.Channel.GameName
+	chatMsg.ChatMessages = []chat.Message{msg}
+	return chatMsg
+}
+
+// NewChatHistoryMessage creates the message with the last messages of a channel of the chat, sent to the player
+// entering the channel
+func NewChatHistoryMessage(playerName string, channel chat.Channel, history []chat.Message) MessageToOnePlayer {
+	msg := NewMessageToOnePlayer(ChatHistoryMsgID, playerName)
+	msg.GameName = channel.GameName
+	msg.ChatMessages = history
+	return msg
+}
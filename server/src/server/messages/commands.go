@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go-scopone/src/auth"
+	"go-scopone/src/game-logic/actor"
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// GameCommand translates a message of a player into the command for the game of the message, together with the id of
+// the message sent back to the player if the command fails
+// It returns false if the message is not a command for a game
+func GameCommand(msg MessageFromPlayer, playerName string) (command actor.Command, errorMsgID string, isGameCommand bool,
+	err error) {
+	switch msg.ID {
+	case "addPlayerToGame":
+		return actor.Join{PlayerName: playerName, Seat: msg.Seat, Team: msg.Team, Password: msg.GamePassword},
+			ErrorAddingPlayerToGameMsgID, true, nil
+	case "addBotToGame":
+		strategy, err := bot.New(msg.BotStrategy)
+		if err != nil {
+			return nil, ErrorAddingPlayerToGameMsgID, true, err
+		}
+		return actor.JoinBot{Strategy: strategy}, ErrorAddingPlayerToGameMsgID, true, nil
+	case "addObserverToGame":
+		return actor.Observe{PlayerName: playerName, Password: msg.GamePassword}, ErrorAddingObserverToGameMsgID, true, nil
+	case "swapSeats":
+		if msg.Seat == nil {
+			return nil, ErrorMsgID, true, fmt.Errorf("%w - Message swapSeats has no seat", scopone.ErrInvalidSeat)
+		}
+		return actor.SwapSeats{PlayerName: playerName, Seat: *msg.Seat}, ErrorMsgID, true, nil
+	case "kickFromSeat":
+		if msg.Seat == nil {
+			return nil, ErrorMsgID, true, fmt.Errorf("%w - Message kickFromSeat has no seat", scopone.ErrInvalidSeat)
+		}
+		return actor.Kick{PlayerName: playerName, Seat: *msg.Seat}, ErrorMsgID, true, nil
+	case "ready":
+		return actor.Ready{PlayerName: playerName}, ErrorMsgID, true, nil
+	case "leaveSeat":
+		return actor.LeaveSeat{PlayerName: playerName}, ErrorMsgID, true, nil
+	case "voteToAbandon":
+		return actor.VoteToAbandon{PlayerName: playerName}, ErrorMsgID, true, nil
+	case "newHand":
+		return actor.NewHand{PlayerName: playerName}, ErrorMsgID, true, nil
+	case "playCard":
+		return actor.PlayCard{PlayerName: playerName, CardPlayed: msg.CardPlayed, CardsTaken: msg.CardsTaken},
+			ErrorPlayingCardMsgID, true, nil
+	case "closeGame":
+		return actor.Close{PlayerName: playerName}, ErrorMsgID, true, nil
+	case "turnTimer":
+		return actor.TurnTimer{}, ErrorMsgID, true, nil
+	}
+	return nil, ErrorMsgID, false, fmt.Errorf("Message %v is not a command for a game", msg.ID)
+}
+
+// NewCommandErrorMessage creates the message for the player whose command for a game has failed - the card played
+// and the cards taken are sent back so that the player can play them again
+func NewCommandErrorMessage(msg MessageFromPlayer, errorMsgID string, playerName string, err error) MessageToOnePlayer {
+	response := NewErrorMessage(errorMsgID, playerName, err)
+	response.GameName = msg.GameName
+	response.CardPlayed = msg.CardPlayed
+	response.CardsTaken = msg.CardsTaken
+	return response
+}
+
+// AccountCommand executes a message of a player about its account, i.e. register, which creates the account and logs
+// the player in, and login, and returns the message with the session token of the player, who from then on is the
+// player of the account
+// It returns false if the message is not about an account
+func AccountCommand(ctx context.Context, accounts *auth.Accounts, msg MessageFromPlayer) (response MessageToOnePlayer,
+	isAccountCommand bool, err error) {
+	switch msg.ID {
+	case "register":
+		err = accounts.Register(ctx, msg.PlayerName, msg.Password)
+		if err != nil {
+			return NewErrorMessage(ErrorLoggingInMsgID, msg.PlayerName, err), true, err
+		}
+	case "login":
+	default:
+		return response, false, fmt.Errorf("Message %v is not about an account", msg.ID)
+	}
+	token, err := accounts.Login(ctx, msg.PlayerName, msg.Password)
+	if err != nil {
+		return NewErrorMessage(ErrorLoggingInMsgID, msg.PlayerName, err), true, err
+	}
+	response = NewMessageToOnePlayer(LoggedInMsgID, msg.PlayerName)
+	response.ResponseTo = msg.ID
+	response.Token = token
+	return response, true, nil
+}
+
+// InvitedToNewGame returns the players invited to the new game of a newGame message - if the game is private, the
+// player who creates it is invited too, so that the player can see the game created
+func InvitedToNewGame(msg MessageFromPlayer, playerName string) []string {
+	if msg.GamePassword == "" && len(msg.Invited) == 0 {
+		return nil
+	}
+	for _, pName := range msg.Invited {
+		if pName == playerName {
+			return msg.Invited
+		}
+	}
+	return append([]string{playerName}, msg.Invited...)
+}
@@ -2,11 +2,16 @@
 package server
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"go-scopone/src/auth"
 	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/replay"
 	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 
 	"github.com/spf13/viper"
 )
@@ -20,6 +25,63 @@ type MessageFromPlayer struct {
 	GameName   string      `json:"gameName"`
 	CardPlayed deck.Card   `json:"cardPlayed"`
 	CardsTaken []deck.Card `json:"cardsTaken"`
+	// TargetScore is the score to reach to win a new game - it is used only by the newGame message
+	TargetScore int `json:"targetScore,omitempty"`
+	// Variant is the variant of the rules of a new game - it is used only by the newGame message
+	Variant scopone.Variant `json:"variant,omitempty"`
+	// NumberOfPlayers is the number of players of a new game - it is used only by the newGame message
+	NumberOfPlayers int `json:"numberOfPlayers,omitempty"`
+	// BotStrategy is the strategy of the bot added to a game - it is used only by the addBotToGame message
+	BotStrategy string `json:"botStrategy,omitempty"`
+	// Seed is the seed of the decks of a new game - it is used only by the newGame message
+	Seed int64 `json:"seed,omitempty"`
+	// MoveTimeLimit is the number of seconds each player of a new game has to play a card - it is used only by the
+	// newGame message
+	MoveTimeLimit int `json:"moveTimeLimit,omitempty"`
+	// ObserverMode is what the observers of a new game see of its hands and ObserverDelay the number of moves they
+	// are behind the players with the delayed mode - they are used only by the newGame message
+	ObserverMode  scopone.ObserverMode `json:"observerMode,omitempty"`
+	ObserverDelay int                  `json:"observerDelay,omitempty"`
+	// HandIndex is the index of the hand of a game, starting from 0 - it is used only by the replayHand message
+	HandIndex int `json:"handIndex,omitempty"`
+	// Password is the password of the account of the player - it is used only by the register and login messages
+	Password string `json:"password,omitempty"`
+	// Seat is the seat chosen by the player, see scopone.Game.Seats - it is used by the addPlayerToGame message, where
+	// it is optional, and by the swapSeats and kickFromSeat messages
+	Seat *int `json:"seat,omitempty"`
+	// Team is the team chosen by the player, starting from 0, who takes its first seat free - it is used only by the
+	// addPlayerToGame message when the player does not choose the seat
+	Team *int `json:"team,omitempty"`
+	// ReconnectToken is the token of the seat of the player in a game - it is used only by the playerEntersOsteria
+	// message of a player who comes back to the game
+	ReconnectToken string `json:"reconnectToken,omitempty"`
+	// GamePassword is the password, or invite code, of a private game - it is used by the newGame message, which
+	// makes the game private, and by the addPlayerToGame and addObserverToGame messages of the players not invited
+	GamePassword string `json:"gamePassword,omitempty"`
+	// Invited are the players invited to a new private game - it is used only by the newGame message
+	Invited []string `json:"invited,omitempty"`
+	// NoTeamChatInHands keeps the players of the teams of a new game from chatting with each other while a hand is
+	// played - it is used only by the newGame message
+	NoTeamChatInHands bool `json:"noTeamChatInHands,omitempty"`
+	// Channel is the kind of the channel of the chat the player sends the Text to, the lobby if it is empty - the
+	// table and the team channels are the channels of the game of the message - they are used only by the chat
+	// message
+	Channel chat.Kind `json:"channel,omitempty"`
+	Text    string    `json:"text,omitempty"`
+}
+
+// Redacted returns the message without the passwords and the reconnect token, so that it can be logged
+func (msg MessageFromPlayer) Redacted() MessageFromPlayer {
+	if msg.Password != "" {
+		msg.Password = "***"
+	}
+	if msg.GamePassword != "" {
+		msg.GamePassword = "***"
+	}
+	if msg.ReconnectToken != "" {
+		msg.ReconnectToken = "***"
+	}
+	return msg
 }
 
 // Ids of messages that can be sent to the clients
@@ -33,6 +95,27 @@ const (
 	HandView                       = "HandView"
 	CardsPlayedAndTaken            = "CardsPlayedAndTaken"
 	ErrorAddingObserverToGameMsgID = "ErrorAddingObserverToGame"
+	ErrorPlayingCardMsgID          = "ErrorPlayingCard"
+	NotYourTurnMsgID               = "NotYourTurn"
+	StoreFailureMsgID              = "StoreFailure"
+	ErrorMsgID                     = "Error"
+	GameFinishedMsgID              = "GameFinished"
+	HandReplayMsgID                = "HandReplay"
+	ErrorReplayingHandMsgID        = "ErrorReplayingHand"
+	LoggedInMsgID                  = "LoggedIn"
+	ErrorLoggingInMsgID            = "ErrorLoggingIn"
+	NotLoggedInMsgID               = "NotLoggedIn"
+	ReconnectTokenMsgID            = "ReconnectToken"
+	InvalidReconnectTokenMsgID     = "InvalidReconnectToken"
+	TurnWarningMsgID               = "TurnWarning"
+	TurnTimedOutMsgID              = "TurnTimedOut"
+	SeatTakenMsgID                 = "SeatTaken"
+	PlayersNotReadyMsgID           = "PlayersNotReady"
+	NotInvitedMsgID                = "NotInvited"
+	ChatMsgID                      = "Chat"
+	ChatHistoryMsgID               = "ChatHistory"
+	ErrorChattingMsgID             = "ErrorChatting"
+	TooManyChatMessagesMsgID       = "TooManyChatMessages"
 )
 
 // MessageToAllClients is a message to be sent to all clients
@@ -45,6 +128,8 @@ type MessageToAllClients struct {
 	Players    []*player.Player `json:"players,omitempty"`
 	Games      []*scopone.Game  `json:"games"`
 	Teams      [][]string       `json:"teams,omitempty"`
+	GameName   string           `json:"gameName,omitempty"`
+	Winners    []string         `json:"winners,omitempty"`
 	MsgVersion string           `json:"msgVersion"`
 }
 
@@ -71,7 +156,15 @@ type MessageToOnePlayer struct {
 	CardsTaken         []deck.Card                       `json:"cardsTaken,omitempty"`
 	CardPlayedByPlayer string                            `json:"cardPlayedByPlayer"`
 	FinalTableTake     scopone.FinalTableTake            `json:"finalTableTake"`
-	MsgVersion         string                            `json:"msgVersion"`
+	ReplaySteps        []replay.Step                     `json:"replaySteps,omitempty"`
+	Token              string                            `json:"token,omitempty"`
+	ReconnectToken     string                            `json:"reconnectToken,omitempty"`
+	// RemainingTime is the number of milliseconds the player has left to play a card
+	RemainingTime int64 `json:"remainingTime,omitempty"`
+	// ChatMessages are the messages of the chat sent to a channel, or the last messages of a channel when the player
+	// enters it
+	ChatMessages []chat.Message `json:"chatMessages,omitempty"`
+	MsgVersion   string         `json:"msgVersion"`
 }
 
 // NewMessageToOnePlayer creates a message for one player
@@ -84,6 +177,94 @@ func NewMessageToOnePlayer(id string, playerName string) MessageToOnePlayer {
 	return msg
 }
 
+// NewErrorMessage creates the message for the player whose command has failed with an error
+// The errors of the Osteria which have a specific message are mapped to the id of such message, all other errors
+// are sent with the id passed in, which is the one of the error message expected for the command that failed
+func NewErrorMessage(id string, playerName string, err error) MessageToOnePlayer {
+	switch {
+	case errors.Is(err, scopone.ErrPlayerAlreadyInOsteria):
+		id = PlayerIsAlreadyInOsteria
+	case errors.Is(err, scopone.ErrGameAlreadyPresent):
+		id = GameWithSameNamePresent
+	case errors.Is(err, scopone.ErrNotYourTurn):
+		id = NotYourTurnMsgID
+	case errors.Is(err, scopone.ErrIllegalPlay):
+		id = ErrorPlayingCardMsgID
+	case errors.Is(err, scopone.ErrStoreFailure):
+		id = StoreFailureMsgID
+	case errors.Is(err, scopone.ErrSeatTaken), errors.Is(err, scopone.ErrTeamFull):
+		id = SeatTakenMsgID
+	case errors.Is(err, scopone.ErrPlayersNotReady):
+		id = PlayersNotReadyMsgID
+	case errors.Is(err, scopone.ErrInvalidReconnectToken):
+		id = InvalidReconnectTokenMsgID
+	case errors.Is(err, scopone.ErrNotInvited):
+		id = NotInvitedMsgID
+	case errors.Is(err, chat.ErrTooManyMessages):
+		id = TooManyChatMessagesMsgID
+	case errors.Is(err, auth.ErrNotAuthenticated), errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenExpired):
+		id = NotLoggedInMsgID
+	}
+	msg := NewMessageToOnePlayer(id, playerName)
+	msg.Error = err.Error()
+	return msg
+}
+
+// NewReconnectTokenMessage creates the message with the reconnect token of the seat of the player in a game, which
+// the player has to present entering the Osteria to resume the game
+func NewReconnectTokenMessage(playerName string, gameName string, token string) MessageToOnePlayer {
+	msg := NewMessageToOnePlayer(ReconnectTokenMsgID, playerName)
+	msg.GameName = gameName
+	msg.ReconnectToken = token
+	return msg
+}
+
+// NewTurnWarningMessage creates the message which warns the current player of a game that the time to play a card
+// is running out
+func NewTurnWarningMessage(playerName string, gameName string, remainingTime time.Duration) MessageToOnePlayer {
+	msg := NewMessageToOnePlayer(TurnWarningMsgID, playerName)
+	msg.GameName = gameName
+	msg.RemainingTime = remainingTime.Milliseconds()
+	return msg
+}
+
+// NewTurnTimedOutMessage creates the message which tells all the clients that the turn of a player of a game has
+// expired and that a card is played for the player
+func NewTurnTimedOutMessage(playerName string, gameName string) MessageToAllClients {
+	msg := NewMessageToAllClients(TurnTimedOutMsgID)
+	msg.PlayerName = playerName
+	msg.GameName = gameName
+	return msg
+}
+
+// NewHandReplayMessage creates the message with all the steps of the replay of a closed hand of a game, which the
+// client can move through forward and back - the hands of a game can be replayed only by its players and, if the
+// observers of the game can see the cards of the players once the hand is closed, by its observers
+func NewHandReplayMessage(g *scopone.Game, playerName string, handIndex int) (MessageToOnePlayer, error) {
+	_, seated := g.Players[playerName]
+	_, observing := g.Observers[playerName]
+	switch {
+	case seated && !g.SeatsLeft[playerName]:
+	case !g.VisibleTo(playerName):
+		return MessageToOnePlayer{}, fmt.Errorf("%w - Player %v is not invited to the private game %v",
+			scopone.ErrNotInvited, playerName, g.Name)
+	case !observing:
+		return MessageToOnePlayer{}, fmt.Errorf("%w - Player %v neither plays nor observes game %v",
+			scopone.ErrPlayerNotPlaying, playerName, g.Name)
+	case !g.ObserversSeeClosedHands():
+		return MessageToOnePlayer{}, fmt.Errorf("%w - The hands of game %v can be replayed only by its players",
+			scopone.ErrHiddenFromObservers, g.Name)
+	}
+	r, err := replay.ForClosedHand(g, handIndex)
+	if err != nil {
+		return MessageToOnePlayer{}, err
+	}
+	msg := NewMessageToOnePlayer(HandReplayMsgID, playerName)
+	msg.GameName = g.Name
+	msg.ReplaySteps = r.Steps()
+	return msg, nil
+}
+
 func msgVersion() string {
 	msgVersion, ok := viper.Get("VERSION").(string)
 	if !ok {
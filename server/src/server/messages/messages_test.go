@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the commands of the tests
+var ctx = context.Background()
+
+// privateGameWithHandClosed creates a private game of bots, to which p1 is invited and which p1 observes, and plays
+// its first hand until it is closed
+func privateGameWithHandClosed(t *testing.T, mode scopone.ObserverMode) *scopone.Game {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	g, err := s.NewGame(ctx, "private", scopone.GameOptions{ObserverMode: mode, Invited: []string{"p1", "p2"}})
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := s.AddBotToGame(ctx, "private", &bot.Heuristic{}); err != nil {
+			t.Fatalf("A bot could not be added to the game: %v", err)
+		}
+	}
+	for _, pName := range []string{"p1", "p2", "intruder"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	if _, err := s.AddObserverToGame(ctx, "p1", "private"); err != nil {
+		t.Fatalf("p1 could not observe the game: %v", err)
+	}
+	s.NewHand(ctx, g)
+	for s.IsBotTurn(g) {
+		if _, move, _, _, err := s.PlayBot(ctx, g); err != nil {
+			t.Fatalf("The move %v returns an error %v", move, err)
+		}
+	}
+	return g
+}
+
+func TestHandReplayOfPrivateGame(t *testing.T) {
+	for _, mode := range []scopone.ObserverMode{scopone.ObserveAfterHand, scopone.ObserveDelayed} {
+		g := privateGameWithHandClosed(t, mode)
+		pName := g.Hands[0].CurrentPlayer.Name
+		if _, err := NewHandReplayMessage(g, pName, 0); err != nil {
+			t.Errorf("With mode %v the player %v should replay the hand but gets error %v", mode, pName, err)
+		}
+		msg, err := NewHandReplayMessage(g, "p1", 0)
+		if err != nil || len(msg.ReplaySteps) == 0 {
+			t.Errorf("With mode %v the observer p1 should replay the hand but gets error %v", mode, err)
+		}
+		if _, err := NewHandReplayMessage(g, "intruder", 0); !errors.Is(err, scopone.ErrNotInvited) {
+			t.Errorf("With mode %v a player not invited should not replay the hand but gets error %v", mode, err)
+		}
+		// a player invited has to observe the game to replay its hands
+		if _, err := NewHandReplayMessage(g, "p2", 0); !errors.Is(err, scopone.ErrPlayerNotPlaying) {
+			t.Errorf("With mode %v a player invited who does not observe the game should not replay the hand but gets error %v",
+				mode, err)
+		}
+	}
+}
+
+func TestHandReplayHiddenFromObservers(t *testing.T) {
+	g := privateGameWithHandClosed(t, scopone.ObservePublic)
+	if _, err := NewHandReplayMessage(g, "p1", 0); !errors.Is(err, scopone.ErrHiddenFromObservers) {
+		t.Errorf("The observers of a game observed in public mode should not replay the hands but get error %v", err)
+	}
+}
@@ -3,15 +3,20 @@ package srvgorilla
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
+	"go-scopone/src/auth"
+	"go-scopone/src/game-logic/actor"
 	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/replay"
 	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 	server "go-scopone/src/server/messages"
 
 	"github.com/gorilla/websocket"
@@ -19,20 +24,23 @@ import (
 
 // Client is a middleman between the websocket connection and the hub.
 type client struct {
-	name    string
-	scopone *scopone.Scopone
-	hub     *Hub
+	name string
+	// account is the name of the account the player of the client has logged in with - it is empty until the
+	// player logs in, either with the token sent opening the connection or with a login message
+	account  string
+	accounts *auth.Accounts
+	scopone  *scopone.Scopone
+	// games are the actors which execute the commands of the games
+	games *actor.Osteria
+	// chats is the chat of the Osteria, shared by all the clients
+	chats *chat.Chat
+	hub   *Hub
 	// The websocket connection.
 	conn *websocket.Conn
 	// Buffered channel of outbound messages.
 	send chan []byte
 }
 
-// The processing of each command needs to be synchronized.
-// If the commands sent by different clients are processed at the same time, then their shared state (e.g. the
-// game they are playing) can be accessed in read and write simoultaneously by different processings
-var processCommandMutex sync.Mutex
-
 // readPump pumps messages from the websocket connection to the hub.
 //
 // The application runs readPump in a per-connection goroutine. The application
@@ -45,179 +53,440 @@ func (c *client) readPump() {
 	}()
 	for {
 		_, message, err := c.conn.ReadMessage()
-		processCommandMutex.Lock()
-		{
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("UNEXPECTED error: %v", err)
-				}
-				cName := c.name
-				if cName == "" {
-					cName = "Unknown client - the client did not register as client in the Osteria"
-				} else {
-					_, wasPlaying := c.scopone.RemovePlayer(cName)
-					if wasPlaying {
-						error := fmt.Sprintf("Error Because Player \"%v\" has been removed", cName)
-						sendPlayerLeftOsteria(c, cName, error)
-						sendGames(c, error)
-					}
-				}
-				log.Printf("Error: %v", err)
-				processCommandMutex.Unlock()
-				c.conn.Close()
-				break
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("UNEXPECTED error: %v", err)
+			}
+			cName := c.name
+			if cName == "" {
+				cName = "Unknown client - the client did not register as client in the Osteria"
+			} else {
+				c.leaveOsteria()
 			}
+			log.Printf("Error: %v", err)
+			c.conn.Close()
+			break
+		}
+
+		message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
+		c.processCommand(message)
+	}
+}
+
+// leaveOsteria removes the player of the client from the Osteria when the connection is closed - if the player was
+// playing a game, the game is told that the player has left
+func (c *client) leaveOsteria() {
+	unlock := c.scopone.LockOsteria()
+	g, playing := c.scopone.GameOfPlayer(c.name)
+	if !playing {
+		_, _, e := c.scopone.RemovePlayer(c.name)
+		unlock()
+		if e != nil {
+			log.Printf("Error while removing player %v: %v", c.name, e)
+		}
+		return
+	}
+	unlock()
+	respTo := fmt.Sprintf("Error Because Player \"%v\" has been removed", c.name)
+	changes := &osteriaChanges{}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	err := c.games.Do(ctx, g.Name, actor.Leave{PlayerName: c.name}, c.eventHandler(respTo, changes))
+	if err != nil {
+		log.Printf("Error while removing player %v: %v", c.name, err)
+	}
+	c.sendOsteriaChanges(changes, respTo)
+}
+
+// processCommand processes a command sent by the player connected to the client
+// If the command fails, the error is sent back only to the client which has sent the command
+func (c *client) processCommand(message []byte) {
+	// convert data received to MessageFromPlayer struct
+	var msg server.MessageFromPlayer
+	if err := json.Unmarshal(message, &msg); err != nil {
+		sendError(c, server.ErrorMsgID, c.name, fmt.Errorf("Message %v can not be read: %v", string(message), err))
+		return
+	}
+	fmt.Println("Message received", msg.Redacted())
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	if msg.ID == "register" || msg.ID == "login" {
+		c.processAccountCommand(ctx, msg)
+		return
+	}
+	// all the other commands are sent by the player of the account the client has logged in with
+	if c.account == "" {
+		sendError(c, server.ErrorMsgID, msg.PlayerName,
+			fmt.Errorf("%w - Message %v can be sent only after the login", auth.ErrNotAuthenticated, msg.ID))
+		return
+	}
+	// the commands of a game are sent to the game, which executes them in its own goroutine, while the other
+	// commands lock the whole Osteria
+	switch msg.ID {
+	case "playerEntersOsteria", "newGame":
+		unlock := c.scopone.LockOsteria()
+		defer unlock()
+		c.processOsteriaCommand(ctx, msg)
+	case "replayHand":
+		c.replayHand(msg)
+	case "chat":
+		c.chat(ctx, msg)
+	default:
+		command, errorMsgID, isGameCommand, err := server.GameCommand(msg, c.name)
+		if !isGameCommand {
+			err = fmt.Errorf("Unexpected messageId %v arrived from player %v", msg.ID, c.name)
+		}
+		if err != nil {
+			sendError(c, errorMsgID, c.name, err)
+			return
+		}
+		respTo := fmt.Sprintf("%v - game \"%v\"", msg.ID, msg.GameName)
+		changes := &osteriaChanges{}
+		err = c.games.Do(ctx, msg.GameName, command, c.eventHandler(respTo, changes))
+		if errors.Is(err, scopone.ErrHandStillActive) {
+			// another player has already started the new hand, so there is nothing to do
+			return
+		}
+		if err != nil {
+			// if the command has been executed but the game could not be saved, the error is sent after the updates
+			log.Printf("Error processing command of %v: %v", c.name, err)
+			sendToClient(c, server.NewCommandErrorMessage(msg, errorMsgID, c.name, err))
+		}
+		c.sendOsteriaChanges(changes, respTo)
+	}
+}
+
+// osteriaChanges tells whether the events of a command have changed the lists of the players and of the games
+type osteriaChanges struct {
+	players bool
+	games   bool
+}
 
-			message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
-			fmt.Println("Message received", string(message))
-			// convert data received to MessageFromPlayer struct
-			var msg server.MessageFromPlayer
-			if err := json.Unmarshal(message, &msg); err != nil {
-				panic(err.Error())
+// eventHandler returns the handler which sends to the players the updates for the events of a command - the
+// changes of the lists of the players and of the games are only registered, since the lists can be read only
+// holding the lock of the Osteria, and are sent by sendOsteriaChanges after the command has been executed
+func (c *client) eventHandler(respTo string, changes *osteriaChanges) actor.Handler {
+	return func(ev actor.Event) {
+		switch e := ev.(type) {
+		case actor.PlayerJoined:
+			changes.players = changes.players || e.Bot || e.Replaced != ""
+			changes.games = true
+			if playerClient, connected := c.hub.client(e.PlayerName); connected {
+				sendChatHistory(playerClient, e.Game(), chat.GameChannels(e.Game(), e.PlayerName))
+			}
+			if e.ReconnectToken != "" {
+				sendToClient(c, server.NewReconnectTokenMessage(e.PlayerName, e.Game().Name, e.ReconnectToken))
+			}
+			if e.HandViews != nil {
+				// the player who has taken the seat of another player goes on with the cards of the seat
+				sendPlayerViews(c, e.HandViews, respTo)
+				sendObserverUpdates(c, e.HandViews, respTo, e.Game())
+			}
+		case actor.SeatsChanged, actor.PlayerReady:
+			// the seats and the players ready are sent with the games
+			changes.games = true
+		case actor.PlayerKicked, actor.SeatLeft:
+			changes.players = true
+			changes.games = true
+		case actor.AbandonVoted:
+			// the votes are sent with the games
+			changes.games = true
+		case actor.ObserverJoined:
+			changes.games = true
+			sendObserverUpdates(c, e.HandViews, respTo, e.Game())
+			if observerClient, connected := c.hub.client(e.ObserverName); connected {
+				sendChatHistory(observerClient, e.Game(), chat.GameChannels(e.Game(), e.ObserverName))
 			}
+		case actor.HandStarted:
+			changes.games = true
+			fmt.Println("NewHand", e.Game().Name, len(e.HandViews))
+			sendPlayerViews(c, e.HandViews, respTo)
+			sendObserverUpdates(c, e.HandViews, respTo, e.Game())
+		case actor.CardPlayed:
+			sendCardPlayUpdates(c, e.PlayerName, e.CardPlayed, e.CardsTaken, e.FinalTableTake, e.HandViews, e.Game(),
+				fmt.Sprintf("playCard \"%v\"", e.PlayerName))
+		case actor.GameFinished:
+			changes.games = true
+			sendGameFinished(c, e.Game(), respTo)
+		case actor.TurnWarning:
+			if playerClient, connected := c.hub.client(e.PlayerName); connected {
+				msg := server.NewTurnWarningMessage(e.PlayerName, e.Game().Name, e.RemainingTime)
+				msg.ResponseTo = respTo
+				sendToClient(playerClient, msg)
+			}
+		case actor.TurnTimedOut:
+			sendTurnTimedOut(c, e.PlayerName, e.Game(), respTo)
+		case actor.GameSuspended:
+			changes.games = true
+			sendPlayerLeftOsteria(c, e.PlayerName, respTo)
+		case actor.GameClosed:
+			changes.games = true
+			clearGameChat(c, e.Game().Name)
+		}
+	}
+}
+
+// sendOsteriaChanges sends the lists of the players and of the games which have been changed by a command
+func (c *client) sendOsteriaChanges(changes *osteriaChanges, respTo string) {
+	if !changes.players && !changes.games {
+		return
+	}
+	unlock := c.scopone.LockOsteria()
+	defer unlock()
+	if changes.players {
+		sendPlayers(c, respTo)
+	}
+	if changes.games {
+		sendGames(c, respTo)
+	}
+}
+
+// replayHand sends to the client the replay of a hand of a game
+func (c *client) replayHand(msg server.MessageFromPlayer) {
+	game, unlock, err := c.scopone.LockGame(msg.GameName)
+	if err != nil {
+		sendError(c, server.ErrorReplayingHandMsgID, c.name, err)
+		return
+	}
+	defer unlock()
+	response, err := server.NewHandReplayMessage(game, c.name, msg.HandIndex)
+	if err != nil {
+		sendError(c, server.ErrorReplayingHandMsgID, c.name, err)
+		return
+	}
+	response.ResponseTo = fmt.Sprintf("replayHand - game \"%v\"", msg.GameName)
+	sendToClient(c, response)
+}
+
+// chat sends the message of the player to the channel of the chat chosen - the messages of the lobby are sent to
+// all the players in the Osteria, while the messages of a game are sent to the players of its channel, who are
+// read holding the lock of the game
+func (c *client) chat(ctx context.Context, msg server.MessageFromPlayer) {
+	channel, members := chat.LobbyChannel, c.hub.clientNames()
+	if msg.Channel != "" && msg.Channel != chat.Lobby {
+		game, unlock, err := c.scopone.LockGame(msg.GameName)
+		if err != nil {
+			sendError(c, server.ErrorChattingMsgID, c.name, err)
+			return
+		}
+		defer unlock()
+		channel, err = chat.GameChannel(game, c.name, msg.Channel)
+		if err != nil {
+			sendError(c, server.ErrorChattingMsgID, c.name, err)
+			return
+		}
+		members = chat.Members(game, channel)
+	}
+	sent, err := c.chats.Send(ctx, c.name, channel, msg.Text)
+	if err != nil {
+		sendError(c, server.ErrorChattingMsgID, c.name, err)
+		return
+	}
+	for _, pName := range members {
+		if playerClient, connected := c.hub.client(pName); connected {
+			sendToClient(playerClient, server.NewChatMessage(pName, sent))
+		}
+	}
+}
+
+// sendChatHistory sends to the client of a player the last messages of each channel passed in which has any, sent
+// since the player has joined the channel - the game is the game of the channels, if any
+func sendChatHistory(c *client, g *scopone.Game, channels []chat.Channel) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	for _, channel := range channels {
+		history, err := c.chats.History(ctx, channel, chat.JoinedAt(g, c.name, channel))
+		if err != nil {
+			log.Printf("The chat history of %v could not be read: %v", channel, err)
+			continue
+		}
+		if len(history) > 0 {
+			sendToClient(c, server.NewChatHistoryMessage(c.name, channel, history))
+		}
+	}
+}
+
+// clearGameChat removes the messages of the channels of the game, so that a new game with the same name does not
+// get them
+func clearGameChat(c *client, gameName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	if err := c.chats.ClearGame(ctx, gameName); err != nil {
+		log.Printf("The chat of game %v could not be cleared: %v", gameName, err)
+	}
+}
+
+// processAccountCommand registers the account of the player or logs the player in, sending back the session token
+// The player can not change account after entering the Osteria
+func (c *client) processAccountCommand(ctx context.Context, msg server.MessageFromPlayer) {
+	if c.name != "" {
+		sendError(c, server.ErrorLoggingInMsgID, c.name,
+			fmt.Errorf("Player %v has already entered the Osteria and can not log in again", c.name))
+		return
+	}
+	response, _, err := server.AccountCommand(ctx, c.accounts, msg)
+	if err != nil {
+		log.Printf("Login of %v failed: %v", msg.PlayerName, err)
+		sendToClient(c, response)
+		return
+	}
+	c.account = msg.PlayerName
+	sendToClient(c, response)
+}
 
-			switch msg.ID {
-			case "playerEntersOsteria":
-				playerName := msg.PlayerName
-				hv, alreadyIn := c.scopone.PlayerEnters(playerName)
-				if alreadyIn {
-					// Player is already in the osteria
-					response := server.NewMessageToOnePlayer(server.PlayerIsAlreadyInOsteria, playerName)
-					response.Error = fmt.Sprintf("Player \"%v\" is already in the Osteria", playerName)
-					rsp, e := json.Marshal(response)
-					if e != nil {
-						panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", response, e)
-						panic(panicMessage)
-					}
-					c.send <- []byte(rsp)
-				} else {
-					c.name = playerName
-					c.hub.registerClient <- c
-					if hv == nil {
-						// if there are no handViews to be sent to Players it means that the Player is entering for the fist time in the Osteria
-						// or he is re-entering but was not playing any game previously
-						respTo := "playerEntersOsteria - no handViews"
-						sendPlayers(c, respTo)
-						sendGames(c, respTo)
-					} else {
-						// on the contrary if the handViews are defined it means that the Player is re-entering the Osteria
-						// and that he was previously playing a game, so we return the handViews to all Players for them
-						// to resume the game
-						respTo := fmt.Sprintf("playerEntersOsteria \"%v\"", playerName)
-						sendGames(c, respTo)
-						sendPlayers(c, respTo)
-						sendPlayerViews(c, hv, respTo)
-					}
-				}
-			case "newGame":
-				gameName := msg.GameName
-				_, e := c.scopone.NewGame(gameName)
-				if e != nil {
-					// There is already a game with the same name
-					response := server.NewMessageToOnePlayer(server.GameWithSameNamePresent, c.name)
-					response.Error = fmt.Sprintf("Game \"%v\" with the same name already created", gameName)
-					response.GameName = gameName
-					rsp, e := json.Marshal(response)
-					if e != nil {
-						panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", response, e)
-						panic(panicMessage)
-					}
-					c.send <- []byte(rsp)
-				}
-				respTo := fmt.Sprintf("newGame \"%v\"", gameName)
-				sendGames(c, respTo)
-			case "addPlayerToGame":
-				playerName := msg.PlayerName
-				gameName := msg.GameName
-				e := c.scopone.AddPlayerToGame(playerName, gameName)
-				if e != nil {
-					response := server.NewMessageToOnePlayer(server.ErrorAddingPlayerToGameMsgID, playerName)
-					response.Error = e.Error()
-					rsp, e := json.Marshal(response)
-					if e != nil {
-						panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", response, e)
-						panic(panicMessage)
-					}
-					c.send <- []byte(rsp)
-				} else {
-					respTo := fmt.Sprintf("addPlayerToGame - game \"%v\"", gameName)
-					sendGames(c, respTo)
-				}
-			case "addObserverToGame":
-				playerName := msg.PlayerName
-				gameName := msg.GameName
-				hv, err := c.scopone.AddObserverToGame(playerName, gameName)
-				if err != nil {
-					response := server.NewMessageToOnePlayer(server.ErrorAddingObserverToGameMsgID, playerName)
-					response.Error = err.Error()
-					rsp, e := json.Marshal(response)
-					if e != nil {
-						panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", response, e)
-						panic(panicMessage)
-					}
-					c.send <- []byte(rsp)
-				} else {
-					respTo := fmt.Sprintf("addObserverToGame - game \"%v\"", gameName)
-					sendGames(c, respTo)
-					game := c.scopone.Games[gameName]
-					sendObserverUpdates(c, hv, respTo, game)
-				}
-			case "newHand":
-				gameName := msg.GameName
-				game := c.scopone.Games[gameName]
-				// _, handViewForPlayers, handCreated := game.NewHand()
-				_, handViewForPlayers, handCreated := c.scopone.NewHand(game)
-				if handCreated {
-					respTo := fmt.Sprintf("newHand - game \"%v\"", gameName)
-					fmt.Println("NewHand", gameName, len(handViewForPlayers))
-					sendGames(c, respTo)
-					sendPlayerViews(c, handViewForPlayers, respTo)
-					sendObserverUpdates(c, handViewForPlayers, respTo, game)
-				}
-			case "playCard":
-				handViewForPlayers, finalTableTake, g := c.scopone.Play(msg.PlayerName, msg.CardPlayed, msg.CardsTaken)
-				// if handViewForPlayers is nil it means something anomalous happened while playing the card and so
-				// there is no message sent to clients
-				if handViewForPlayers != nil {
-					respTo := fmt.Sprintf("playCard \"%v\"", c.name)
-					sendCardsPlayedAndTaken(c, msg.CardPlayed, msg.CardsTaken, finalTableTake, g, respTo)
-					sendPlayerViews(c, handViewForPlayers, respTo)
-					sendObserverUpdates(c, handViewForPlayers, respTo, g)
-				}
-			case "closeGame":
-				gameName := msg.GameName
-				c.scopone.Close(gameName, c.name)
-				respTo := fmt.Sprintf("Game \"%v\" closed", gameName)
-				sendGames(c, respTo)
-			default:
-				panicMessage := fmt.Sprintf("Unexpected messageId %v arrived from player %v\n", msg.ID, c.name)
-				panic(panicMessage)
+// processOsteriaCommand processes a command which changes the Osteria as a whole holding the lock of the Osteria
+func (c *client) processOsteriaCommand(ctx context.Context, msg server.MessageFromPlayer) {
+	switch msg.ID {
+	case "playerEntersOsteria":
+		// the player is the player of the account, whatever the name in the message
+		playerName := c.account
+		hv, err := c.scopone.PlayerEnters(ctx, playerName, msg.ReconnectToken)
+		if err != nil {
+			sendError(c, server.ErrorMsgID, playerName, err)
+			return
+		}
+		c.name = playerName
+		c.hub.registerClient <- c
+		// the player who comes back to a game gets a new reconnect token, since a token can be used only once
+		if g, playing := c.scopone.GameOfPlayer(playerName); playing {
+			if token, found := c.scopone.ReconnectToken(playerName); found {
+				sendToClient(c, server.NewReconnectTokenMessage(playerName, g.Name, token))
 			}
 		}
-		processCommandMutex.Unlock()
+		if hv == nil {
+			// if there are no handViews to be sent to Players it means that the Player is entering for the fist time in the Osteria
+			// or he is re-entering but was not playing any game previously
+			respTo := "playerEntersOsteria - no handViews"
+			sendPlayers(c, respTo)
+			sendGames(c, respTo)
+		} else {
+			// on the contrary if the handViews are defined it means that the Player is re-entering the Osteria
+			// and that he was previously playing a game, so we return the handViews to all Players for them
+			// to resume the game
+			respTo := fmt.Sprintf("playerEntersOsteria \"%v\"", playerName)
+			sendGames(c, respTo)
+			sendPlayers(c, respTo)
+			sendPlayerViews(c, hv, respTo)
+		}
+		// the player gets the last messages of the lobby and of the game the player plays
+		channels := []chat.Channel{chat.LobbyChannel}
+		g, playing := c.scopone.GameOfPlayer(playerName)
+		if playing {
+			channels = append(channels, chat.GameChannels(g, playerName)...)
+		}
+		sendChatHistory(c, g, channels)
+	case "newGame":
+		gameName := msg.GameName
+		_, err := c.scopone.NewGame(ctx, gameName, scopone.GameOptions{
+			TargetScore:       msg.TargetScore,
+			Variant:           msg.Variant,
+			NumberOfPlayers:   msg.NumberOfPlayers,
+			Seed:              msg.Seed,
+			MoveTimeLimit:     msg.MoveTimeLimit,
+			ObserverMode:      msg.ObserverMode,
+			ObserverDelay:     msg.ObserverDelay,
+			Password:          msg.GamePassword,
+			Invited:           server.InvitedToNewGame(msg, c.name),
+			NoTeamChatInHands: msg.NoTeamChatInHands,
+		})
+		if err != nil {
+			response := server.NewErrorMessage(server.ErrorMsgID, c.name, err)
+			response.GameName = gameName
+			sendToClient(c, response)
+		} else {
+			// the game may have the name of a game closed, whose chat is not its chat
+			clearGameChat(c, gameName)
+		}
+		respTo := fmt.Sprintf("newGame \"%v\"", gameName)
+		sendGames(c, respTo)
+	}
+}
+
+// sendCardPlayUpdates sends to the players and the observers of the game the updates after a card has been played
+func sendCardPlayUpdates(c *client, playerName string, cardPlayed deck.Card, cardsTaken []deck.Card,
+	finalTableTake scopone.FinalTableTake, handViewForPlayers map[string]scopone.HandPlayerView, g *scopone.Game,
+	respTo string) {
+	sendCardsPlayedAndTaken(c, playerName, cardPlayed, cardsTaken, finalTableTake, g, respTo)
+	sendPlayerViews(c, handViewForPlayers, respTo)
+	sendObserverUpdates(c, handViewForPlayers, respTo, g)
+}
+
+// sendError sends the error returned by a command only to the client which has sent the command
+func sendError(c *client, msgID string, playerName string, err error) {
+	log.Printf("Error processing command of %v: %v", playerName, err)
+	sendToClient(c, server.NewErrorMessage(msgID, playerName, err))
+}
+
+// sendToClient sends a message only to the client
+func sendToClient(c *client, msg server.MessageToOnePlayer) {
+	rsp, e := json.Marshal(msg)
+	if e != nil {
+		panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", msg, e)
+		panic(panicMessage)
 	}
+	c.send <- rsp
 }
+
 func sendPlayers(c *client, responseTo string) {
 	msg := server.NewMessageToAllClients(server.PlayersMsgID)
 	msg.Players = c.scopone.AllPlayers()
 	msg.ResponseTo = responseTo
 	c.hub.broadcastMsg <- messageToAllAsJSON(msg)
 }
+
+// sendGames sends to each player connected the games the player can see, i.e. all the games but the private games
+// the player is not invited to
 func sendGames(c *client, responseTo string) {
-	allGames := c.scopone.AllGames()
-	if len(allGames) > 0 {
+	for _, playerName := range c.hub.clientNames() {
+		games := c.scopone.GamesVisibleTo(playerName)
+		if len(games) == 0 {
+			continue
+		}
 		msg := server.NewMessageToAllClients(server.GamesMsgID)
-		msg.Games = allGames
+		msg.Receiver = playerName
+		msg.Games = games
 		msg.ResponseTo = responseTo
+		if playerClient, connected := c.hub.client(playerName); connected {
+			playerClient.send <- messageToAllAsJSON(msg)
+		}
+	}
+}
+
+// broadcastAbout sends the message about a game to all the clients or, if the game is private, only to the players
+// who can see the game
+func broadcastAbout(c *client, game *scopone.Game, msg server.MessageToAllClients) {
+	if !game.Private {
 		c.hub.broadcastMsg <- messageToAllAsJSON(msg)
+		return
+	}
+	for _, playerName := range c.hub.clientNames() {
+		if !game.VisibleTo(playerName) {
+			continue
+		}
+		if playerClient, connected := c.hub.client(playerName); connected {
+			playerClient.send <- messageToAllAsJSON(msg)
+		}
 	}
 }
+func sendGameFinished(c *client, game *scopone.Game, rspTo string) {
+	msg := server.NewMessageToAllClients(server.GameFinishedMsgID)
+	msg.GameName = game.Name
+	msg.Winners = game.Winners
+	msg.ResponseTo = rspTo
+	broadcastAbout(c, game, msg)
+}
 func sendPlayerLeftOsteria(c *client, playerName string, rspTo string) {
 	msg := server.NewMessageToAllClients(server.PlayerLeftMsgID)
 	msg.PlayerName = playerName
 	msg.ResponseTo = rspTo
 	c.hub.broadcastMsg <- messageToAllAsJSON(msg)
 }
+func sendTurnTimedOut(c *client, playerName string, game *scopone.Game, rspTo string) {
+	msg := server.NewTurnTimedOutMessage(playerName, game.Name)
+	msg.ResponseTo = rspTo
+	broadcastAbout(c, game, msg)
+}
 func messageToAllAsJSON(message server.MessageToAllClients) []byte {
 	b, err := json.Marshal(message)
 	if err != nil {
@@ -238,32 +507,43 @@ func sendPlayerViews(c *client, handViewForPlayers map[string]scopone.HandPlayer
 			panic(panicMessage)
 		}
 
+		// bots have no client to send messages to
+		playerClient, connected := c.hub.client(playerName)
+		if !connected {
+			continue
+		}
 		if c.scopone.Players[playerName].Status == player.PlayerPlaying || c.scopone.Players[playerName].Status == player.PlayerLookingAtHandResult {
-			c.hub.clients[playerName].send <- msgHandViewJ
+			playerClient.send <- msgHandViewJ
 		}
 	}
 }
 func sendObserverUpdates(c *client, handViewForPlayers map[string]scopone.HandPlayerView, responseTo string, game *scopone.Game) {
+	observerViews := replay.ObserverViews(game, handViewForPlayers)
 	for observerName := range game.Observers {
 		msgObsUpdate := server.NewMessageToOnePlayer(server.HandView, observerName)
 		msgObsUpdate.ResponseTo = responseTo
-		msgObsUpdate.AllHandPlayerViews = handViewForPlayers
+		msgObsUpdate.AllHandPlayerViews = observerViews
 		msgObsUpdateJ, e := json.Marshal(msgObsUpdate)
 		if e != nil {
 			panicMessage := fmt.Sprintf("Marshalling to json of %v failed with error %v\n", msgObsUpdate, e)
 			panic(panicMessage)
 		}
-		c.hub.clients[observerName].send <- msgObsUpdateJ
+		if observerClient, connected := c.hub.client(observerName); connected {
+			observerClient.send <- msgObsUpdateJ
+		}
 	}
 }
-func sendCardsPlayedAndTaken(c *client, cardPlayed deck.Card, cardsTaken []deck.Card,
+func sendCardsPlayedAndTaken(c *client, playerName string, cardPlayed deck.Card, cardsTaken []deck.Card,
 	finalTableTake scopone.FinalTableTake, game *scopone.Game, responseTo string) {
 	playerObservers := make([]string, 0)
 	for p := range game.Players {
 		playerObservers = append(playerObservers, p)
 	}
+	// the observers kept behind the players see the cards played with their delayed views
 	for o := range game.Observers {
-		playerObservers = append(playerObservers, o)
+		if game.ObserversSeeMovesLive() {
+			playerObservers = append(playerObservers, o)
+		}
 	}
 	for _, playerObserverName := range playerObservers {
 		msgCardsPlayedAndTaken := server.NewMessageToOnePlayer(server.CardsPlayedAndTaken, playerObserverName)
@@ -271,7 +551,7 @@ func sendCardsPlayedAndTaken(c *client, cardPlayed deck.Card, cardsTaken []deck.
 		msgCardsPlayedAndTaken.CardPlayed = cardPlayed
 		msgCardsPlayedAndTaken.CardsTaken = cardsTaken
 		msgCardsPlayedAndTaken.FinalTableTake = finalTableTake
-		msgCardsPlayedAndTaken.CardPlayedByPlayer = c.name
+		msgCardsPlayedAndTaken.CardPlayedByPlayer = playerName
 		msgCardsPlayedAndTaken.FinalTableTake = finalTableTake
 		msgCardsPlayedAndTakenJ, e := json.Marshal(msgCardsPlayedAndTaken)
 		if e != nil {
@@ -279,7 +559,10 @@ func sendCardsPlayedAndTaken(c *client, cardPlayed deck.Card, cardsTaken []deck.
 			panic(panicMessage)
 		}
 		// ATTENTION PLEASE
-		c.hub.clients[playerObserverName].send <- msgCardsPlayedAndTakenJ
+		// bots have no client to send messages to
+		if playerObserverClient, connected := c.hub.client(playerObserverName); connected {
+			playerObserverClient.send <- msgCardsPlayedAndTakenJ
+		}
 	}
 }
 
@@ -3,15 +3,22 @@
 package srvgorilla
 
 import (
+	"context"
 	// "bytes"
 
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
+	"go-scopone/src/auth"
+	"go-scopone/src/game-logic/actor"
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 
 	"github.com/gorilla/websocket"
 )
@@ -19,6 +26,10 @@ import (
 // https://stackoverflow.com/a/56312831/5699993
 var addr = flag.String("addr", ":8080", "http service address")
 
+// the seed allows to repeat the same sequence of decks, e.g. to reproduce a bug - the decks can then be predicted, so
+// it must not be used for real games
+var seed = flag.Int64("seed", 0, "seed of the sequence of the decks, which makes them predictable - if 0 the decks are shuffled from a crypto-secure source")
+
 const (
 	// Time allowed to write a message to the peer.
 	writeWait = 10 * time.Second
@@ -29,6 +40,9 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
+	// Time allowed to a command of a player, including the writes of the store.
+	commandTimeout = 30 * time.Second
+
 	// Maximum message size allowed from peer.
 	// maxMessageSize = 512
 )
@@ -46,8 +60,10 @@ var upgrader = websocket.Upgrader{
 // Hub maintains the set of active clients and broadcasts messages to the
 // clients.
 type Hub struct {
-	broadcastMsg     chan []byte
-	clients          map[string]*client
+	broadcastMsg chan []byte
+	clients      map[string]*client
+	// clientsMutex guards the clients, which are changed by the hub and read by the commands of all the games
+	clientsMutex     sync.RWMutex
 	registerClient   chan *client
 	unregisterClient chan *client
 }
@@ -65,6 +81,7 @@ func (h *Hub) run() {
 	for {
 		select {
 		case message := <-h.broadcastMsg:
+			h.clientsMutex.Lock()
 			for k, client := range h.clients {
 				select {
 				case client.send <- message:
@@ -73,23 +90,62 @@ func (h *Hub) run() {
 					delete(h.clients, k)
 				}
 			}
+			h.clientsMutex.Unlock()
 		case c := <-h.registerClient:
+			h.clientsMutex.Lock()
 			h.clients[c.name] = c
+			h.clientsMutex.Unlock()
 		case client := <-h.unregisterClient:
+			h.clientsMutex.Lock()
 			if _, ok := h.clients[client.name]; ok {
 				delete(h.clients, client.name)
 				close(client.send)
 				log.Printf("Connection closed - Name: %v", client.name)
 			}
+			h.clientsMutex.Unlock()
 		}
 	}
 }
 
+// client returns the client of the player with the name passed in, if the player is connected
+func (h *Hub) client(name string) (*client, bool) {
+	h.clientsMutex.RLock()
+	defer h.clientsMutex.RUnlock()
+	c, connected := h.clients[name]
+	return c, connected
+}
+
+// clientNames returns the names of the players connected
+func (h *Hub) clientNames() []string {
+	h.clientsMutex.RLock()
+	defer h.clientsMutex.RUnlock()
+	names := make([]string, 0, len(h.clients))
+	for name := range h.clients {
+		names = append(names, name)
+	}
+	return names
+}
+
 // ServeOsteria handles websocket requests from the Players that want to play in the Osteria.
-func serveOsteria(hub *Hub, scopone *scopone.Scopone, w http.ResponseWriter, r *http.Request) {
+// A request with a session token, see auth.TokenFromRequest, opens a connection for the player of the token and is
+// refused if the token is not valid, while a request without token opens a connection on which the player has to
+// log in before sending any other command
+func serveOsteria(hub *Hub, scopone *scopone.Scopone, games *actor.Osteria, chats *chat.Chat, accounts *auth.Accounts,
+	w http.ResponseWriter, r *http.Request) {
 	// just assume the origin is OK - security happiness
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
+	var account string
+	if token := auth.TokenFromRequest(r); token != "" {
+		var err error
+		account, err = accounts.Verify(token)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
@@ -100,7 +156,8 @@ func serveOsteria(hub *Hub, scopone *scopone.Scopone, w http.ResponseWriter, r *
 	conn.SetReadDeadline(time.Now().Add(pongWait))
 	conn.SetPongHandler(func(string) error { conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 
-	client := &client{hub: hub, conn: conn, send: make(chan []byte, 256), scopone: scopone}
+	client := &client{hub: hub, conn: conn, send: make(chan []byte, 256), scopone: scopone, games: games,
+		chats: chats, accounts: accounts, account: account}
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
@@ -109,7 +166,9 @@ func serveOsteria(hub *Hub, scopone *scopone.Scopone, w http.ResponseWriter, r *
 }
 
 // Start the server
-func Start(playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter) {
+// The session tokens are signed with the secret of the env var auth.SecretEnvVar - if it is not set the tokens are
+// signed with a random secret and are not valid any more after a restart
+func Start(playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter, accountStore auth.AccountStore) {
 	fmt.Println("Server started")
 	flag.Parse()
 
@@ -118,10 +177,28 @@ func Start(playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter) {
 	hub := newHub()
 	go hub.run()
 
-	scopone := scopone.New(playerStore, gameStore)
+	scopone := scopone.New(context.Background(), playerStore, gameStore)
+	bot.RestoreBots(scopone)
+	if *seed != 0 {
+		scopone.Shuffler = deck.NewSeededShuffler(*seed)
+	}
+	// the players whose turn expires play the move the heuristic bot would play
+	scopone.TimeoutStrategy = &bot.Heuristic{}
+
+	games := actor.New(scopone)
+	defer games.Stop()
+	// the messages of the chat are kept only in memory and are lost when the server is restarted
+	chats := chat.New(chat.NewMemoryStore(), chat.DefaultRateLimit)
+	games.RunTimers(turnTimers(hub, scopone, games, chats))
+
+	secret, fromEnv := auth.SecretFromEnv()
+	if !fromEnv {
+		log.Printf("%v is not set - the session tokens are valid only until the server is restarted", auth.SecretEnvVar)
+	}
+	accounts := auth.NewAccounts(accountStore, auth.NewTokens(secret, auth.DefaultTokenLifetime))
 
 	http.HandleFunc("/osteria", func(w http.ResponseWriter, r *http.Request) {
-		serveOsteria(hub, scopone, w, r)
+		serveOsteria(hub, scopone, games, chats, accounts, w, r)
 	})
 
 	err := http.ListenAndServe(*addr, nil)
@@ -130,6 +207,23 @@ func Start(playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter) {
 	}
 }
 
+// turnTimers returns the function which sends to the games the commands of the timers of the turns - the players are
+// told what has happened as if the commands were sent by a client, which has no connection since nobody has sent them
+func turnTimers(hub *Hub, scopone *scopone.Scopone, games *actor.Osteria, chats *chat.Chat) actor.Timers {
+	c := &client{hub: hub, scopone: scopone, games: games, chats: chats}
+	return func(gameName string, command actor.Command) {
+		respTo := fmt.Sprintf("turnTimer - game \"%v\"", gameName)
+		changes := &osteriaChanges{}
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		err := games.Do(ctx, gameName, command, c.eventHandler(respTo, changes))
+		if err != nil {
+			log.Printf("The timer of the turn of game %v failed: %v", gameName, err)
+		}
+		c.sendOsteriaChanges(changes, respTo)
+	}
+}
+
 func homePage(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Home Page")
 }
@@ -5,7 +5,9 @@ import (
 	"log"
 	"net/http"
 
+	"go-scopone/src/auth"
 	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 	"go-scopone/src/server/srvlambda/lambdamongo"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -15,14 +17,20 @@ type connectionStorer interface {
 	ActiveConnectionIDs(ctx context.Context) ([]string, error)
 	ConnectedPlayers(ctx context.Context) ([]string, error)
 	ConnectionIDForPlayer(ctx context.Context, playerName string) (string, error)
+	PlayerForConnectionID(ctx context.Context, connectionID string) (string, error)
 	AddConnectionID(ctx context.Context, connectionID string) error
 	AddPlayerToConnectionID(ctx context.Context, connectionID string, playerName string) error
 	MarkConnectionIDDisconnected(ctx context.Context, connectionID string) error
+	SeatTokens(ctx context.Context) (map[string]scopone.SeatToken, error)
+	WriteSeatTokens(ctx context.Context, tokens map[string]scopone.SeatToken) error
+	MarkSeatLeft(ctx context.Context, playerName string) error
 }
 
 var connectionStore connectionStorer
 var playerStore scopone.PlayerWriter
 var gameStore scopone.GameReadWriter
+var accounts *auth.Accounts
+var chats *chat.Chat
 
 func handleRequest(ctx context.Context, event events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log.Println("Lambda Handle Request started")
@@ -32,13 +40,39 @@ func handleRequest(ctx context.Context, event events.APIGatewayWebsocketProxyReq
 		connectionStore = store
 		playerStore = store
 		gameStore = store
+		// each instance of the function has to verify the tokens issued by the others, so the secret can not be
+		// a random one
+		secret, fromEnv := auth.SecretFromEnv()
+		if !fromEnv {
+			log.Fatalf("%v must be set to sign the session tokens", auth.SecretEnvVar)
+		}
+		accounts = auth.NewAccounts(store, auth.NewTokens(secret, auth.DefaultTokenLifetime))
+		// the messages of the chat are kept in the store, since the instances of the function share its channels
+		// and its rate limits
+		chats = chat.New(store, chat.DefaultRateLimit)
 	}
 
 	rc := event.RequestContext
 	switch rk := rc.RouteKey; rk {
 	case "$connect":
 		log.Println("Connect", rc.ConnectionID)
+		// a connection opened with a session token is of the player of the token, and is refused if the token is
+		// not valid, while a connection without token has to log in before sending any other command
+		var playerName string
+		if token := event.QueryStringParameters["token"]; token != "" {
+			var err error
+			playerName, err = accounts.Verify(token)
+			if err != nil {
+				log.Println("Connection refused", rc.ConnectionID, err)
+				return events.APIGatewayProxyResponse{
+					StatusCode: http.StatusUnauthorized,
+				}, nil
+			}
+		}
 		err := connectionStore.AddConnectionID(ctx, rc.ConnectionID)
+		if err == nil && playerName != "" {
+			err = connectionStore.AddPlayerToConnectionID(ctx, rc.ConnectionID, playerName)
+		}
 		if err != nil {
 			return events.APIGatewayProxyResponse{
 				StatusCode: http.StatusInternalServerError,
@@ -46,15 +80,22 @@ func handleRequest(ctx context.Context, event events.APIGatewayWebsocketProxyReq
 		}
 	case "$disconnect":
 		log.Println("Disconnect", rc.ConnectionID)
-		err := connectionStore.MarkConnectionIDDisconnected(ctx, rc.ConnectionID)
+		playerName, err := connectionStore.PlayerForConnectionID(ctx, rc.ConnectionID)
+		if err == nil && playerName != "" {
+			// the grace period of the seat of the player, if any, starts when the connection is closed
+			err = connectionStore.MarkSeatLeft(ctx, playerName)
+		}
+		if err == nil {
+			err = connectionStore.MarkConnectionIDDisconnected(ctx, rc.ConnectionID)
+		}
 		if err != nil {
 			return events.APIGatewayProxyResponse{
 				StatusCode: http.StatusInternalServerError,
 			}, err
 		}
 	case "$default":
-		log.Println("Default - Handle Commands", rc.ConnectionID, event.Body)
-		err := handleCommand(ctx, event, connectionStore, playerStore, gameStore)
+		log.Println("Default - Handle Commands", rc.ConnectionID)
+		err := handleCommand(ctx, event, connectionStore, playerStore, gameStore, accounts, chats)
 		if err != nil {
 			return events.APIGatewayProxyResponse{
 				StatusCode: http.StatusInternalServerError,
@@ -6,11 +6,14 @@ import (
 	"log"
 	"time"
 
+	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 	"go-scopone/src/store/storemongo"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Store is the mongodb reference
@@ -26,6 +29,8 @@ func Connect(ctx context.Context) *Store {
 
 const (
 	connectionsCollName = "connections"
+	seatTokensCollName  = "seatTokens"
+	chatCollName        = "chatMessages"
 	connActive          = "active"
 	connClosed          = "closed"
 )
@@ -103,6 +108,16 @@ func (store *Store) ConnectionIDForPlayer(ctx context.Context, playerName string
 	return elem.ConnectionID, err
 }
 
+// PlayerForConnectionID returns the player of a connectionID - the player is empty if the connection has not
+// logged in yet
+func (store *Store) PlayerForConnectionID(ctx context.Context, connectionID string) (string, error) {
+	collection := store.Store.GetDb().Collection(connectionsCollName)
+	filter := bson.D{primitive.E{Key: "connectionid", Value: connectionID}}
+	var elem connectionIDEntry
+	err := collection.FindOne(ctx, filter).Decode(&elem)
+	return elem.PlayerName, err
+}
+
 // AddConnectionID adds a record representing a connectionId
 func (store *Store) AddConnectionID(ctx context.Context, connectionID string) error {
 	entry := connectionIDEntry{}
@@ -139,3 +154,105 @@ func (store *Store) MarkConnectionIDDisconnected(ctx context.Context, connection
 	_, err := collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// seatTokenEntry is the reconnect token of the seat of a player, which the Osteria of each request has to be
+// given back since the Osteria is not kept in memory
+type seatTokenEntry struct {
+	PlayerName string
+	Token      string
+	Left       time.Time
+}
+
+// SeatTokens returns the reconnect tokens of the seats, with the name of the player as key
+func (store *Store) SeatTokens(ctx context.Context) (map[string]scopone.SeatToken, error) {
+	collection := store.Store.GetDb().Collection(seatTokensCollName)
+	cur, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	tokens := make(map[string]scopone.SeatToken)
+	for cur.Next(ctx) {
+		var elem seatTokenEntry
+		err := cur.Decode(&elem)
+		if err != nil {
+			return nil, err
+		}
+		tokens[elem.PlayerName] = scopone.SeatToken{Token: elem.Token, Left: elem.Left}
+	}
+	return tokens, cur.Err()
+}
+
+// WriteSeatTokens saves the reconnect tokens of the seats, removing the tokens of the seats which no longer have one
+func (store *Store) WriteSeatTokens(ctx context.Context, tokens map[string]scopone.SeatToken) error {
+	collection := store.Store.GetDb().Collection(seatTokensCollName)
+	names := make([]string, 0, len(tokens))
+	for pName, st := range tokens {
+		names = append(names, pName)
+		filter := bson.D{primitive.E{Key: "playername", Value: pName}}
+		update := bson.M{"$set": seatTokenEntry{PlayerName: pName, Token: st.Token, Left: st.Left}}
+		_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := collection.DeleteMany(ctx, bson.M{"playername": bson.M{"$nin": names}})
+	return err
+}
+
+// MarkSeatLeft registers that the player has left the seat, which starts its grace period - nothing happens if the
+// player has no seat
+func (store *Store) MarkSeatLeft(ctx context.Context, playerName string) error {
+	collection := store.Store.GetDb().Collection(seatTokensCollName)
+	filter := bson.D{primitive.E{Key: "playername", Value: playerName}}
+	update := bson.M{
+		"$set": bson.M{"left": time.Now()},
+	}
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// AddMessage adds a message to its channel of the chat
+func (store *Store) AddMessage(ctx context.Context, msg chat.Message) error {
+	collection := store.Store.GetDb().Collection(chatCollName)
+	_, err := collection.InsertOne(ctx, msg)
+	return err
+}
+
+// Messages returns the last messages of the channel of the chat, from the oldest one
+func (store *Store) Messages(ctx context.Context, channel chat.Channel, last int) ([]chat.Message, error) {
+	collection := store.Store.GetDb().Collection(chatCollName)
+	opts := options.Find().SetSort(bson.M{"sent": -1}).SetLimit(int64(last))
+	cur, err := collection.Find(ctx, bson.M{"channel": channel}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	messages := make([]chat.Message, 0)
+	for cur.Next(ctx) {
+		var msg chat.Message
+		if err := cur.Decode(&msg); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	// the messages are read from the last one
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, cur.Err()
+}
+
+// SentSince returns the number of messages of the chat sent by the player since the time passed in
+func (store *Store) SentSince(ctx context.Context, sender string, since time.Time) (int, error) {
+	collection := store.Store.GetDb().Collection(chatCollName)
+	count, err := collection.CountDocuments(ctx, bson.M{"sender": sender, "sent": bson.M{"$gte": since}})
+	return int(count), err
+}
+
+// ClearGame removes the messages of the table and of the team channels of the game
+func (store *Store) ClearGame(ctx context.Context, gameName string) error {
+	collection := store.Store.GetDb().Collection(chatCollName)
+	_, err := collection.DeleteMany(ctx, bson.M{"channel.kind": bson.M{"$ne": chat.Lobby}, "channel.gamename": gameName})
+	return err
+}
@@ -0,0 +1,54 @@
+package lambdamongo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go-scopone/src/store/storemongo"
+	"go-scopone/src/store/storetest"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// the tests need mongo and are skipped if the env var MONGO_CONNECTION is not set
+func TestConformance(t *testing.T) {
+	connString := os.Getenv("MONGO_CONNECTION")
+	if connString == "" {
+		t.Skip("MONGO_CONNECTION is not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connString))
+	if err != nil {
+		t.Fatalf("Mongo could not be reached: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		db := client.Database(fmt.Sprintf("scopone_test_%v", time.Now().UnixNano()))
+		t.Cleanup(func() { db.Drop(ctx) })
+		return func(t *testing.T) storetest.Store {
+			return &Store{storemongo.New(db)}
+		}
+	})
+}
+
+func TestChatConformance(t *testing.T) {
+	connString := os.Getenv("MONGO_CONNECTION")
+	if connString == "" {
+		t.Skip("MONGO_CONNECTION is not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connString))
+	if err != nil {
+		t.Fatalf("Mongo could not be reached: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	db := client.Database(fmt.Sprintf("scopone_test_%v", time.Now().UnixNano()))
+	t.Cleanup(func() { db.Drop(ctx) })
+	storetest.RunChat(t, &Store{storemongo.New(db)})
+}
@@ -3,12 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
+	"go-scopone/src/auth"
+	"go-scopone/src/game-logic/actor"
+	"go-scopone/src/game-logic/bot"
 	"go-scopone/src/game-logic/deck"
 	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/replay"
 	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/server/chat"
 	server "go-scopone/src/server/messages"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -35,11 +41,8 @@ func buildApigateway(event events.APIGatewayWebsocketProxyRequest) *apigatewayma
 }
 
 func handleCommand(ctx context.Context, event events.APIGatewayWebsocketProxyRequest,
-	connectionStore connectionStorer, playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter) error {
-
-	scopone := scopone.New(playerStore, gameStore)
-	adjustPlayers(ctx, scopone)
-	setGamesStatus(scopone)
+	connectionStore connectionStorer, playerStore scopone.PlayerWriter, gameStore scopone.GameReadWriter,
+	accounts *auth.Accounts, chats *chat.Chat) error {
 
 	buildApigateway(event)
 	connectionID := event.RequestContext.ConnectionID
@@ -47,113 +50,228 @@ func handleCommand(ctx context.Context, event events.APIGatewayWebsocketProxyReq
 	// convert data received to MessageFromPlayer struct
 	var msg server.MessageFromPlayer
 	if err := json.Unmarshal([]byte(event.Body), &msg); err != nil {
-		log.Fatalln(err.Error())
+		sendError(ctx, server.ErrorMsgID, "", fmt.Errorf("Message %v can not be read: %v", event.Body, err), connectionID)
+		return nil
 	}
-	log.Println("Message received", msg)
+	log.Println("Message received", msg.Redacted())
 
 	gameName := msg.GameName
-	playerName := msg.PlayerName
+	// the player is the player of the account the connection has logged in with, whatever the name in the message
+	playerName, err := connectionStore.PlayerForConnectionID(ctx, connectionID)
+	if err != nil {
+		return err
+	}
+	if msg.ID == "register" || msg.ID == "login" {
+		return processAccountCommand(ctx, accounts, msg, playerName, connectionID, connectionStore)
+	}
+	if playerName == "" {
+		sendError(ctx, server.ErrorMsgID, msg.PlayerName,
+			fmt.Errorf("%w - Message %v can be sent only after the login", auth.ErrNotAuthenticated, msg.ID), connectionID)
+		return nil
+	}
+
+	osteria := scopone.New(ctx, playerStore, gameStore)
+	// the player entering the Osteria is already connected, since the connection is of the player since the login,
+	// but is not in the Osteria yet
+	entering := ""
+	if msg.ID == "playerEntersOsteria" {
+		entering = playerName
+	}
+	adjustPlayers(ctx, osteria, entering)
+	bot.RestoreBots(osteria)
+	// the players whose turn expires play the move the heuristic bot would play
+	osteria.TimeoutStrategy = &bot.Heuristic{}
+	setGamesStatus(osteria)
+	err = restoreSeatTokens(ctx, osteria, connectionStore)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := connectionStore.WriteSeatTokens(ctx, osteria.SeatTokens()); err != nil {
+			log.Printf("The reconnect tokens of the seats could not be saved: %v", err)
+		}
+	}()
 
 	switch msg.ID {
 	case "playerEntersOsteria":
-		err := connectionStore.AddPlayerToConnectionID(ctx, connectionID, playerName)
+		handViewForPlayers, err := osteria.PlayerEnters(ctx, playerName, msg.ReconnectToken)
 		if err != nil {
-			log.Fatalf("Player %v could not be added to its connection", playerName)
-		}
-		handViewForPlayers, alreadyIn := scopone.PlayerEnters(playerName)
-		if alreadyIn {
-			// Player is already in the osteria
-			err := connectionStore.MarkConnectionIDDisconnected(ctx, connectionID)
-			if err != nil {
-				panic(err)
+			if errors.Is(err, scopone.ErrPlayerAlreadyInOsteria) {
+				// Player is already in the osteria
+				e := connectionStore.MarkConnectionIDDisconnected(ctx, connectionID)
+				if e != nil {
+					log.Printf("Connection %v could not be marked as disconnected: %v", connectionID, e)
+				}
 			}
-			resp := server.NewMessageToOnePlayer(server.PlayerIsAlreadyInOsteria, playerName)
-			resp.Error = fmt.Sprintf("Player \"%v\" is already in the Osteria", playerName)
-			sendMessage(ctx, resp, &connectionID)
-		} else {
-			if handViewForPlayers == nil {
-				// if there are no handViews to be sent to Players it means that the Player is entering for the fist time in the Osteria
-				// or he is re-entering but was not playing any game previously
-				respTo := "playerEntersOsteria - no handViews"
-				sendPlayers(ctx, scopone, respTo, connectionStore)
-				sendGames(ctx, scopone, respTo, connectionStore)
-			} else {
-				// on the contrary if the handViews are defined it means that the Player is re-entering the Osteria
-				// and that he was previously playing a game, so we return the handViews to all Players for them
-				// to resume the game
-				respTo := fmt.Sprintf("playerEntersOsteria \"%v\"", playerName)
-				sendGames(ctx, scopone, respTo, connectionStore)
-				sendPlayers(ctx, scopone, respTo, connectionStore)
-				sendPlayerViews(ctx, scopone, handViewForPlayers, respTo, connectionStore)
+			sendError(ctx, server.ErrorMsgID, playerName, err, connectionID)
+			return nil
+		}
+		// the player who comes back to a game gets a new reconnect token, since a token can be used only once
+		if g, playing := osteria.GameOfPlayer(playerName); playing {
+			if token, found := osteria.ReconnectToken(playerName); found {
+				sendMessage(ctx, server.NewReconnectTokenMessage(playerName, g.Name, token), &connectionID)
 			}
 		}
+		if handViewForPlayers == nil {
+			// if there are no handViews to be sent to Players it means that the Player is entering for the fist time in the Osteria
+			// or he is re-entering but was not playing any game previously
+			respTo := "playerEntersOsteria - no handViews"
+			sendPlayers(ctx, osteria, respTo, connectionStore)
+			sendGames(ctx, osteria, respTo, connectionStore)
+		} else {
+			// on the contrary if the handViews are defined it means that the Player is re-entering the Osteria
+			// and that he was previously playing a game, so we return the handViews to all Players for them
+			// to resume the game
+			respTo := fmt.Sprintf("playerEntersOsteria \"%v\"", playerName)
+			sendGames(ctx, osteria, respTo, connectionStore)
+			sendPlayers(ctx, osteria, respTo, connectionStore)
+			sendPlayerViews(ctx, osteria, handViewForPlayers, respTo, connectionStore)
+		}
+		// the player gets the last messages of the lobby and of the game the player plays
+		channels := []chat.Channel{chat.LobbyChannel}
+		g, playing := osteria.GameOfPlayer(playerName)
+		if playing {
+			channels = append(channels, chat.GameChannels(g, playerName)...)
+		}
+		sendChatHistory(ctx, chats, playerName, g, channels, connectionStore)
 	case "newGame":
-		_, e := scopone.NewGame(gameName)
-		if e != nil {
-			// There is already a game with the same name
-			resp := server.NewMessageToOnePlayer(server.GameWithSameNamePresent, playerName)
-			resp.Error = fmt.Sprintf("Game \"%v\" with the same name already created", gameName)
+		_, err := osteria.NewGame(ctx, gameName, scopone.GameOptions{
+			TargetScore:       msg.TargetScore,
+			Variant:           msg.Variant,
+			NumberOfPlayers:   msg.NumberOfPlayers,
+			Seed:              msg.Seed,
+			MoveTimeLimit:     msg.MoveTimeLimit,
+			ObserverMode:      msg.ObserverMode,
+			ObserverDelay:     msg.ObserverDelay,
+			Password:          msg.GamePassword,
+			Invited:           server.InvitedToNewGame(msg, playerName),
+			NoTeamChatInHands: msg.NoTeamChatInHands,
+		})
+		if err != nil {
+			resp := server.NewErrorMessage(server.ErrorMsgID, playerName, err)
 			resp.GameName = gameName
 			sendMessage(ctx, resp, &connectionID)
+		} else {
+			// the game may have the name of a game closed, whose chat is not its chat
+			clearGameChat(ctx, chats, gameName)
 		}
 		respTo := fmt.Sprintf("newGame \"%v\"", gameName)
-		sendGames(ctx, scopone, respTo, connectionStore)
-	case "addPlayerToGame":
-		e := scopone.AddPlayerToGame(playerName, gameName)
-		if e != nil {
-			resp := server.NewMessageToOnePlayer(server.ErrorAddingPlayerToGameMsgID, playerName)
-			resp.Error = e.Error()
-			sendMessage(ctx, resp, &connectionID)
-		} else {
-			respTo := fmt.Sprintf("addPlayerToGame - game \"%v\"", gameName)
-			sendGames(ctx, scopone, respTo, connectionStore)
+		sendGames(ctx, osteria, respTo, connectionStore)
+	case "chat":
+		sendChat(ctx, osteria, chats, playerName, msg, connectionID, connectionStore)
+	case "replayHand":
+		game, found := osteria.Games[gameName]
+		if !found {
+			sendError(ctx, server.ErrorReplayingHandMsgID, playerName,
+				fmt.Errorf("%w - There is no Game with name %v", scopone.ErrGameNotFound, gameName), connectionID)
+			return nil
 		}
-	case "addObserverToGame":
-		playerName := msg.PlayerName
-		gameName := msg.GameName
-		hv, err := scopone.AddObserverToGame(playerName, gameName)
+		resp, err := server.NewHandReplayMessage(game, playerName, msg.HandIndex)
 		if err != nil {
-			resp := server.NewMessageToOnePlayer(server.ErrorAddingObserverToGameMsgID, playerName)
-			resp.Error = err.Error()
-			sendMessage(ctx, resp, &connectionID)
-		} else {
-			respTo := fmt.Sprintf("addObserverToGame - game \"%v\"", gameName)
-			sendGames(ctx, scopone, respTo, connectionStore)
-			game := scopone.Games[gameName]
-			sendObserverUpdates(ctx, scopone, hv, respTo, game, connectionStore)
-		}
-	case "newHand":
-		game := scopone.Games[gameName]
-		_, handViewForPlayers, handCreated := scopone.NewHand(game)
-		if handCreated {
-			respTo := fmt.Sprintf("newHand - game \"%v\"", gameName)
-			fmt.Println("NewHand", gameName, len(handViewForPlayers))
-			sendGames(ctx, scopone, respTo, connectionStore)
-			sendPlayerViews(ctx, scopone, handViewForPlayers, respTo, connectionStore)
-			sendObserverUpdates(ctx, scopone, handViewForPlayers, respTo, game, connectionStore)
-		}
-	case "playCard":
-		handViewForPlayers, finalTableTake, g := scopone.Play(msg.PlayerName, msg.CardPlayed, msg.CardsTaken)
-		// if handViewForPlayers is nil it means something anomalous happened while playing the card and so
-		// there is no message sent to clients
-		if handViewForPlayers != nil {
-			respTo := fmt.Sprintf("playCard \"%v\"", playerName)
-			sendCardsPlayedAndTaken(ctx, msg.CardPlayed, msg.CardsTaken, finalTableTake, g, playerName, respTo, connectionStore)
-			sendPlayerViews(ctx, scopone, handViewForPlayers, respTo, connectionStore)
-			sendObserverUpdates(ctx, scopone, handViewForPlayers, respTo, g, connectionStore)
-		}
-	case "closeGame":
-		gameName := msg.GameName
-		scopone.Close(gameName, playerName)
-		respTo := fmt.Sprintf("Game \"%v\" closed", gameName)
-		sendGames(ctx, scopone, respTo, connectionStore)
+			sendError(ctx, server.ErrorReplayingHandMsgID, playerName, err, connectionID)
+			return nil
+		}
+		resp.ResponseTo = fmt.Sprintf("replayHand - game \"%v\"", gameName)
+		sendMessage(ctx, resp, &connectionID)
 	default:
-		panicMessage := fmt.Sprintf("Unexpected messageId %v arrived from player %v\n", msg.ID, playerName)
-		log.Fatal(panicMessage)
+		command, errorMsgID, isGameCommand, err := server.GameCommand(msg, playerName)
+		if !isGameCommand {
+			err = fmt.Errorf("Unexpected messageId %v arrived from player %v", msg.ID, playerName)
+		}
+		if err != nil {
+			sendError(ctx, errorMsgID, playerName, err, connectionID)
+			return nil
+		}
+		games := actor.New(osteria)
+		defer games.Stop()
+		respTo := fmt.Sprintf("%v - game \"%v\"", msg.ID, gameName)
+		err = games.Do(ctx, gameName, command, eventHandler(ctx, osteria, chats, respTo, connectionID, connectionStore))
+		if errors.Is(err, scopone.ErrHandStillActive) {
+			// another player has already started the new hand, so there is nothing to do
+			return nil
+		}
+		if err != nil {
+			// if the command has been executed but the game could not be saved, the error is sent after the updates
+			log.Printf("Error processing command of %v: %v", playerName, err)
+			sendMessage(ctx, server.NewCommandErrorMessage(msg, errorMsgID, playerName, err), &connectionID)
+		}
 	}
 	return nil
 }
 
+// eventHandler returns the handler which sends to the players the updates for the events of a command
+func eventHandler(ctx context.Context, osteria *scopone.Scopone, chats *chat.Chat, respTo string, connectionID string,
+	store connectionStorer) actor.Handler {
+	return func(ev actor.Event) {
+		switch e := ev.(type) {
+		case actor.PlayerJoined:
+			if e.Bot || e.Replaced != "" {
+				sendPlayers(ctx, osteria, respTo, store)
+			}
+			sendGames(ctx, osteria, respTo, store)
+			if e.ReconnectToken != "" {
+				sendMessage(ctx, server.NewReconnectTokenMessage(e.PlayerName, e.Game().Name, e.ReconnectToken), &connectionID)
+			}
+			if e.HandViews != nil {
+				// the player who has taken the seat of another player goes on with the cards of the seat
+				sendPlayerViews(ctx, osteria, e.HandViews, respTo, store)
+				sendObserverUpdates(ctx, osteria, e.HandViews, respTo, e.Game(), store)
+			}
+			if !e.Bot {
+				sendChatHistory(ctx, chats, e.PlayerName, e.Game(), chat.GameChannels(e.Game(), e.PlayerName), store)
+			}
+		case actor.SeatsChanged, actor.PlayerReady, actor.AbandonVoted:
+			sendGames(ctx, osteria, respTo, store)
+		case actor.PlayerKicked, actor.SeatLeft:
+			sendPlayers(ctx, osteria, respTo, store)
+			sendGames(ctx, osteria, respTo, store)
+		case actor.ObserverJoined:
+			sendGames(ctx, osteria, respTo, store)
+			sendObserverUpdates(ctx, osteria, e.HandViews, respTo, e.Game(), store)
+			sendChatHistory(ctx, chats, e.ObserverName, e.Game(), chat.GameChannels(e.Game(), e.ObserverName), store)
+		case actor.HandStarted:
+			fmt.Println("NewHand", e.Game().Name, len(e.HandViews))
+			sendGames(ctx, osteria, respTo, store)
+			sendPlayerViews(ctx, osteria, e.HandViews, respTo, store)
+			sendObserverUpdates(ctx, osteria, e.HandViews, respTo, e.Game(), store)
+		case actor.CardPlayed:
+			sendCardPlayUpdates(ctx, osteria, e.PlayerName, e.CardPlayed, e.CardsTaken, e.FinalTableTake, e.HandViews,
+				e.Game(), fmt.Sprintf("playCard \"%v\"", e.PlayerName), store)
+		case actor.GameFinished:
+			sendGameFinished(ctx, e.Game(), respTo, store)
+			sendGames(ctx, osteria, respTo, store)
+		case actor.TurnWarning:
+			msg := server.NewTurnWarningMessage(e.PlayerName, e.Game().Name, e.RemainingTime)
+			msg.ResponseTo = respTo
+			sendToPlayer(ctx, msg, store)
+		case actor.TurnTimedOut:
+			msg := server.NewTurnTimedOutMessage(e.PlayerName, e.Game().Name)
+			msg.ResponseTo = respTo
+			broadcastAbout(ctx, e.Game(), msg, store)
+		case actor.GameSuspended:
+			sendGames(ctx, osteria, respTo, store)
+		case actor.GameClosed:
+			sendGames(ctx, osteria, respTo, store)
+			clearGameChat(ctx, chats, e.Game().Name)
+		}
+	}
+}
+
+// sendCardPlayUpdates sends to the players and the observers of the game the updates after a card has been played
+func sendCardPlayUpdates(ctx context.Context, osteria *scopone.Scopone, playerName string, cardPlayed deck.Card,
+	cardsTaken []deck.Card, finalTableTake scopone.FinalTableTake, handViewForPlayers map[string]scopone.HandPlayerView,
+	g *scopone.Game, respTo string, store connectionStorer) {
+	sendCardsPlayedAndTaken(ctx, cardPlayed, cardsTaken, finalTableTake, g, playerName, respTo, store)
+	sendPlayerViews(ctx, osteria, handViewForPlayers, respTo, store)
+	sendObserverUpdates(ctx, osteria, handViewForPlayers, respTo, g, store)
+}
+
+// sendError sends the error returned by a command only to the connection which has sent the command
+func sendError(ctx context.Context, msgID string, playerName string, err error, connectionID string) {
+	log.Printf("Error processing command of %v: %v", playerName, err)
+	sendMessage(ctx, server.NewErrorMessage(msgID, playerName, err), &connectionID)
+}
+
 func buildMessage(msg interface{}) []byte {
 	msgB, e := json.Marshal(msg)
 	if e != nil {
@@ -170,14 +288,55 @@ func sendPlayers(ctx context.Context, scopone *scopone.Scopone, responseTo strin
 	broadcast(ctx, msg, store)
 }
 
+// sendGames sends to each player connected the games the player can see, i.e. all the games but the private games
+// the player is not invited to
 func sendGames(ctx context.Context, scopone *scopone.Scopone, responseTo string, store connectionStorer) {
-	allGames := scopone.AllGames()
-	if len(allGames) > 0 {
+	connectedPlayers, err := store.ConnectedPlayers(ctx)
+	if err != nil {
+		log.Println("Unable to get the connected players", err.Error())
+		return
+	}
+	for _, playerName := range connectedPlayers {
+		if playerName == "" {
+			continue
+		}
+		games := scopone.GamesVisibleTo(playerName)
+		if len(games) == 0 {
+			continue
+		}
 		msg := server.NewMessageToAllClients(server.GamesMsgID)
-		msg.Games = allGames
+		msg.Receiver = playerName
+		msg.Games = games
 		msg.ResponseTo = responseTo
+		postToPlayer(ctx, playerName, msg, store)
+	}
+}
+
+// broadcastAbout sends the message about a game to all the connections or, if the game is private, only to the
+// players who can see the game
+func broadcastAbout(ctx context.Context, game *scopone.Game, msg server.MessageToAllClients, store connectionStorer) {
+	if !game.Private {
 		broadcast(ctx, msg, store)
+		return
+	}
+	connectedPlayers, err := store.ConnectedPlayers(ctx)
+	if err != nil {
+		log.Println("Unable to get the connected players", err.Error())
+		return
 	}
+	for _, playerName := range connectedPlayers {
+		if playerName != "" && game.VisibleTo(playerName) {
+			postToPlayer(ctx, playerName, msg, store)
+		}
+	}
+}
+
+func sendGameFinished(ctx context.Context, game *scopone.Game, responseTo string, store connectionStorer) {
+	msg := server.NewMessageToAllClients(server.GameFinishedMsgID)
+	msg.GameName = game.Name
+	msg.Winners = game.Winners
+	msg.ResponseTo = responseTo
+	broadcastAbout(ctx, game, msg, store)
 }
 
 func sendPlayerViews(ctx context.Context, scopone *scopone.Scopone,
@@ -187,6 +346,10 @@ func sendPlayerViews(ctx context.Context, scopone *scopone.Scopone,
 		msg := server.NewMessageToOnePlayer(server.HandView, playerName)
 		msg.ResponseTo = responseTo
 		msg.HandPlayerView = hView
+		// bots have no connection to send messages to
+		if scopone.Players[playerName].Bot != "" {
+			continue
+		}
 		connectionID, err := store.ConnectionIDForPlayer(ctx, playerName)
 		if err != nil {
 			log.Printf("Connection for player %v not found", playerName)
@@ -200,10 +363,11 @@ func sendPlayerViews(ctx context.Context, scopone *scopone.Scopone,
 
 func sendObserverUpdates(ctx context.Context, scopone *scopone.Scopone,
 	handViewForPlayers map[string]scopone.HandPlayerView, responseTo string, game *scopone.Game, store connectionStorer) {
+	observerViews := replay.ObserverViews(game, handViewForPlayers)
 	for observerName := range game.Observers {
 		msg := server.NewMessageToOnePlayer(server.HandView, observerName)
 		msg.ResponseTo = responseTo
-		msg.AllHandPlayerViews = handViewForPlayers
+		msg.AllHandPlayerViews = observerViews
 		connectionID, err := store.ConnectionIDForPlayer(ctx, observerName)
 		if err != nil {
 			log.Printf("Connection for observer %v not found", observerName)
@@ -216,10 +380,17 @@ func sendCardsPlayedAndTaken(ctx context.Context, cardPlayed deck.Card, cardsTak
 	finalTableTake scopone.FinalTableTake, game *scopone.Game, playerName string, responseTo string, store connectionStorer) {
 	playerObservers := make([]string, 0)
 	for p := range game.Players {
+		// bots have no connection to send messages to
+		if game.Players[p].Bot != "" {
+			continue
+		}
 		playerObservers = append(playerObservers, p)
 	}
+	// the observers kept behind the players see the cards played with their delayed views
 	for o := range game.Observers {
-		playerObservers = append(playerObservers, o)
+		if game.ObserversSeeMovesLive() {
+			playerObservers = append(playerObservers, o)
+		}
 	}
 	for _, playerObserverName := range playerObservers {
 		msg := server.NewMessageToOnePlayer(server.CardsPlayedAndTaken, playerObserverName)
@@ -253,6 +424,95 @@ func sendMessage(ctx context.Context, msg server.MessageToOnePlayer, connectionI
 	}
 }
 
+// sendChat sends the message of the player to the channel of the chat chosen - the messages of the lobby are sent
+// to all the players connected, while the messages of a game are sent to the players of its channel
+func sendChat(ctx context.Context, osteria *scopone.Scopone, chats *chat.Chat, playerName string,
+	msg server.MessageFromPlayer, connectionID string, store connectionStorer) {
+	channel, members, err := chatChannel(ctx, osteria, playerName, msg, store)
+	if err != nil {
+		sendError(ctx, server.ErrorChattingMsgID, playerName, err, connectionID)
+		return
+	}
+	sent, err := chats.Send(ctx, playerName, channel, msg.Text)
+	if err != nil {
+		sendError(ctx, server.ErrorChattingMsgID, playerName, err, connectionID)
+		return
+	}
+	for _, pName := range members {
+		if pName != "" && (osteria.Players[pName] == nil || osteria.Players[pName].Bot == "") {
+			sendToPlayer(ctx, server.NewChatMessage(pName, sent), store)
+		}
+	}
+}
+
+// chatChannel returns the channel of the chat of the message of the player and the players who get its messages
+func chatChannel(ctx context.Context, osteria *scopone.Scopone, playerName string, msg server.MessageFromPlayer,
+	store connectionStorer) (chat.Channel, []string, error) {
+	if msg.Channel == "" || msg.Channel == chat.Lobby {
+		members, err := store.ConnectedPlayers(ctx)
+		return chat.LobbyChannel, members, err
+	}
+	game, found := osteria.Games[msg.GameName]
+	if !found {
+		return chat.Channel{}, nil, fmt.Errorf("%w - There is no Game with name %v", scopone.ErrGameNotFound, msg.GameName)
+	}
+	channel, err := chat.GameChannel(game, playerName, msg.Channel)
+	if err != nil {
+		return chat.Channel{}, nil, err
+	}
+	return channel, chat.Members(game, channel), nil
+}
+
+// sendChatHistory sends to the player the last messages of each channel passed in which has any, sent since the
+// player has joined the channel - the game is the game of the channels, if any
+func sendChatHistory(ctx context.Context, chats *chat.Chat, playerName string, g *scopone.Game,
+	channels []chat.Channel, store connectionStorer) {
+	for _, channel := range channels {
+		history, err := chats.History(ctx, channel, chat.JoinedAt(g, playerName, channel))
+		if err != nil {
+			log.Printf("The chat history of %v could not be read: %v", channel, err)
+			continue
+		}
+		if len(history) > 0 {
+			sendToPlayer(ctx, server.NewChatHistoryMessage(playerName, channel, history), store)
+		}
+	}
+}
+
+// clearGameChat removes the messages of the channels of the game, so that a new game with the same name does not
+// get them
+func clearGameChat(ctx context.Context, chats *chat.Chat, gameName string) {
+	if err := chats.ClearGame(ctx, gameName); err != nil {
+		log.Printf("The chat of game %v could not be cleared: %v", gameName, err)
+	}
+}
+
+// sendToPlayer sends a message to the connection of the player of the message, if the player is connected
+func sendToPlayer(ctx context.Context, msg server.MessageToOnePlayer, store connectionStorer) {
+	connectionID, err := store.ConnectionIDForPlayer(ctx, msg.PlayerName)
+	if err != nil || connectionID == "" {
+		log.Printf("Connection for player %v not found", msg.PlayerName)
+		return
+	}
+	sendMessage(ctx, msg, &connectionID)
+}
+
+// postToPlayer sends a message for all the clients only to the connection of the player, if the player is connected
+func postToPlayer(ctx context.Context, playerName string, msg server.MessageToAllClients, store connectionStorer) {
+	connectionID, err := store.ConnectionIDForPlayer(ctx, playerName)
+	if err != nil || connectionID == "" {
+		log.Printf("Connection for player %v not found", playerName)
+		return
+	}
+	_, err = apigateway.PostToConnection(&apigatewaymanagementapi.PostToConnectionInput{
+		ConnectionId: aws.String(connectionID),
+		Data:         buildMessage(msg),
+	})
+	if err != nil {
+		log.Println("ERROR while sending message to a client", err.Error())
+	}
+}
+
 func broadcast(ctx context.Context, msg server.MessageToAllClients, store connectionStorer) {
 	msgB := buildMessage(msg)
 
@@ -277,12 +537,16 @@ func broadcast(ctx context.Context, msg server.MessageToAllClients, store connec
 // but who are not yet into any game - these players are not loaded by the GameReadWriter since this
 // loads only the players who are playing a game, not those who have just entered the osteria
 // Then is also set with status "PlayerPlaying" those players who are actually into a game
-func adjustPlayers(ctx context.Context, scopone *scopone.Scopone) {
+// The player who is entering the Osteria, if any, is left as read from the store
+func adjustPlayers(ctx context.Context, scopone *scopone.Scopone, entering string) {
 	connectedPlayers, err := connectionStore.ConnectedPlayers(ctx)
 	if err != nil {
 		log.Fatalln("Can not read connected players from store")
 	}
 	for _, p := range connectedPlayers {
+		if p == "" || p == entering {
+			continue
+		}
 		pInGame := scopone.Players[p]
 		if pInGame == nil {
 			connectedP := &player.Player{}
@@ -295,9 +559,46 @@ func adjustPlayers(ctx context.Context, scopone *scopone.Scopone) {
 	}
 }
 
+// restoreSeatTokens gives back to the seats of the Osteria the reconnect tokens saved by the previous requests
+func restoreSeatTokens(ctx context.Context, osteria *scopone.Scopone, store connectionStorer) error {
+	tokens, err := store.SeatTokens(ctx)
+	if err != nil {
+		return err
+	}
+	osteria.RestoreSeatTokens(tokens)
+	return nil
+}
+
 // setGamesStatus sets the status of the games
 func setGamesStatus(scopone *scopone.Scopone) {
 	for _, g := range scopone.Games {
-		g.CalculateState()
+		err := g.CalculateState()
+		if err != nil {
+			log.Printf("The state of game %v can not be calculated: %v", g.Name, err)
+		}
 	}
 }
+
+// processAccountCommand registers the account of the player or logs the player in, sending back the session token,
+// and makes the connection be of the player of the account
+// A connection can not change account once it has logged in
+func processAccountCommand(ctx context.Context, accounts *auth.Accounts, msg server.MessageFromPlayer, playerName string,
+	connectionID string, connectionStore connectionStorer) error {
+	if playerName != "" {
+		sendError(ctx, server.ErrorLoggingInMsgID, playerName,
+			fmt.Errorf("Connection %v has already logged in as %v", connectionID, playerName), connectionID)
+		return nil
+	}
+	response, _, err := server.AccountCommand(ctx, accounts, msg)
+	if err != nil {
+		log.Printf("Login of %v failed: %v", msg.PlayerName, err)
+		sendMessage(ctx, response, &connectionID)
+		return nil
+	}
+	err = connectionStore.AddPlayerToConnectionID(ctx, connectionID, msg.PlayerName)
+	if err != nil {
+		return err
+	}
+	sendMessage(ctx, response, &connectionID)
+	return nil
+}
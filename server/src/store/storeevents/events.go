@@ -0,0 +1,68 @@
+// Package storeevents implements a store which saves the games as an append-only log of events - the state of a
+// game is rebuilt folding its events, starting from the last snapshot of the game if any
+package storeevents
+
+import (
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Kind is the kind of an event
+type Kind string
+
+// kinds of the events of a game
+const (
+	GameCreated    Kind = "gameCreated"
+	PlayerJoined   Kind = "playerJoined"
+	SeatTaken      Kind = "seatTaken"
+	SeatsArranged  Kind = "seatsArranged"
+	PlayerReady    Kind = "playerReady"
+	SeatLeft       Kind = "seatLeft"
+	AbandonVoted   Kind = "abandonVoted"
+	ObserverJoined Kind = "observerJoined"
+	ObserverLeft   Kind = "observerLeft"
+	HandDealt      Kind = "handDealt"
+	CardPlayed     Kind = "cardPlayed"
+	GameClosed     Kind = "gameClosed"
+)
+
+// Event is something which has happened in a game - only the fields which make sense for its kind are set
+// The events of a game are numbered starting from 1 and a GameCreated event starts the game from scratch, e.g. when
+// a closed game is created again with the same name
+type Event struct {
+	GameName string    `json:"gameName"`
+	Seq      int       `json:"seq"`
+	Kind     Kind      `json:"kind"`
+	Ts       time.Time `json:"ts"`
+	// Options are the options of the game created
+	Options scopone.GameOptions `json:"options,omitempty"`
+	// PlayerName is the player who joins or plays or the observer who joins or leaves or the player who closes
+	PlayerName string `json:"playerName,omitempty"`
+	// Bot is the strategy of the player who joins, if the player is a bot
+	Bot string `json:"bot,omitempty"`
+	// ReplacedPlayer is the player whose seat is taken by the player who joins - it is the player who joins when
+	// the player takes back the seat left
+	ReplacedPlayer string `json:"replacedPlayer,omitempty"`
+	// Seat is the seat taken by the player who joins - the events saved before the players could choose their seat
+	// have no seat and the player takes the first seat free
+	Seat *int `json:"seat,omitempty"`
+	// Seats are the names of the players in the order of their seats after the seats have been arranged, with an
+	// empty name for the seats free
+	Seats []string `json:"seats,omitempty"`
+	// Deck is the deck of the hand dealt in the order the cards are dealt and Seed the seed it has been shuffled with
+	Deck []deck.Card `json:"deck,omitempty"`
+	Seed int64       `json:"seed,omitempty"`
+	// CardPlayed is the card played with the cards taken from the table
+	CardPlayed deck.Card   `json:"cardPlayed,omitempty"`
+	CardsTaken []deck.Card `json:"cardsTaken,omitempty"`
+}
+
+// Snapshot is the state of a game after the event with sequence number Seq - the game is encoded with bson, as
+// the games saved by storemongo, since the json encoding of a game leaves out the cards of the players
+type Snapshot struct {
+	GameName string `json:"gameName"`
+	Seq      int    `json:"seq"`
+	Game     []byte `json:"game"`
+}
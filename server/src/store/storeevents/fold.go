@@ -0,0 +1,247 @@
+package storeevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrInvalidEvent is returned when an event can not be applied to the state its game has reached
+var ErrInvalidEvent = errors.New("Invalid event")
+
+// Fold applies the events, in order, to the game passed in and returns the game reached - the game can be nil if
+// the first event is GameCreated
+// The events are applied with the same logic of the Osteria, so the game reached is the same game which has
+// caused them
+func Fold(game *scopone.Game, events []Event) (*scopone.Game, error) {
+	f := newFolder(game)
+	for _, e := range events {
+		err := f.apply(e)
+		if err != nil {
+			return nil, fmt.Errorf("%w - Event %v of game %v can not be applied: %v", ErrInvalidEvent, e.Seq, e.GameName, err)
+		}
+	}
+	return f.game, nil
+}
+
+// folder applies the events of a game using an Osteria with only that game and its players
+type folder struct {
+	osteria *scopone.Scopone
+	game    *scopone.Game
+	deck    *dealtDeck
+}
+
+// dealtDeck is the shuffler which gives to the hand dealt again the deck of its HandDealt event
+type dealtDeck struct {
+	cards []deck.Card
+	seed  int64
+}
+
+// Shuffle replaces the cards of the deck with the cards of the hand dealt
+func (d *dealtDeck) Shuffle(dk deck.Deck) int64 {
+	copy(dk, d.cards)
+	return d.seed
+}
+
+func newFolder(game *scopone.Game) *folder {
+	f := &folder{deck: &dealtDeck{}}
+	f.osteria = &scopone.Scopone{
+		Players:     make(map[string]*player.Player),
+		Games:       make(map[string]*scopone.Game),
+		Bots:        make(map[string]scopone.BotStrategy),
+		Shuffler:    f.deck,
+		PlayerStore: &scopone.DoNothingStore{},
+		GameStore:   &scopone.DoNothingStore{},
+	}
+	if game != nil {
+		f.game = game
+		f.osteria.Games[game.Name] = game
+		for _, p := range game.Players {
+			f.osteria.Players[p.Name] = p
+			if game.SeatsLeft[p.Name] {
+				// the instance in the seat left is no longer the player, who can take back the seat
+				f.osteria.Players[p.Name] = player.New(p.Name)
+			}
+		}
+		for _, o := range game.Observers {
+			f.osteria.Players[o.Name] = o
+		}
+	}
+	return f
+}
+
+// player returns the player with the name passed in, creating it if it is not in the Osteria
+func (f *folder) player(pName string, bot string) *player.Player {
+	p, found := f.osteria.Players[pName]
+	if !found {
+		p = player.New(pName)
+		p.Bot = bot
+		f.osteria.Players[pName] = p
+	}
+	return p
+}
+
+func (f *folder) apply(e Event) error {
+	// the Osteria of the folder has no store, so its commands have no context to pass on
+	ctx := context.Background()
+	if e.Kind == GameCreated {
+		f.osteria.Games = make(map[string]*scopone.Game)
+		f.osteria.Players = make(map[string]*player.Player)
+		g, err := f.osteria.NewGame(ctx, e.GameName, e.Options)
+		if err != nil {
+			return err
+		}
+		f.game = g
+		return nil
+	}
+	if f.game == nil {
+		return fmt.Errorf("the game has not been created")
+	}
+	switch e.Kind {
+	case PlayerJoined:
+		f.player(e.PlayerName, e.Bot)
+		seat := scopone.AnySeat
+		if e.Seat != nil {
+			seat = *e.Seat
+		}
+		back := f.backToSeat(e.PlayerName)
+		if err := f.osteria.AddPlayerToSeat(ctx, e.PlayerName, f.game.Name, seat); err != nil {
+			return err
+		}
+		f.seated(e, back)
+		return nil
+	case SeatTaken:
+		f.player(e.PlayerName, e.Bot)
+		back := f.backToSeat(e.PlayerName)
+		if err := f.osteria.TakeSeat(ctx, e.PlayerName, f.game.Name, e.ReplacedPlayer); err != nil {
+			return err
+		}
+		f.seated(e, back)
+		return nil
+	case SeatsArranged:
+		return f.osteria.ArrangeSeats(ctx, f.game.Name, e.Seats)
+	case PlayerReady:
+		return f.osteria.PlayerReady(ctx, e.PlayerName, f.game.Name)
+	case SeatLeft:
+		return f.osteria.LeaveSeat(ctx, f.game.Name, e.PlayerName)
+	case AbandonVoted:
+		_, err := f.osteria.VoteToAbandon(ctx, f.game.Name, e.PlayerName)
+		return err
+	case ObserverJoined:
+		f.player(e.PlayerName, "")
+		_, err := f.osteria.AddObserverToGame(ctx, e.PlayerName, f.game.Name)
+		return err
+	case ObserverLeft:
+		delete(f.game.Observers, e.PlayerName)
+		return nil
+	case HandDealt:
+		f.deck.cards = e.Deck
+		f.deck.seed = e.Seed
+		if len(f.game.Hands) == 0 {
+			// the events saved before the players had to be ready have no PlayerReady before the first hand
+			for pName := range f.game.Players {
+				if err := f.osteria.PlayerReady(ctx, pName, f.game.Name); err != nil {
+					return err
+				}
+			}
+		}
+		hand, _, err := f.osteria.NewHand(ctx, f.game)
+		if err != nil {
+			return err
+		}
+		if !sameCards(hand.Deck, e.Deck) {
+			return fmt.Errorf("the deck dealt %v is not the deck of the event", hand.Deck)
+		}
+		f.turnStarted(e)
+		return nil
+	case CardPlayed:
+		_, _, err := f.osteria.Play(ctx, f.game, e.PlayerName, e.CardPlayed, e.CardsTaken)
+		f.turnStarted(e)
+		return err
+	case GameClosed:
+		f.game.Close(e.PlayerName)
+		return nil
+	default:
+		return fmt.Errorf("the kind %v is not known", e.Kind)
+	}
+}
+
+// turnStarted sets the start of the turn of the current player of the game to the time of the event which has
+// started it, i.e. the hand dealt or the card played before, so that the turns keep their time limit across restarts
+func (f *folder) turnStarted(e Event) {
+	if len(f.game.Hands) > 0 && !e.Ts.IsZero() {
+		f.game.Hands[len(f.game.Hands)-1].TurnStarted = e.Ts
+	}
+}
+
+// backToSeat returns true if the player is taking back the seat left, whose time does not change
+func (f *folder) backToSeat(pName string) bool {
+	_, seated := f.game.Players[pName]
+	return seated
+}
+
+// seated sets the time the player has taken the seat to the time of the event, rather than the time the event is
+// applied
+func (f *folder) seated(e Event, back bool) {
+	if back || e.Ts.IsZero() {
+		return
+	}
+	if f.game.Seated == nil {
+		f.game.Seated = make(map[string]time.Time)
+	}
+	f.game.Seated[e.PlayerName] = e.Ts
+}
+
+func sameCards(cards1 []deck.Card, cards2 []deck.Card) bool {
+	if len(cards1) != len(cards2) {
+		return false
+	}
+	for i := range cards1 {
+		if cards1[i] != cards2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeGame encodes the game for a snapshot
+func encodeGame(g *scopone.Game) ([]byte, error) {
+	return bson.Marshal(g)
+}
+
+// decodeGame decodes the game of a snapshot
+func decodeGame(data []byte) (*scopone.Game, error) {
+	g := &scopone.Game{}
+	err := bson.Unmarshal(data, g)
+	if err != nil {
+		return nil, err
+	}
+	// the decoding creates a different instance of a player any time the player is found, so the instances kept in
+	// Game.Players are set everywhere else, as storemongo does when it reads the games
+	for _, t := range g.Teams {
+		for i, p := range t.Players {
+			// the player is nil if the game has been created but not all the players have been added yet
+			if p != nil {
+				t.Players[i] = g.Players[p.Name]
+			}
+		}
+	}
+	g.History = make([]*scopone.HandHistory, len(g.Hands))
+	for i, h := range g.Hands {
+		if h.FirstPlayer != nil {
+			h.FirstPlayer = g.Players[h.FirstPlayer.Name]
+		}
+		if h.CurrentPlayer != nil {
+			h.CurrentPlayer = g.Players[h.CurrentPlayer.Name]
+		}
+		g.History[i] = &h.History
+	}
+	return g, nil
+}
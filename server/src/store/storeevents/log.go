@@ -0,0 +1,83 @@
+package storeevents
+
+import (
+	"context"
+	"sync"
+)
+
+// Log keeps the events of the games, which are only appended and never changed, and the last snapshot of each game
+// Its methods take the context of the command which reads or writes the game, see scopone.GameReadWriter
+type Log interface {
+	// Append appends the events of a game - if it fails some of the events can have been appended anyway, and they
+	// are appended again, with the same sequence numbers, the next time the game is written
+	Append(ctx context.Context, gameName string, events []Event) error
+	// Events returns the events of a game with sequence number greater than afterSeq, in sequence order and each
+	// sequence number only once
+	Events(ctx context.Context, gameName string, afterSeq int) ([]Event, error)
+	// GameNames returns the names of all the games which have events in the log
+	GameNames(ctx context.Context) ([]string, error)
+	// WriteSnapshot replaces the snapshot of a game
+	WriteSnapshot(ctx context.Context, snapshot Snapshot) error
+	// ReadSnapshot returns the last snapshot of a game - it returns false if the game has no snapshot
+	ReadSnapshot(ctx context.Context, gameName string) (Snapshot, bool, error)
+}
+
+// MemoryLog is a log which keeps the events in memory - it is lost when the program stops and is used by tests
+type MemoryLog struct {
+	mu        sync.Mutex
+	events    map[string][]Event
+	snapshots map[string]Snapshot
+}
+
+// NewMemoryLog returns an empty log kept in memory
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{events: make(map[string][]Event), snapshots: make(map[string]Snapshot)}
+}
+
+// Append appends the events of a game
+func (l *MemoryLog) Append(ctx context.Context, gameName string, events []Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events[gameName] = append(l.events[gameName], events...)
+	return nil
+}
+
+// Events returns the events of a game with sequence number greater than afterSeq
+func (l *MemoryLog) Events(ctx context.Context, gameName string, afterSeq int) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make([]Event, 0)
+	for _, e := range l.events[gameName] {
+		if e.Seq > afterSeq {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// GameNames returns the names of all the games which have events in the log
+func (l *MemoryLog) GameNames(ctx context.Context) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.events))
+	for name := range l.events {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// WriteSnapshot replaces the snapshot of a game
+func (l *MemoryLog) WriteSnapshot(ctx context.Context, snapshot Snapshot) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.snapshots[snapshot.GameName] = snapshot
+	return nil
+}
+
+// ReadSnapshot returns the last snapshot of a game
+func (l *MemoryLog) ReadSnapshot(ctx context.Context, gameName string) (Snapshot, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	snapshot, found := l.snapshots[gameName]
+	return snapshot, found, nil
+}
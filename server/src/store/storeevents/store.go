@@ -0,0 +1,465 @@
+package storeevents
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// DefaultSnapshotInterval is the number of events after which a new snapshot of a game is written
+const DefaultSnapshotInterval = 50
+
+// Store saves the games as events appended to a log - it implements scopone.GameReadWriter
+// Each time a game is written the store compares it with what it has already saved of the game and appends the
+// events which have happened since then
+type Store struct {
+	log Log
+	// snapshotInterval is the number of events after which a new snapshot of a game is written - if it is 0 no
+	// snapshot is written
+	snapshotInterval int
+	// mu protects streams and locks since the games are written in parallel - it is never held while the log is
+	// read or written, so that a slow log does not stop the other games
+	mu      sync.Mutex
+	streams map[string]*stream
+	// locks has a lock for each game, held while the game is read or written, so that the events of a game are
+	// appended in order while the other games are written in parallel
+	locks map[string]*sync.Mutex
+}
+
+// stream is what the store has saved of a game
+type stream struct {
+	seq          int
+	lastSnapshot int
+	// created is true if the creation of the game has been saved
+	created bool
+	// seats are the names of the players in the seats saved, in the order of the seats, with an empty name for the
+	// seats not taken yet
+	seats []string
+	// ready are the players saved as ready to start the game
+	ready map[string]bool
+	// seatsLeft are the players saved as having left their seat in the middle of the game and votes the players
+	// saved as having voted to abandon the game
+	seatsLeft map[string]bool
+	votes     map[string]bool
+	observers map[string]bool
+	hands     int
+	// cardsPlayed is the number of plays of the last hand saved, including the final take of the table
+	cardsPlayed int
+	closed      bool
+}
+
+// New returns a store which saves the games in the log passed in and writes a snapshot of a game every
+// snapshotInterval events
+func New(log Log, snapshotInterval int) *Store {
+	return &Store{log: log, snapshotInterval: snapshotInterval, streams: make(map[string]*stream),
+		locks: make(map[string]*sync.Mutex)}
+}
+
+// lock locks the game and returns the function which unlocks it
+func (store *Store) lock(gameName string) func() {
+	store.mu.Lock()
+	l, found := store.locks[gameName]
+	if !found {
+		l = &sync.Mutex{}
+		store.locks[gameName] = l
+	}
+	store.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// stream returns what the store has saved of a game
+func (store *Store) stream(gameName string) (*stream, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	st, found := store.streams[gameName]
+	return st, found
+}
+
+// setStream sets what the store has saved of a game
+func (store *Store) setStream(gameName string, st *stream) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.streams[gameName] = st
+}
+
+// newStream returns what has been saved of a game which has reached the state passed in with the event seq
+func newStream(g *scopone.Game, seq int) *stream {
+	st := &stream{seq: seq, created: true, seats: seatNames(g), ready: make(map[string]bool),
+		seatsLeft: make(map[string]bool), votes: make(map[string]bool), observers: make(map[string]bool),
+		hands: len(g.Hands), closed: g.State == scopone.GameClosed}
+	for pName, ready := range g.Ready {
+		st.ready[pName] = ready
+	}
+	for pName := range g.SeatsLeft {
+		st.seatsLeft[pName] = true
+	}
+	for pName := range g.AbandonVotes {
+		st.votes[pName] = true
+	}
+	for oName := range g.Observers {
+		st.observers[oName] = true
+	}
+	if len(g.Hands) > 0 {
+		st.cardsPlayed = len(g.Hands[len(g.Hands)-1].History.CardPlaySequence)
+	}
+	return st
+}
+
+// WriteGame appends to the log the events which have happened in the game since it has been written last time
+// If the events can not be appended they are appended the next time the game is written
+func (store *Store) WriteGame(ctx context.Context, g *scopone.Game) error {
+	unlock := store.lock(g.Name)
+	defer unlock()
+	st, found := store.stream(g.Name)
+	if !found || (st.closed && g.State != scopone.GameClosed) {
+		// the game is new or has been created again with the name of a game which has been closed
+		seq, err := store.lastSeq(ctx, g.Name)
+		if err != nil {
+			return err
+		}
+		st = &stream{seq: seq, lastSnapshot: seq, ready: make(map[string]bool), seatsLeft: make(map[string]bool),
+			votes: make(map[string]bool), observers: make(map[string]bool)}
+	}
+	events, next := st.next(g)
+	if len(events) == 0 {
+		return nil
+	}
+	err := store.log.Append(ctx, g.Name, events)
+	if err != nil {
+		return err
+	}
+	if store.snapshotInterval > 0 && next.seq-next.lastSnapshot >= store.snapshotInterval {
+		// the snapshot only makes loading the game faster, so if it fails the game is saved anyway
+		err = store.writeSnapshot(ctx, g, next.seq)
+		if err != nil {
+			log.Printf("The snapshot of game %v could not be written: %v", g.Name, err)
+		} else {
+			next.lastSnapshot = next.seq
+		}
+	}
+	store.setStream(g.Name, next)
+	return nil
+}
+
+// next returns the events which have happened in the game since the stream has been saved and the stream after
+// the events are saved
+func (st *stream) next(g *scopone.Game) ([]Event, *stream) {
+	next := *st
+	next.seats = append([]string{}, st.seats...)
+	next.ready = make(map[string]bool)
+	for pName := range st.ready {
+		next.ready[pName] = true
+	}
+	next.seatsLeft = make(map[string]bool)
+	for pName := range st.seatsLeft {
+		next.seatsLeft[pName] = true
+	}
+	next.votes = make(map[string]bool)
+	for pName := range st.votes {
+		next.votes[pName] = true
+	}
+	next.observers = make(map[string]bool)
+	for oName := range st.observers {
+		next.observers[oName] = true
+	}
+	events := make([]Event, 0)
+	add := func(e Event) {
+		next.seq++
+		e.GameName = g.Name
+		e.Seq = next.seq
+		e.Ts = time.Now()
+		events = append(events, e)
+	}
+
+	if !next.created {
+		add(Event{Kind: GameCreated, Options: scopone.GameOptions{
+			TargetScore:       g.TargetScore,
+			Variant:           g.Variant,
+			NumberOfPlayers:   g.NumberOfPlayers,
+			Seed:              g.Seed,
+			MoveTimeLimit:     g.MoveTimeLimit,
+			ObserverMode:      g.ObserverMode,
+			ObserverDelay:     g.ObserverDelay,
+			PasswordHash:      g.PasswordHash,
+			Invited:           g.Invited,
+			NoTeamChatInHands: g.NoTeamChatInHands,
+		}})
+		next.created = true
+	}
+	// before the first hand the players can swap their seats or be kicked out of them, and any such change makes
+	// the players confirm again to be ready, so if a player saved is no longer in the seat saved, or a player saved
+	// as ready is no longer ready, the seats of the players saved are arranged as they are now
+	if next.hands == 0 && next.seatsChanged(g) {
+		seats := make([]string, 0)
+		for _, pName := range seatNames(g) {
+			if !contains(next.seats, pName) {
+				pName = ""
+			}
+			seats = append(seats, pName)
+		}
+		add(Event{Kind: SeatsArranged, Seats: seats})
+		next.seats = seats
+		next.ready = make(map[string]bool)
+	}
+	// the players who have taken back the seat left are still in the seat saved
+	for _, pName := range sortedKeys(next.seatsLeft) {
+		if g.SeatsLeft[pName] {
+			continue
+		}
+		if contains(seatNames(g), pName) {
+			add(Event{Kind: SeatTaken, PlayerName: pName, ReplacedPlayer: pName})
+		}
+		delete(next.seatsLeft, pName)
+	}
+	// the players who have joined since the last time are the ones in the seats free when saved, while a seat saved
+	// with another player has been taken by a player who has replaced the player saved, see Scopone.TakeSeat
+	for seat, pName := range seatNames(g) {
+		seat := seat
+		switch {
+		case pName == "":
+		case seat >= len(next.seats) || next.seats[seat] == "":
+			add(Event{Kind: PlayerJoined, PlayerName: pName, Bot: g.Players[pName].Bot, Seat: &seat})
+		case next.seats[seat] != pName:
+			add(Event{Kind: SeatTaken, PlayerName: pName, Bot: g.Players[pName].Bot, ReplacedPlayer: next.seats[seat]})
+		default:
+			continue
+		}
+		for len(next.seats) <= seat {
+			next.seats = append(next.seats, "")
+		}
+		next.seats[seat] = pName
+	}
+	if next.hands == 0 {
+		for _, pName := range sortedKeys(g.Ready) {
+			if g.Ready[pName] && !next.ready[pName] {
+				add(Event{Kind: PlayerReady, PlayerName: pName})
+				next.ready[pName] = true
+			}
+		}
+	}
+	for _, oName := range sortedNames(g.Observers) {
+		if !next.observers[oName] {
+			add(Event{Kind: ObserverJoined, PlayerName: oName})
+			next.observers[oName] = true
+		}
+	}
+	for _, oName := range sortedKeys(next.observers) {
+		if _, found := g.Observers[oName]; !found {
+			add(Event{Kind: ObserverLeft, PlayerName: oName})
+			delete(next.observers, oName)
+		}
+	}
+	for i := next.hands - 1; i < len(g.Hands); i++ {
+		if i < 0 {
+			continue
+		}
+		history := g.Hands[i].History
+		if i >= next.hands {
+			add(Event{Kind: HandDealt, Deck: append([]deck.Card{}, history.Deck...), Seed: history.Seed})
+			next.hands = i + 1
+			next.cardsPlayed = 0
+		}
+		for _, play := range history.CardPlaySequence[next.cardsPlayed:] {
+			// the cards left on the table at the end of the hand are taken without any card played and the take
+			// happens again when the last card is played
+			if play.CardPlayed == (deck.Card{}) {
+				continue
+			}
+			add(Event{Kind: CardPlayed, PlayerName: play.Player, CardPlayed: play.CardPlayed,
+				CardsTaken: append([]deck.Card{}, play.CardsTaken...)})
+		}
+		next.cardsPlayed = len(history.CardPlaySequence)
+	}
+	// the seats are left and the votes are cast when no card can be played any more by the players who leave or
+	// vote, so they follow the cards played
+	for _, pName := range sortedKeys(g.SeatsLeft) {
+		if !next.seatsLeft[pName] {
+			add(Event{Kind: SeatLeft, PlayerName: pName})
+			next.seatsLeft[pName] = true
+		}
+	}
+	for _, pName := range sortedKeys(next.votes) {
+		if !g.AbandonVotes[pName] {
+			delete(next.votes, pName)
+		}
+	}
+	for _, pName := range sortedKeys(g.AbandonVotes) {
+		if !next.votes[pName] {
+			add(Event{Kind: AbandonVoted, PlayerName: pName})
+			next.votes[pName] = true
+		}
+	}
+	if g.State == scopone.GameClosed && !next.closed {
+		add(Event{Kind: GameClosed, PlayerName: g.ClosedBy})
+		next.closed = true
+	}
+	return events, &next
+}
+
+// seatsChanged returns true if a player saved is no longer in the seat saved or a player saved as ready is no
+// longer ready
+func (st *stream) seatsChanged(g *scopone.Game) bool {
+	seats := seatNames(g)
+	for seat, pName := range st.seats {
+		if pName != "" && (seat >= len(seats) || seats[seat] != pName) {
+			return true
+		}
+	}
+	for pName := range st.ready {
+		if !g.Ready[pName] {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n != "" && n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// seatNames returns the names of the players in the seats of the game, team after team, with an empty name for the
+// seats not taken yet
+func seatNames(g *scopone.Game) []string {
+	names := make([]string, 0)
+	for _, t := range g.Teams {
+		for _, p := range t.Players {
+			if p == nil {
+				names = append(names, "")
+				continue
+			}
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func sortedNames(players map[string]*player.Player) []string {
+	names := make([]string, 0, len(players))
+	for name := range players {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (store *Store) writeSnapshot(ctx context.Context, g *scopone.Game, seq int) error {
+	data, err := encodeGame(g)
+	if err != nil {
+		return err
+	}
+	return store.log.WriteSnapshot(ctx, Snapshot{GameName: g.Name, Seq: seq, Game: data})
+}
+
+// load rebuilds a game from its last snapshot, if any, and the events which follow it - the game is nil if the
+// log has no events of the game
+func (store *Store) load(ctx context.Context, gameName string) (*scopone.Game, *stream, error) {
+	snapshot, found, err := store.log.ReadSnapshot(ctx, gameName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var g *scopone.Game
+	if found {
+		g, err = decodeGame(snapshot.Game)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	events, err := store.log.Events(ctx, gameName, snapshot.Seq)
+	if err != nil {
+		return nil, nil, err
+	}
+	g, err = Fold(g, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	if g == nil {
+		return nil, nil, nil
+	}
+	seq := snapshot.Seq
+	if len(events) > 0 {
+		seq = events[len(events)-1].Seq
+	}
+	st := newStream(g, seq)
+	st.lastSnapshot = snapshot.Seq
+	return g, st, nil
+}
+
+// lastSeq returns the sequence number of the last event of a game in the log, 0 if there is no event
+func (store *Store) lastSeq(ctx context.Context, gameName string) (int, error) {
+	snapshot, _, err := store.log.ReadSnapshot(ctx, gameName)
+	if err != nil {
+		return 0, err
+	}
+	events, err := store.log.Events(ctx, gameName, snapshot.Seq)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return snapshot.Seq, nil
+	}
+	return events[len(events)-1].Seq, nil
+}
+
+// ReadOpenGames rebuilds from the log all the games which are not closed
+// A game whose events can not be folded is left out and the error is logged, so that the other games can be played
+func (store *Store) ReadOpenGames(ctx context.Context) (games map[string]*scopone.Game, players map[string]*player.Player, err error) {
+	// it is important to initialize games and players because we do not want to retun nils but rather
+	// empty maps in case no player or games are found in the log
+	games = make(map[string]*scopone.Game)
+	players = make(map[string]*player.Player)
+
+	names, err := store.log.GameNames(ctx)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		unlock := store.lock(name)
+		g, st, e := store.load(ctx, name)
+		if e == nil && g != nil {
+			store.setStream(name, st)
+		}
+		unlock()
+		if e != nil {
+			log.Printf("Game %v can not be read: %v", name, e)
+			continue
+		}
+		if g == nil {
+			continue
+		}
+		if g.State == scopone.GameClosed {
+			continue
+		}
+		games[name] = g
+		// the players and the observers of the games read are not in the Osteria until they enter it again
+		for _, p := range g.Players {
+			p.Status = player.PlayerLeftOsteria
+			players[p.Name] = p
+		}
+		for _, o := range g.Observers {
+			o.Status = player.PlayerLeftOsteria
+			players[o.Name] = o
+		}
+	}
+	return
+}
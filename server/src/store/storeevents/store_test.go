@@ -0,0 +1,310 @@
+package storeevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/game-logic/team"
+	"go-scopone/src/store/storetest"
+)
+
+var ctx = context.Background()
+
+// failingLog is a log which fails to append the events while fail is true
+type failingLog struct {
+	*MemoryLog
+	fail bool
+}
+
+func (l *failingLog) Append(ctx context.Context, gameName string, events []Event) error {
+	if l.fail {
+		return errors.New("log not available")
+	}
+	return l.MemoryLog.Append(ctx, gameName, events)
+}
+
+// slowLog is a log which does not append the events of the slow game until it is released
+type slowLog struct {
+	*MemoryLog
+	appending chan struct{}
+	release   chan struct{}
+}
+
+func (l *slowLog) Append(ctx context.Context, gameName string, events []Event) error {
+	if gameName == "slow game" {
+		l.appending <- struct{}{}
+		<-l.release
+	}
+	return l.MemoryLog.Append(ctx, gameName, events)
+}
+
+// gameState is the state of a game which has to be the same after the game is rebuilt from the log
+type gameState struct {
+	State       scopone.State
+	Score       map[string]int
+	Teams       []string
+	TakenCards  map[string][]deck.Card
+	Scope       map[string][]deck.Card
+	Cards       map[string][]deck.Card
+	Bots        map[string]string
+	Observers   []string
+	Tables      [][]deck.Card
+	HandScores  []map[string]scopone.TeamScore
+	Histories   []scopone.HandHistory
+	Current     string
+	DealtCards  int
+	HandsClosed int
+}
+
+func stateOf(g *scopone.Game) gameState {
+	st := gameState{State: g.State, Score: g.Score, TakenCards: map[string][]deck.Card{}, Scope: map[string][]deck.Card{},
+		Cards: map[string][]deck.Card{}, Bots: map[string]string{}}
+	for _, t := range g.Teams {
+		for _, p := range t.Players {
+			if p == nil {
+				continue
+			}
+			st.Teams = append(st.Teams, p.Name)
+			if g.Players[p.Name] != p {
+				st.Teams = append(st.Teams, "not the player of the game")
+			}
+		}
+	}
+	for _, t := range g.Teams {
+		if t.Players[len(t.Players)-1] != nil {
+			st.TakenCards[team.Name(t)] = t.TakenCards
+			st.Scope[team.Name(t)] = t.ScopeDiScopone
+		}
+	}
+	for _, p := range g.Players {
+		st.Cards[p.Name] = p.Cards
+		st.Bots[p.Name] = p.Bot
+	}
+	st.Observers = sortedNames(g.Observers)
+	for i, h := range g.Hands {
+		st.Tables = append(st.Tables, h.Table)
+		st.HandScores = append(st.HandScores, h.Score)
+		st.Histories = append(st.Histories, *g.History[i])
+		if h.State == scopone.HandClosed {
+			st.HandsClosed++
+		}
+		st.Current = h.CurrentPlayer.Name
+		st.DealtCards = h.DealtCards
+	}
+	return st
+}
+
+// newGameOfBots creates an Osteria with the store passed in and a game of 4 bots, with an observer, which have
+// already played a hand and some cards of the second hand
+func newGameOfBots(t *testing.T, store *Store, gName string) (*scopone.Scopone, *scopone.Game) {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, store)
+	g := storetest.NewGameOfBots(t, s, gName, scopone.GameOptions{}, 4)
+	for _, oName := range []string{"o1", "o2"} {
+		s.PlayerEnters(ctx, oName, "")
+		if _, err := s.AddObserverToGame(ctx, oName, gName); err != nil {
+			t.Fatalf("The observer could not be added to the game: %v", err)
+		}
+	}
+	s.RemovePlayer("o2")
+	storetest.PlayCards(t, s, g, 45)
+	return s, g
+}
+
+func checkRebuilt(t *testing.T, log Log, snapshotInterval int, g *scopone.Game) *scopone.Scopone {
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, New(log, snapshotInterval))
+	rebuilt, found := s.Games[g.Name]
+	if !found {
+		t.Fatalf("Game %v should be read from the log", g.Name)
+	}
+	if !reflect.DeepEqual(stateOf(g), stateOf(rebuilt)) {
+		t.Errorf("The game rebuilt from the log\n%v\nshould be the same as the game written\n%v", stateOf(rebuilt), stateOf(g))
+	}
+	for pName, p := range rebuilt.Players {
+		if s.Players[pName] != p {
+			t.Errorf("The player %v of the game should be the player of the Osteria", pName)
+		}
+	}
+	return s
+}
+
+func TestGameRebuiltFromEvents(t *testing.T) {
+	for _, interval := range []int{0, 7, DefaultSnapshotInterval} {
+		log := NewMemoryLog()
+		_, g := newGameOfBots(t, New(log, interval), "game")
+		s := checkRebuilt(t, log, interval, g)
+
+		// the game rebuilt goes on being played and saved
+		bot.RestoreBots(s)
+		rebuilt := s.Games["game"]
+		storetest.PlayCards(t, s, rebuilt, 10)
+		checkRebuilt(t, log, interval, rebuilt)
+
+		_, found, _ := log.ReadSnapshot(ctx, "game")
+		if found != (interval > 0) {
+			t.Errorf("With snapshot interval %v the game should have a snapshot %v", interval, interval > 0)
+		}
+	}
+}
+
+func TestGameRebuiltFromSnapshot(t *testing.T) {
+	log := NewMemoryLog()
+	_, g := newGameOfBots(t, New(log, 7), "game")
+	snapshot, _, _ := log.ReadSnapshot(ctx, "game")
+	// the events before the snapshot are not needed any more
+	events, _ := log.Events(ctx, "game", snapshot.Seq)
+	log.events["game"] = events
+	checkRebuilt(t, log, 7, g)
+}
+
+func TestSeatTimesRebuilt(t *testing.T) {
+	log := NewMemoryLog()
+	_, g := newGameOfBots(t, New(log, 0), "game")
+	rebuilt := checkRebuilt(t, log, 0, g).Games["game"]
+	events, _ := log.Events(ctx, "game", 0)
+	joined := 0
+	for _, e := range events {
+		if e.Kind != PlayerJoined {
+			continue
+		}
+		joined++
+		if !rebuilt.Seated[e.PlayerName].Equal(e.Ts) {
+			t.Errorf("%v should have taken the seat at %v, when the event was saved, but has taken it at %v",
+				e.PlayerName, e.Ts, rebuilt.Seated[e.PlayerName])
+		}
+	}
+	if joined != 4 {
+		t.Errorf("The 4 players should have joined the game but %v have joined", joined)
+	}
+}
+
+func TestClosedGames(t *testing.T) {
+	log := NewMemoryLog()
+	s, _ := newGameOfBots(t, New(log, 7), "game")
+	if err := s.Close(ctx, "game", "Bot 1"); err != nil {
+		t.Fatalf("The game could not be closed: %v", err)
+	}
+	s = scopone.New(ctx, &scopone.DoNothingStore{}, New(log, 7))
+	if _, found := s.Games["game"]; found {
+		t.Fatalf("A closed game should not be read from the log")
+	}
+
+	// a new game can be created with the name of the game closed
+	g, err := s.NewGame(ctx, "game", scopone.GameOptions{NumberOfPlayers: 2, Variant: scopone.Scopa})
+	if err != nil {
+		t.Fatalf("The game could not be created: %v", err)
+	}
+	s.AddBotToGame(ctx, "game", &bot.Heuristic{})
+	checkRebuilt(t, log, 7, g)
+	events, _ := log.Events(ctx, "game", 0)
+	for i := range events {
+		if events[i].Seq != i+1 {
+			t.Fatalf("The event %v should have sequence number %v", events[i], i+1)
+		}
+	}
+}
+
+func TestWriteFailure(t *testing.T) {
+	log := &failingLog{MemoryLog: NewMemoryLog()}
+	s, g := newGameOfBots(t, New(log, 7), "game")
+	log.fail = true
+	if _, _, _, _, err := s.PlayBot(ctx, g); !errors.Is(err, scopone.ErrStoreFailure) {
+		t.Fatalf("The card played should not be saved but the error is %v", err)
+	}
+	log.fail = false
+	// the card played which has not been saved is saved with the next one
+	storetest.PlayCards(t, s, g, 1)
+	checkRebuilt(t, log, 7, g)
+}
+
+func TestSlowGameDoesNotStopTheOthers(t *testing.T) {
+	log := &slowLog{MemoryLog: NewMemoryLog(), appending: make(chan struct{}), release: make(chan struct{})}
+	store := New(log, 7)
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	slow, _ := s.NewGame(ctx, "slow game", scopone.GameOptions{})
+	other, _ := s.NewGame(ctx, "other game", scopone.GameOptions{})
+	written := make(chan error)
+	go func() {
+		written <- store.WriteGame(ctx, slow)
+	}()
+	<-log.appending
+	done := make(chan error)
+	go func() {
+		done <- store.WriteGame(ctx, other)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("The other game could not be written: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The other game should be written while the log is appending the events of the slow game")
+	}
+	close(log.release)
+	if err := <-written; err != nil {
+		t.Errorf("The slow game could not be written: %v", err)
+	}
+}
+
+func TestOnlyPasswordHashSaved(t *testing.T) {
+	log := NewMemoryLog()
+	s := scopone.New(ctx, &scopone.DoNothingStore{}, New(log, 1))
+	g := storetest.NewGameOfBots(t, s, "game", scopone.GameOptions{Password: "secret"}, 4)
+	storetest.PlayCards(t, s, g, 1)
+	events, _ := log.Events(ctx, "game", 0)
+	snapshot, _, _ := log.ReadSnapshot(ctx, "game")
+	data, _ := json.Marshal(events)
+	if strings.Contains(string(data), "secret") || strings.Contains(string(snapshot.Game), "secret") {
+		t.Errorf("The password of the game should not be saved but the events are %v", string(data))
+	}
+	read, _, _ := New(log, 1).ReadOpenGames(ctx)
+	if err := read["game"].Admits("p1", "secret"); err != nil {
+		t.Errorf("The game read should admit the players who know its password but the error is %v", err)
+	}
+}
+
+func TestInvalidEvents(t *testing.T) {
+	_, err := Fold(nil, []Event{{GameName: "game", Seq: 1, Kind: PlayerJoined, PlayerName: "p1"}})
+	if !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("An event of a game not created should return ErrInvalidEvent but returns %v", err)
+	}
+
+	log := NewMemoryLog()
+	newGameOfBots(t, New(log, 0), "game")
+	newGameOfBots(t, New(log, 0), "broken game")
+	log.Append(ctx, "broken game", []Event{{GameName: "broken game", Seq: 1000, Kind: CardPlayed, PlayerName: "p1",
+		CardPlayed: deck.Card{Type: "Ace", Suit: deck.Denari}}})
+	games, _, _ := New(log, 0).ReadOpenGames(ctx)
+	if _, found := games["broken game"]; found {
+		t.Errorf("A game whose events can not be folded should not be read")
+	}
+	if _, found := games["game"]; !found {
+		t.Errorf("A game whose events can be folded should be read even if another game can not")
+	}
+}
+
+// memoryStore is a store of events kept in memory which ignores the entries of the players
+type memoryStore struct {
+	*Store
+}
+
+func (store memoryStore) AddPlayerEntry(ctx context.Context, player *player.Player) error {
+	return nil
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		log := NewMemoryLog()
+		return func(t *testing.T) storetest.Store {
+			return memoryStore{New(log, 7)}
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package storefile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-scopone/src/store/storeevents"
+)
+
+const (
+	eventsSuffix   = ".events.jsonl"
+	snapshotSuffix = ".snapshot.json"
+	tmpSuffix      = ".tmp"
+)
+
+// fileLog keeps the events of each game in a file, one event per line, and the snapshot of each game in another
+// file - it implements storeevents.Log
+// The files are local, so the context of a write is only checked before the write starts
+type fileLog struct {
+	dir string
+}
+
+// fileName returns the name of a file of a game - the name of the game is escaped so that it can be any string
+func (l *fileLog) fileName(gameName string, suffix string) string {
+	return filepath.Join(l.dir, url.PathEscape(gameName)+suffix)
+}
+
+// Append appends the events of a game to its file
+func (l *fileLog) Append(ctx context.Context, gameName string, events []storeevents.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var data bytes.Buffer
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data.Write(line)
+		data.WriteByte('\n')
+	}
+	return appendLines(l.fileName(gameName, eventsSuffix), data.Bytes())
+}
+
+// Events reads the events of a game with sequence number greater than afterSeq
+func (l *fileLog) Events(ctx context.Context, gameName string, afterSeq int) ([]storeevents.Event, error) {
+	events := make([]storeevents.Event, 0)
+	lastSeq := afterSeq
+	err := readLines(l.fileName(gameName, eventsSuffix), func(line []byte) error {
+		var e storeevents.Event
+		err := json.Unmarshal(line, &e)
+		if err != nil {
+			return err
+		}
+		if e.Seq > lastSeq {
+			events = append(events, e)
+			lastSeq = e.Seq
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return events, nil
+	}
+	return events, err
+}
+
+// GameNames returns the names of the games which have a file of events
+func (l *fileLog) GameNames(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), eventsSuffix) {
+			continue
+		}
+		name, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), eventsSuffix))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// WriteSnapshot replaces the snapshot of a game
+func (l *fileLog) WriteSnapshot(ctx context.Context, snapshot storeevents.Snapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return writeAtomically(l.fileName(snapshot.GameName, snapshotSuffix), data)
+}
+
+// ReadSnapshot reads the snapshot of a game
+func (l *fileLog) ReadSnapshot(ctx context.Context, gameName string) (storeevents.Snapshot, bool, error) {
+	var snapshot storeevents.Snapshot
+	data, err := os.ReadFile(l.fileName(gameName, snapshotSuffix))
+	if os.IsNotExist(err) {
+		return snapshot, false, nil
+	}
+	if err != nil {
+		return snapshot, false, err
+	}
+	err = json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return snapshot, false, err
+	}
+	return snapshot, true, nil
+}
+
+// appendLines appends the lines to the file and waits for them to be on disk - if the lines can not be written
+// the file is truncated to its previous size, so that no half line is left at its end
+func appendLines(fileName string, lines []byte) error {
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(lines)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		if e := f.Truncate(info.Size()); e != nil {
+			return fmt.Errorf("%v - the file %v could not be truncated: %v", err, fileName, e)
+		}
+		return err
+	}
+	return nil
+}
+
+// readLines passes to the function the lines of the file, without the new line at their end
+func readLines(fileName string, readLine func(line []byte) error) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// the line without new line at the end of the file is a line whose writing has not been completed
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = readLine(line[:len(line)-1])
+		if err != nil {
+			return fmt.Errorf("The line %q of file %v can not be read: %v", line, fileName, err)
+		}
+	}
+}
+
+// writeAtomically replaces the content of the file with the data - the data are written to a temporary file which
+// then replaces the file, so that the file has either the old content or the new one even if the program stops
+// while it is being written
+func writeAtomically(fileName string, data []byte) error {
+	tmpName := fileName + tmpSuffix
+	f, err := os.OpenFile(tmpName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	err = os.Rename(tmpName, fileName)
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return syncDir(filepath.Dir(fileName))
+}
+
+// syncDir waits for the changes of the entries of the directory, e.g. a file renamed, to be on disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// recoverFiles brings the files of the directory back to a consistent state after the program has stopped while
+// writing them: the temporary files are removed and the lines not completed are cut from the files of lines
+func recoverFiles(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fileName := filepath.Join(dir, entry.Name())
+		switch {
+		case strings.HasSuffix(entry.Name(), tmpSuffix):
+			err = os.Remove(fileName)
+		case strings.HasSuffix(entry.Name(), ".jsonl"):
+			err = cutIncompleteLine(fileName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cutIncompleteLine truncates the file after its last new line
+func cutIncompleteLine(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete == len(data) {
+		return nil
+	}
+	fmt.Printf("The incomplete line at the end of file %v is removed\n", fileName)
+	return os.Truncate(fileName, int64(complete))
+}
@@ -0,0 +1,122 @@
+// Package storefile implements the store using files of a local directory, so that the games survive a restart of
+// the server without any database
+// The games are saved as events, see storeevents, appended to one file per game, while the entries of the players in
+// the Osteria and the accounts of the players are appended to one file each
+package storefile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-scopone/src/auth"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/store/storeevents"
+)
+
+const (
+	playerEntriesFileName = "playerEntries.jsonl"
+	accountsFileName      = "accounts.jsonl"
+)
+
+// Store keeps the players, the games and the accounts in the files of a directory - it implements
+// scopone.PlayerWriter, auth.AccountStore and, through the event store it embeds, scopone.GameReadWriter
+type Store struct {
+	*storeevents.Store
+	dir string
+	// mu makes the entries of the players and the accounts be appended one at a time
+	mu sync.Mutex
+}
+
+// Open opens the store kept in the directory passed in, creating the directory if it does not exist
+// If the program has stopped while writing the files of the store, the files are brought back to the state they
+// had before the write started
+func Open(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	err = recoverFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	store := Store{
+		Store: storeevents.New(&fileLog{dir: dir}, storeevents.DefaultSnapshotInterval),
+		dir:   dir,
+	}
+	return &store, nil
+}
+
+type playerEntry struct {
+	Ts     time.Time `json:"ts"`
+	Player string    `json:"player"`
+}
+
+// AddPlayerEntry appends a line to the file of the entries representing the fact that a player has entered the Osteria
+func (store *Store) AddPlayerEntry(ctx context.Context, player *player.Player) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(playerEntry{Ts: time.Now(), Player: player.Name})
+	if err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return appendLines(filepath.Join(store.dir, playerEntriesFileName), append(line, '\n'))
+}
+
+// AddAccount appends the account to the file of the accounts unless there is already an account with the same name
+func (store *Store) AddAccount(ctx context.Context, account auth.Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, err = store.account(account.Name)
+	if err == nil {
+		return fmt.Errorf("%w - \"%v\"", auth.ErrAccountAlreadyExists, account.Name)
+	}
+	if !errors.Is(err, auth.ErrAccountNotFound) {
+		return err
+	}
+	return appendLines(filepath.Join(store.dir, accountsFileName), append(line, '\n'))
+}
+
+// Account reads the account with the name passed in from the file of the accounts
+func (store *Store) Account(ctx context.Context, name string) (auth.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.account(name)
+}
+
+func (store *Store) account(name string) (auth.Account, error) {
+	var found *auth.Account
+	err := readLines(filepath.Join(store.dir, accountsFileName), func(line []byte) error {
+		var account auth.Account
+		err := json.Unmarshal(line, &account)
+		if err != nil {
+			return err
+		}
+		if account.Name == name {
+			found = &account
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return auth.Account{}, err
+	}
+	if found == nil {
+		return auth.Account{}, fmt.Errorf("%w - \"%v\"", auth.ErrAccountNotFound, name)
+	}
+	return *found, nil
+}
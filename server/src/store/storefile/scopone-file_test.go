@@ -0,0 +1,79 @@
+package storefile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/store/storetest"
+)
+
+var ctx = context.Background()
+
+// opener returns the function which opens the store on the directory passed in
+func opener(dir string) storetest.Opener {
+	return func(t *testing.T) storetest.Store {
+		store, err := Open(dir)
+		if err != nil {
+			t.Fatalf("The store could not be opened: %v", err)
+		}
+		return store
+	}
+}
+
+func TestPlayerEntriesSaved(t *testing.T) {
+	dir := t.TempDir()
+	if err := opener(dir)(t).AddPlayerEntry(ctx, player.New("p1")); err != nil {
+		t.Fatalf("The entry of p1 could not be added: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, playerEntriesFileName))
+	if !strings.Contains(string(data), `"player":"p1"`) {
+		t.Errorf("The entry of the player should be saved but the entries are %v", string(data))
+	}
+}
+
+func TestRecoveryAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	open := opener(dir)
+	s := storetest.Osteria(t, open)
+	g := storetest.NewGameOfBots(t, s, "game", scopone.GameOptions{}, 4)
+	storetest.PlayCards(t, s, g, 60)
+
+	// the program stops while appending some events and while writing a snapshot
+	l := &fileLog{dir: dir}
+	f, _ := os.OpenFile(l.fileName("game", eventsSuffix), os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"gameName":"game","seq":1000,"kind":"cardPl`)
+	f.Close()
+	os.WriteFile(l.fileName("game", snapshotSuffix+tmpSuffix), []byte(`{"gameName":"ga`), 0644)
+
+	games, players := storetest.ReadOpenGames(t, open)
+	storetest.CheckGame(t, games, players, g)
+	if _, err := os.Stat(l.fileName("game", snapshotSuffix+tmpSuffix)); !os.IsNotExist(err) {
+		t.Errorf("The temporary file of the snapshot should be removed but its state is %v", err)
+	}
+
+	// the events appended after the recovery are read
+	s = storetest.Osteria(t, open)
+	g = s.Games["game"]
+	storetest.PlayCards(t, s, g, 5)
+	games, players = storetest.ReadOpenGames(t, open)
+	storetest.CheckGame(t, games, players, g)
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		return opener(t.TempDir())
+	})
+}
+
+func TestAccounts(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("The store could not be opened: %v", err)
+	}
+	storetest.RunAccounts(t, store)
+}
@@ -0,0 +1,48 @@
+package storemongo
+
+import (
+	"context"
+	"fmt"
+
+	"go-scopone/src/auth"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const accountsCollName string = "accounts"
+
+// AddAccount adds an account to the accounts collection - the account is inserted only if there is no account
+// with the same name, in one operation, so that two players can not register the same name at the same time
+func (store *Store) AddAccount(ctx context.Context, account auth.Account) error {
+	collection := store.db.Collection(accountsCollName)
+	filter := bson.M{"name": account.Name}
+	update := bson.M{"$setOnInsert": account}
+	opts := options.Update().SetUpsert(true)
+	var res *mongo.UpdateResult
+	err := store.do(ctx, func(ctx context.Context) (err error) {
+		res, err = collection.UpdateOne(ctx, filter, update, opts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if res.UpsertedCount == 0 {
+		return fmt.Errorf("%w - \"%v\"", auth.ErrAccountAlreadyExists, account.Name)
+	}
+	return nil
+}
+
+// Account reads the account with the name passed in
+func (store *Store) Account(ctx context.Context, name string) (auth.Account, error) {
+	var account auth.Account
+	collection := store.db.Collection(accountsCollName)
+	err := store.do(ctx, func(ctx context.Context) error {
+		return collection.FindOne(ctx, bson.M{"name": name}).Decode(&account)
+	})
+	if err == mongo.ErrNoDocuments {
+		return account, fmt.Errorf("%w - \"%v\"", auth.ErrAccountNotFound, name)
+	}
+	return account, err
+}
@@ -0,0 +1,113 @@
+package storemongo
+
+import (
+	"context"
+
+	"go-scopone/src/store/storeevents"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	gameEventsCollName    string = "gameEvents"
+	gameSnapshotsCollName string = "gameSnapshots"
+)
+
+// EventLog keeps the events of the games in mongo - it implements storeevents.Log
+// Its operations are timed out and retried by the store it belongs to, see Store.do
+type EventLog struct {
+	store *Store
+	db    *mongo.Database
+}
+
+// EventLog returns the log of the events of the games kept in the db of the store
+func (store *Store) EventLog() *EventLog {
+	return &EventLog{store: store, db: store.db}
+}
+
+// Append inserts the events of a game in the events collection
+func (l *EventLog) Append(ctx context.Context, gameName string, events []storeevents.Event) error {
+	docs := make([]interface{}, len(events))
+	for i := range events {
+		docs[i] = events[i]
+	}
+	// the events are inserted in order and, since they are numbered, the events already inserted when the insert
+	// fails are inserted again with the same numbers by the next write of the game
+	return l.store.do(ctx, func(ctx context.Context) error {
+		_, err := l.db.Collection(gameEventsCollName).InsertMany(ctx, docs)
+		return err
+	})
+}
+
+// Events reads the events of a game with sequence number greater than afterSeq
+func (l *EventLog) Events(ctx context.Context, gameName string, afterSeq int) ([]storeevents.Event, error) {
+	filter := bson.M{"gamename": gameName, "seq": bson.M{"$gt": afterSeq}}
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "seq", Value: 1}})
+	events := make([]storeevents.Event, 0)
+	err := l.store.do(ctx, func(ctx context.Context) error {
+		cur, err := l.db.Collection(gameEventsCollName).Find(ctx, filter, findOptions)
+		if err != nil {
+			return err
+		}
+		return cur.All(ctx, &events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// an event inserted twice, see Append, is applied only once
+	unique := make([]storeevents.Event, 0, len(events))
+	for _, e := range events {
+		if len(unique) == 0 || unique[len(unique)-1].Seq != e.Seq {
+			unique = append(unique, e)
+		}
+	}
+	return unique, nil
+}
+
+// GameNames reads the names of the games which have events
+func (l *EventLog) GameNames(ctx context.Context) ([]string, error) {
+	var values []interface{}
+	err := l.store.do(ctx, func(ctx context.Context) (err error) {
+		values, err = l.db.Collection(gameEventsCollName).Distinct(ctx, "gamename", bson.D{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		if name, ok := v.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// WriteSnapshot replaces the snapshot of a game
+func (l *EventLog) WriteSnapshot(ctx context.Context, snapshot storeevents.Snapshot) error {
+	filter := bson.D{primitive.E{Key: "gamename", Value: snapshot.GameName}}
+	opts := options.Replace().SetUpsert(true)
+	return l.store.do(ctx, func(ctx context.Context) error {
+		_, err := l.db.Collection(gameSnapshotsCollName).ReplaceOne(ctx, filter, snapshot, opts)
+		return err
+	})
+}
+
+// ReadSnapshot reads the snapshot of a game
+func (l *EventLog) ReadSnapshot(ctx context.Context, gameName string) (storeevents.Snapshot, bool, error) {
+	var snapshot storeevents.Snapshot
+	filter := bson.D{primitive.E{Key: "gamename", Value: gameName}}
+	err := l.store.do(ctx, func(ctx context.Context) error {
+		return l.db.Collection(gameSnapshotsCollName).FindOne(ctx, filter).Decode(&snapshot)
+	})
+	if err == mongo.ErrNoDocuments {
+		return snapshot, false, nil
+	}
+	if err != nil {
+		return snapshot, false, err
+	}
+	return snapshot, true, nil
+}
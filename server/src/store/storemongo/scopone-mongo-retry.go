@@ -0,0 +1,84 @@
+package storemongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// retryPolicy tells how the operations on mongo are timed out and retried
+type retryPolicy struct {
+	// timeout is the time each attempt of an operation is given
+	timeout time.Duration
+	// attempts is the number of times an operation is tried if it fails because mongo can not be reached
+	attempts int
+	// backoff is the wait before the second attempt, doubled before each following attempt
+	backoff time.Duration
+	// cooldown is the time after an operation has failed all its attempts during which mongo is considered
+	// unavailable and the operations fail without trying
+	cooldown time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	timeout:  5 * time.Second,
+	attempts: 3,
+	backoff:  200 * time.Millisecond,
+	cooldown: 10 * time.Second,
+}
+
+// transient returns true if the error is caused by mongo not being reachable, in which case the operation can be
+// tried again
+func transient(err error) bool {
+	return mongo.IsTimeout(err) || mongo.IsNetworkError(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// do runs an operation on mongo, giving each attempt the timeout of the policy and trying again the attempts which
+// fail because mongo can not be reached
+// If all the attempts fail mongo is considered unavailable for the cooldown of the policy, so that the commands of
+// the players are not slowed down by a store which does not answer - in both cases the error returned wraps
+// scopone.ErrStoreUnavailable, see scopone.Scopone for how the Osteria degrades
+func (store *Store) do(ctx context.Context, operation func(ctx context.Context) error) error {
+	if until, unavailable := store.unavailable(); unavailable {
+		return fmt.Errorf("%w - Mongo is not tried again until %v", scopone.ErrStoreUnavailable, until.Format(time.RFC3339))
+	}
+	backoff := store.policy.backoff
+	var err error
+	for attempt := 1; attempt <= store.policy.attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, store.policy.timeout)
+		err = operation(attemptCtx)
+		cancel()
+		if err == nil || !transient(err) || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("Attempt %v of %v on mongo failed: %v", attempt, store.policy.attempts, err)
+	}
+	store.markUnavailable()
+	return fmt.Errorf("%w - %v", scopone.ErrStoreUnavailable, err)
+}
+
+// unavailable returns true, with the time until which it lasts, if mongo is considered unavailable
+func (store *Store) unavailable() (time.Time, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.unavailableUntil, time.Now().Before(store.unavailableUntil)
+}
+
+func (store *Store) markUnavailable() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.unavailableUntil = time.Now().Add(store.policy.cooldown)
+}
@@ -0,0 +1,69 @@
+package storemongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/scopone"
+)
+
+// ctx is the context of the operations of the tests
+var ctx = context.Background()
+
+func newStore() *Store {
+	return &Store{policy: retryPolicy{
+		timeout:  time.Second,
+		attempts: 3,
+		backoff:  time.Millisecond,
+		cooldown: 50 * time.Millisecond,
+	}}
+}
+
+// failing returns an operation which fails with the error passed in for the number of attempts passed in and
+// counts the attempts made
+func failing(err error, failures int, attempts *int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		*attempts++
+		if *attempts <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	store := newStore()
+	attempts := 0
+	if err := store.do(ctx, failing(context.DeadlineExceeded, 2, &attempts)); err != nil || attempts != 3 {
+		t.Errorf("An operation which times out twice should succeed at the third attempt but returns %v after %v attempts",
+			err, attempts)
+	}
+
+	attempts = 0
+	err := store.do(ctx, failing(errors.New("duplicate key"), 1, &attempts))
+	if err == nil || errors.Is(err, scopone.ErrStoreUnavailable) || attempts != 1 {
+		t.Errorf("An operation which fails with an error which is not transient should not be retried but returns %v after %v attempts",
+			err, attempts)
+	}
+}
+
+func TestUnavailableStore(t *testing.T) {
+	store := newStore()
+	attempts := 0
+	if err := store.do(ctx, failing(context.DeadlineExceeded, 10, &attempts)); !errors.Is(err, scopone.ErrStoreUnavailable) {
+		t.Errorf("An operation which fails all its attempts should return ErrStoreUnavailable but returns %v", err)
+	}
+
+	// during the cooldown the operations fail without trying
+	attempts = 0
+	if err := store.do(ctx, failing(nil, 0, &attempts)); !errors.Is(err, scopone.ErrStoreUnavailable) || attempts != 0 {
+		t.Errorf("An operation during the cooldown should fail without trying but returns %v after %v attempts", err, attempts)
+	}
+
+	time.Sleep(store.policy.cooldown)
+	if err := store.do(ctx, failing(nil, 0, &attempts)); err != nil || attempts != 1 {
+		t.Errorf("An operation after the cooldown should be tried but returns %v after %v attempts", err, attempts)
+	}
+}
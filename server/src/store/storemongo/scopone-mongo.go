@@ -5,6 +5,7 @@ import (
 	"context"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"go-scopone/src/game-logic/player"
@@ -25,11 +26,18 @@ const (
 )
 
 // Store is the mongodb reference
+// The operations are timed out and retried as its policy says, see do
 type Store struct {
-	db *mongo.Database
+	db     *mongo.Database
+	policy retryPolicy
+	// mu protects unavailableUntil
+	mu               sync.Mutex
+	unavailableUntil time.Time
 }
 
 // Connect to db
+// If mongo can not be reached the store is returned anyway and its operations fail until mongo is back, so that
+// the Osteria can start and keep its games in memory
 func Connect(ctx context.Context) *Store {
 	// Database Config
 	connString := os.Getenv("MONGO_CONNECTION")
@@ -47,16 +55,20 @@ func Connect(ctx context.Context) *Store {
 		log.Fatal("Error connecting to Mongo", err)
 	}
 
-	err = client.Ping(context.Background(), readpref.Primary())
+	pingCtx, cancel := context.WithTimeout(ctx, defaultRetryPolicy.timeout)
+	defer cancel()
+	err = client.Ping(pingCtx, readpref.Primary())
 	if err != nil {
-		log.Fatal("Couldn't connect to the database", err)
+		log.Println("Couldn't connect to the database, the games are kept in memory until it is reachable", err)
 	} else {
 		log.Println("Connected!")
 	}
-	var store = Store{
-		db: client.Database(dbname),
-	}
-	return &store
+	return New(client.Database(dbname))
+}
+
+// New returns the store kept in the db passed in
+func New(db *mongo.Database) *Store {
+	return &Store{db: db, policy: defaultRetryPolicy}
 }
 
 type playerEntry struct {
@@ -65,13 +77,15 @@ type playerEntry struct {
 }
 
 // AddPlayerEntry adds a record in the entry collection representing the fact that a player has entered the Osteria
-func (store *Store) AddPlayerEntry(player *player.Player) error {
+func (store *Store) AddPlayerEntry(ctx context.Context, player *player.Player) error {
 	entry := playerEntry{}
 	entry.Ts = time.Now()
 	entry.Player = player.Name
 	collection := store.db.Collection(playerEntriesCollName)
-	_, err := collection.InsertOne(context.TODO(), entry)
-	return err
+	return store.do(ctx, func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, entry)
+		return err
+	})
 }
 
 type mgame struct {
@@ -81,7 +95,7 @@ type mgame struct {
 }
 
 // WriteGame saves a game to mongo
-func (store *Store) WriteGame(g *scopone.Game) error {
+func (store *Store) WriteGame(ctx context.Context, g *scopone.Game) error {
 	mg := mgame{time.Now(), g}
 	collection := store.db.Collection(gamesCollName)
 	opts := options.Update().SetUpsert(true)
@@ -91,12 +105,14 @@ func (store *Store) WriteGame(g *scopone.Game) error {
 	update := bson.M{
 		"$set": mg,
 	}
-	_, err := collection.UpdateOne(context.TODO(), filter, update, opts)
-	return err
+	return store.do(ctx, func(ctx context.Context) error {
+		_, err := collection.UpdateOne(ctx, filter, update, opts)
+		return err
+	})
 }
 
 // ReadOpenGames reads from mongo all the games which are not closed
-func (store *Store) ReadOpenGames() (games map[string]*scopone.Game, players map[string]*player.Player, err error) {
+func (store *Store) ReadOpenGames(ctx context.Context) (games map[string]*scopone.Game, players map[string]*player.Player, err error) {
 	// it is important to initialize games and players because we do not want to retun nils but rather
 	// empty maps in case no player or games are found in the db
 	games = make(map[string]*scopone.Game)
@@ -107,20 +123,22 @@ func (store *Store) ReadOpenGames() (games map[string]*scopone.Game, players map
 
 	// Passing bson.D{{}} as the filter matches all documents in the collection
 	filter := bson.M{"game.state": bson.M{"$ne": "closed"}}
-	cur, err := collection.Find(context.TODO(), filter, findOptions)
+	// the games are all read in one operation, so that an attempt which fails reading them is retried from the start
+	var elems []mgame
+	err = store.do(ctx, func(ctx context.Context) error {
+		cur, err := collection.Find(ctx, filter, findOptions)
+		if err != nil {
+			return err
+		}
+		elems = nil
+		return cur.All(ctx, &elems)
+	})
 	if err != nil {
 		log.Print(err)
 		return
 	}
 
-	for cur.Next(context.TODO()) {
-		var elem mgame
-		err = cur.Decode(&elem)
-		if err != nil {
-			log.Print(err)
-			return
-		}
-
+	for _, elem := range elems {
 		g := elem.Game
 		games[elem.Game.Name] = g
 		// we need to add the players and the observers of any game restored from db to the scopone.Players mapp
@@ -154,13 +172,6 @@ func (store *Store) ReadOpenGames() (games map[string]*scopone.Game, players map
 		}
 	}
 
-	if err = cur.Err(); err != nil {
-		log.Print(err)
-		return
-	}
-
-	cur.Close(context.TODO())
-
 	return
 }
 
@@ -0,0 +1,71 @@
+package storemongo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/store/storeevents"
+	"go-scopone/src/store/storetest"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// connect connects to the mongo of the env var MONGO_CONNECTION - the tests which need mongo are skipped if it is
+// not set
+func connect(t *testing.T) *mongo.Client {
+	connString := os.Getenv("MONGO_CONNECTION")
+	if connString == "" {
+		t.Skip("MONGO_CONNECTION is not set")
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connString))
+	if err != nil {
+		t.Fatalf("Mongo could not be reached: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(ctx) })
+	return client
+}
+
+// newStoreInDB returns the function which opens a store on a new db, dropped at the end of the test
+func newStoreInDB(client *mongo.Client) storetest.NewStore {
+	return func(t *testing.T) storetest.Opener {
+		db := client.Database(fmt.Sprintf("scopone_test_%v", time.Now().UnixNano()))
+		t.Cleanup(func() { db.Drop(ctx) })
+		return func(t *testing.T) storetest.Store {
+			return New(db)
+		}
+	}
+}
+
+// eventStore saves the games as events in the event log of the store and the entries of the players in the store
+type eventStore struct {
+	*storeevents.Store
+	players *Store
+}
+
+func (store eventStore) AddPlayerEntry(ctx context.Context, player *player.Player) error {
+	return store.players.AddPlayerEntry(ctx, player)
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, newStoreInDB(connect(t)))
+}
+
+func TestConformanceOfEventLog(t *testing.T) {
+	newDocumentStore := newStoreInDB(connect(t))
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		open := newDocumentStore(t)
+		return func(t *testing.T) storetest.Store {
+			store := open(t).(*Store)
+			return eventStore{Store: storeevents.New(store.EventLog(), 7), players: store}
+		}
+	})
+}
+
+func TestAccounts(t *testing.T) {
+	storetest.RunAccounts(t, newStoreInDB(connect(t))(t)(t).(*Store))
+}
@@ -0,0 +1,39 @@
+package storesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go-scopone/src/auth"
+)
+
+// AddAccount inserts an account unless there is already an account with the same name
+func (store *Store) AddAccount(ctx context.Context, account auth.Account) error {
+	res, err := store.db.ExecContext(ctx, store.dialect.rebind(`INSERT INTO accounts (name, password_hash, created)
+		VALUES (?, ?, ?) ON CONFLICT (name) DO NOTHING`), account.Name, string(account.PasswordHash), account.Created)
+	if err != nil {
+		return err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return fmt.Errorf("%w - \"%v\"", auth.ErrAccountAlreadyExists, account.Name)
+	}
+	return nil
+}
+
+// Account reads the account with the name passed in
+func (store *Store) Account(ctx context.Context, name string) (auth.Account, error) {
+	account := auth.Account{Name: name}
+	var hash string
+	err := store.db.QueryRowContext(ctx, store.dialect.rebind(`SELECT password_hash, created FROM accounts WHERE name = ?`),
+		name).Scan(&hash, &account.Created)
+	if err == sql.ErrNoRows {
+		return account, fmt.Errorf("%w - \"%v\"", auth.ErrAccountNotFound, name)
+	}
+	account.PasswordHash = []byte(hash)
+	return account, err
+}
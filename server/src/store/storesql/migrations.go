@@ -0,0 +1,154 @@
+package storesql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations are the changes of the schema, in the order they are applied - a migration applied is never changed,
+// a change of the schema is a new migration appended to the list
+// The statements are written so that they run both on SQLite and on PostgreSQL
+var migrations = []string{
+	// 1 - the games with the players sitting at their tables, the hands dealt with the cards played and the scores
+	`CREATE TABLE games (
+		name              TEXT PRIMARY KEY,
+		state             TEXT NOT NULL,
+		closed_by         TEXT NOT NULL,
+		target_score      INTEGER NOT NULL,
+		variant           TEXT NOT NULL,
+		number_of_players INTEGER NOT NULL,
+		seed              BIGINT NOT NULL
+	);
+	CREATE TABLE seats (
+		game_name   TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		seat        INTEGER NOT NULL,
+		player_name TEXT NOT NULL,
+		bot         TEXT NOT NULL,
+		PRIMARY KEY (game_name, seat)
+	);
+	CREATE TABLE observers (
+		game_name   TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		player_name TEXT NOT NULL,
+		PRIMARY KEY (game_name, player_name)
+	);
+	CREATE TABLE hands (
+		game_name  TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		hand_index INTEGER NOT NULL,
+		state      TEXT NOT NULL,
+		seed       BIGINT NOT NULL,
+		deck       TEXT NOT NULL,
+		PRIMARY KEY (game_name, hand_index)
+	);
+	CREATE TABLE card_plays (
+		game_name   TEXT NOT NULL,
+		hand_index  INTEGER NOT NULL,
+		play_index  INTEGER NOT NULL,
+		player_name TEXT NOT NULL,
+		card_type   TEXT NOT NULL,
+		card_suit   TEXT NOT NULL,
+		cards_taken TEXT NOT NULL,
+		PRIMARY KEY (game_name, hand_index, play_index),
+		FOREIGN KEY (game_name, hand_index) REFERENCES hands(game_name, hand_index) ON DELETE CASCADE
+	);
+	CREATE TABLE scores (
+		game_name      TEXT NOT NULL,
+		hand_index     INTEGER NOT NULL,
+		team_name      TEXT NOT NULL,
+		score          INTEGER NOT NULL,
+		primiera_score INTEGER NOT NULL,
+		scope          INTEGER NOT NULL,
+		PRIMARY KEY (game_name, hand_index, team_name),
+		FOREIGN KEY (game_name, hand_index) REFERENCES hands(game_name, hand_index) ON DELETE CASCADE
+	);
+	CREATE TABLE player_entries (
+		ts          TIMESTAMP NOT NULL,
+		player_name TEXT NOT NULL
+	);`,
+	// 2 - the accounts of the players
+	`CREATE TABLE accounts (
+		name          TEXT PRIMARY KEY,
+		password_hash TEXT NOT NULL,
+		created       TIMESTAMP NOT NULL
+	);`,
+	// 3 - the seats taken by the players who have replaced the players seated before, with the number of hands dealt
+	// and of plays of the last hand when the seat has been taken
+	`CREATE TABLE seat_changes (
+		game_name       TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		change_index    INTEGER NOT NULL,
+		seat            INTEGER NOT NULL,
+		player_name     TEXT NOT NULL,
+		bot             TEXT NOT NULL,
+		replaced_player TEXT NOT NULL,
+		hands           INTEGER NOT NULL,
+		plays           INTEGER NOT NULL,
+		PRIMARY KEY (game_name, change_index)
+	);`,
+	// 4 - the time limit of the moves of the games
+	`ALTER TABLE games ADD COLUMN move_time_limit INTEGER NOT NULL DEFAULT 0;`,
+	// 5 - the players ready to start the game
+	`ALTER TABLE seats ADD COLUMN ready BOOLEAN NOT NULL DEFAULT FALSE;`,
+	// 6 - the seats left in the middle of the game, which are changes of the seats as the seats taken, and the
+	// players who have voted to abandon the game
+	`ALTER TABLE seat_changes ADD COLUMN kind TEXT NOT NULL DEFAULT 'seatTaken';
+	CREATE TABLE abandon_votes (
+		game_name   TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		player_name TEXT NOT NULL,
+		PRIMARY KEY (game_name, player_name)
+	);`,
+	// 7 - what the observers of the games see of the hands
+	`ALTER TABLE games ADD COLUMN observer_mode TEXT NOT NULL DEFAULT '';
+	ALTER TABLE games ADD COLUMN observer_delay INTEGER NOT NULL DEFAULT 0;`,
+	// 8 - the password of the private games and the players invited to them
+	`ALTER TABLE games ADD COLUMN password TEXT NOT NULL DEFAULT '';
+	CREATE TABLE invitations (
+		game_name   TEXT NOT NULL REFERENCES games(name) ON DELETE CASCADE,
+		player_name TEXT NOT NULL,
+		PRIMARY KEY (game_name, player_name)
+	);`,
+	// 9 - the games whose teams can not chat while a hand is played
+	`ALTER TABLE games ADD COLUMN no_team_chat_in_hands BOOLEAN NOT NULL DEFAULT FALSE;`,
+	// 10 - the bcrypt hash of the password of the private games, which replaces the password, emptied when the game
+	// is written again
+	`ALTER TABLE games ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';`,
+}
+
+// migrate applies to the db the migrations not applied yet, each one in its own transaction
+func migrate(db *sql.DB, dialect dialect) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	var version int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return err
+	}
+	for v := version + 1; v <= len(migrations); v++ {
+		err = applyMigration(db, dialect, v)
+		if err != nil {
+			return fmt.Errorf("Migration %v of the schema failed: %v", v, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, dialect dialect, version int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(migrations[version-1])
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(dialect.rebind(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`), version, time.Now())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
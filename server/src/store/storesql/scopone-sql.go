@@ -0,0 +1,573 @@
+// Package storesql implements the store using a relational database, SQLite or PostgreSQL
+// The games are saved in a normalised schema - the games, the seats of their players, the hands dealt with the
+// cards played and the scores of the hands - and are rebuilt playing again their hands, see storeevents.Fold
+package storesql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-scopone/src/game-logic/deck"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/store/storeevents"
+)
+
+// dialect tells the differences among the databases supported
+type dialect string
+
+const (
+	sqlite   dialect = "sqlite3"
+	postgres dialect = "postgres"
+)
+
+// rebind replaces the ? placeholders of a query with the placeholders of the dialect
+func (d dialect) rebind(query string) string {
+	if d != postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%v", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Store is the relational database - it implements scopone.PlayerWriter, scopone.GameReadWriter and
+// auth.AccountStore
+type Store struct {
+	db      *sql.DB
+	dialect dialect
+	// mu protects written since the games are written in parallel - it is held only to read and to set written, so
+	// that the transactions of different games run in parallel, while SQLite runs them one at a time on its only
+	// connection, see Open
+	mu sync.Mutex
+	// written tells, for each game, what has already been written of its hands
+	written map[string]progress
+}
+
+// progress is what has been written of the seats and of the hands of a game
+type progress struct {
+	// seats are the names of the players in the seats written, with an empty name for the seats not taken yet
+	seats []string
+	// changes is the number of seats taken by players who have replaced the players seated before and of seats left
+	changes int
+	// seatsLeft are the players written as having left their seat in the middle of the game
+	seatsLeft map[string]bool
+	hands     int
+	// plays is the number of plays of the last hand written
+	plays int
+}
+
+// Open connects to the database with the driver passed in, which has to be registered by the program, and
+// migrates its schema to the last version
+// The drivers supported are "sqlite3" (github.com/mattn/go-sqlite3) and "postgres" (github.com/lib/pq)
+func Open(driverName string, dataSourceName string) (*Store, error) {
+	d := dialect(driverName)
+	if d != sqlite && d != postgres {
+		return nil, fmt.Errorf("The driver %v is not supported", driverName)
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if d == sqlite {
+		// SQLite allows one writer at a time, so the connections are not shared among goroutines which write
+		db.SetMaxOpenConns(1)
+	}
+	err = migrate(db, d)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db, dialect: d, written: make(map[string]progress)}, nil
+}
+
+// Close closes the database
+func (store *Store) Close() error {
+	return store.db.Close()
+}
+
+// AddPlayerEntry adds a row to the entries representing the fact that a player has entered the Osteria
+func (store *Store) AddPlayerEntry(ctx context.Context, player *player.Player) error {
+	_, err := store.db.ExecContext(ctx, store.dialect.rebind(`INSERT INTO player_entries (ts, player_name) VALUES (?, ?)`),
+		time.Now(), player.Name)
+	return err
+}
+
+// WriteGame saves a game in one transaction - only the hands and the card plays not yet written are inserted
+func (store *Store) WriteGame(ctx context.Context, g *scopone.Game) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	w := writer{ctx: ctx, tx: tx, dialect: store.dialect, game: g}
+	store.mu.Lock()
+	written, found := store.written[g.Name]
+	store.mu.Unlock()
+	if len(g.Players) == 0 && len(g.Hands) == 0 {
+		// the game is new and it can have the name of a game which has been closed, which is therefore removed
+		w.exec(`DELETE FROM invitations WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM abandon_votes WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM seat_changes WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM scores WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM card_plays WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM hands WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM observers WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM seats WHERE game_name = ?`, g.Name)
+		w.exec(`DELETE FROM games WHERE name = ?`, g.Name)
+		written, found = progress{}, true
+	}
+	if !found {
+		// the game has not been written by this store yet, so the rows already written are skipped by the inserts
+		written = progress{}
+	}
+	// only the hash of the password is written, and the password written by the previous versions is emptied
+	w.exec(`INSERT INTO games (name, state, closed_by, target_score, variant, number_of_players, seed, move_time_limit,
+		observer_mode, observer_delay, password, password_hash, no_team_chat_in_hands)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', ?, ?)
+		ON CONFLICT (name) DO UPDATE SET state = excluded.state, closed_by = excluded.closed_by, password = '',
+		password_hash = excluded.password_hash`,
+		g.Name, string(g.State), g.ClosedBy, g.TargetScore, string(g.Variant), g.NumberOfPlayers, g.Seed, g.MoveTimeLimit,
+		string(g.ObserverMode), g.ObserverDelay, string(g.PasswordHash), g.NoTeamChatInHands)
+	w.writeInvitations()
+	// before the first hand is written the players can still change their seats, which are written as they are
+	seats, changes := w.writeSeats(written, len(g.Hands) == 0 || (found && written.hands == 0))
+	seatsLeft, changes := w.writeSeatsLeft(written, changes)
+	w.writeObservers()
+	w.writeAbandonVotes()
+	next := w.writeHands(written)
+	next.seats, next.changes, next.seatsLeft = seats, changes, seatsLeft
+	if w.err != nil {
+		return w.err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	store.mu.Lock()
+	store.written[g.Name] = next
+	store.mu.Unlock()
+	return nil
+}
+
+// writer writes the rows of a game in a transaction - after a statement fails the following ones are skipped and
+// the error is kept
+type writer struct {
+	ctx     context.Context
+	tx      *sql.Tx
+	dialect dialect
+	game    *scopone.Game
+	err     error
+}
+
+func (w *writer) exec(query string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.tx.ExecContext(w.ctx, w.dialect.rebind(query), args...)
+}
+
+// writeSeats writes the players in the seats they have taken joining the game, see Game.AddPlayer, and the seats
+// taken by players who have replaced the players written, see Scopone.TakeSeat, and returns the names of the
+// players in the seats and the number of seats taken written
+// The seats table keeps the first player of each seat, so that the games are rebuilt applying the seats taken when
+// they have been taken - while the teams are forming the seats are written again as they are, since the players
+// can swap them or be kicked out of them, together with the players who are ready, see Scopone.SwapSeats
+func (w *writer) writeSeats(written progress, forming bool) ([]string, int) {
+	g := w.game
+	seats := make([]string, 0)
+	changes := written.changes
+	if forming {
+		w.exec(`DELETE FROM seats WHERE game_name = ?`, g.Name)
+		written.seats = nil
+	}
+	seat := 0
+	for _, t := range g.Teams {
+		for _, p := range t.Players {
+			if p == nil {
+				seats = append(seats, "")
+				seat++
+				continue
+			}
+			seats = append(seats, p.Name)
+			w.exec(`INSERT INTO seats (game_name, seat, player_name, bot, ready) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (game_name, seat) DO NOTHING`, g.Name, seat, p.Name, p.Bot, g.Ready[p.Name])
+			if seat < len(written.seats) && written.seats[seat] != "" && written.seats[seat] != p.Name {
+				w.writeSeatChange(changes, storeevents.SeatTaken, seat, p, written.seats[seat])
+				changes++
+			}
+			seat++
+		}
+	}
+	return seats, changes
+}
+
+// writeSeatsLeft writes, as changes of the seats, the seats left in the middle of the game and the seats taken back
+// by the players who have left them, see Scopone.LeaveSeat, and returns the players who have left their seat and
+// the number of changes of the seats written
+func (w *writer) writeSeatsLeft(written progress, changes int) (map[string]bool, int) {
+	g := w.game
+	seatsLeft := make(map[string]bool)
+	for _, pName := range sortedKeys(written.seatsLeft) {
+		seat, seated := g.SeatOf(pName)
+		if !g.SeatsLeft[pName] && seated {
+			w.writeSeatChange(changes, storeevents.SeatTaken, seat, g.Players[pName], pName)
+			changes++
+		}
+	}
+	for _, pName := range sortedKeys(g.SeatsLeft) {
+		seatsLeft[pName] = true
+		if written.seatsLeft[pName] {
+			continue
+		}
+		seat, _ := g.SeatOf(pName)
+		w.writeSeatChange(changes, storeevents.SeatLeft, seat, g.Players[pName], "")
+		changes++
+	}
+	return seatsLeft, changes
+}
+
+// writeSeatChange writes a change of the seat with the number of hands dealt and of plays of the last hand when the
+// change has happened
+func (w *writer) writeSeatChange(change int, kind storeevents.Kind, seat int, p *player.Player, replaced string) {
+	g := w.game
+	hands, plays := len(g.Hands), 0
+	if hands > 0 {
+		plays = len(g.Hands[hands-1].History.CardPlaySequence)
+	}
+	w.exec(`INSERT INTO seat_changes (game_name, change_index, kind, seat, player_name, bot, replaced_player, hands, plays)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, g.Name, change, string(kind), seat, p.Name, p.Bot, replaced, hands, plays)
+}
+
+func (w *writer) writeObservers() {
+	w.exec(`DELETE FROM observers WHERE game_name = ?`, w.game.Name)
+	for oName := range w.game.Observers {
+		w.exec(`INSERT INTO observers (game_name, player_name) VALUES (?, ?)`, w.game.Name, oName)
+	}
+}
+
+// writeInvitations writes the players invited to the game, who do not change once the game is created
+func (w *writer) writeInvitations() {
+	for _, pName := range w.game.Invited {
+		w.exec(`INSERT INTO invitations (game_name, player_name) VALUES (?, ?) ON CONFLICT DO NOTHING`, w.game.Name, pName)
+	}
+}
+
+func (w *writer) writeAbandonVotes() {
+	w.exec(`DELETE FROM abandon_votes WHERE game_name = ?`, w.game.Name)
+	for pName := range w.game.AbandonVotes {
+		w.exec(`INSERT INTO abandon_votes (game_name, player_name) VALUES (?, ?)`, w.game.Name, pName)
+	}
+}
+
+// writeHands writes the hands and the card plays not written yet and returns what has been written after that
+func (w *writer) writeHands(written progress) progress {
+	g := w.game
+	start := written.hands - 1
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(g.Hands); i++ {
+		hand := g.Hands[i]
+		w.exec(`INSERT INTO hands (game_name, hand_index, state, seed, deck) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (game_name, hand_index) DO UPDATE SET state = excluded.state`,
+			g.Name, i, string(hand.State), hand.History.Seed, encodeCards(hand.History.Deck))
+		plays := hand.History.CardPlaySequence
+		from := 0
+		if i == written.hands-1 {
+			from = written.plays
+		}
+		for j := from; j < len(plays); j++ {
+			w.exec(`INSERT INTO card_plays (game_name, hand_index, play_index, player_name, card_type, card_suit, cards_taken)
+				VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (game_name, hand_index, play_index) DO NOTHING`,
+				g.Name, i, j, plays[j].Player, plays[j].CardPlayed.Type, plays[j].CardPlayed.Suit,
+				encodeCards(plays[j].CardsTaken))
+		}
+		if hand.State == scopone.HandClosed {
+			for teamName, score := range hand.Score {
+				w.exec(`INSERT INTO scores (game_name, hand_index, team_name, score, primiera_score, scope)
+					VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (game_name, hand_index, team_name) DO NOTHING`,
+					g.Name, i, teamName, score.Score, score.PrimieraScore, len(score.ScoreCard.Scope))
+			}
+		}
+	}
+	next := progress{hands: len(g.Hands)}
+	if len(g.Hands) > 0 {
+		next.plays = len(g.Hands[len(g.Hands)-1].History.CardPlaySequence)
+	}
+	return next
+}
+
+func encodeCards(cards []deck.Card) string {
+	if cards == nil {
+		cards = []deck.Card{}
+	}
+	data, _ := json.Marshal(cards)
+	return string(data)
+}
+
+func decodeCards(data string) ([]deck.Card, error) {
+	cards := make([]deck.Card, 0)
+	err := json.Unmarshal([]byte(data), &cards)
+	return cards, err
+}
+
+// ReadOpenGames reads from the database all the games which are not closed - the players and the observers of the
+// games are returned with status PlayerLeftOsteria and each player is the same instance in Game.Players and in
+// Game.Teams, as storemongo does
+// A game which can not be rebuilt is left out and the error is logged, so that the other games can be played
+func (store *Store) ReadOpenGames(ctx context.Context) (games map[string]*scopone.Game, players map[string]*player.Player, err error) {
+	// it is important to initialize games and players because we do not want to retun nils but rather
+	// empty maps in case no player or games are found in the db
+	games = make(map[string]*scopone.Game)
+	players = make(map[string]*player.Player)
+
+	rows, err := store.db.QueryContext(ctx, store.dialect.rebind(`SELECT name, target_score, variant, number_of_players, seed,
+		move_time_limit, observer_mode, observer_delay, password, password_hash, no_team_chat_in_hands FROM games
+		WHERE state <> ?`), string(scopone.GameClosed))
+	if err != nil {
+		return
+	}
+	created := make([]storeevents.Event, 0)
+	for rows.Next() {
+		e := storeevents.Event{Kind: storeevents.GameCreated, Seq: 1}
+		var variant, observerMode, passwordHash string
+		// the games written by the previous versions have the password instead of its hash, which is hashed when
+		// the game is created again
+		err = rows.Scan(&e.GameName, &e.Options.TargetScore, &variant, &e.Options.NumberOfPlayers, &e.Options.Seed,
+			&e.Options.MoveTimeLimit, &observerMode, &e.Options.ObserverDelay, &e.Options.Password, &passwordHash,
+			&e.Options.NoTeamChatInHands)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		e.Options.Variant = scopone.Variant(variant)
+		e.Options.ObserverMode = scopone.ObserverMode(observerMode)
+		if passwordHash != "" {
+			e.Options.PasswordHash = []byte(passwordHash)
+		}
+		created = append(created, e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	for _, gameCreated := range created {
+		g, changes, e := store.readGame(ctx, gameCreated)
+		if e != nil {
+			log.Printf("Game %v can not be read: %v", gameCreated.GameName, e)
+			continue
+		}
+		games[g.Name] = g
+		next := progress{seats: seatNames(g), changes: changes, seatsLeft: make(map[string]bool), hands: len(g.Hands)}
+		for pName := range g.SeatsLeft {
+			next.seatsLeft[pName] = true
+		}
+		if len(g.Hands) > 0 {
+			next.plays = len(g.Hands[len(g.Hands)-1].History.CardPlaySequence)
+		}
+		store.mu.Lock()
+		store.written[g.Name] = next
+		store.mu.Unlock()
+		// the players and the observers of the games read are not in the Osteria until they enter it again
+		for _, p := range g.Players {
+			p.Status = player.PlayerLeftOsteria
+			players[p.Name] = p
+		}
+		for _, o := range g.Observers {
+			o.Status = player.PlayerLeftOsteria
+			players[o.Name] = o
+		}
+	}
+	return
+}
+
+// seatChange is a seat taken by a player who has replaced the player seated before, or a seat left - it has changed
+// after the number of hands dealt and the number of plays of the last hand
+type seatChange struct {
+	event storeevents.Event
+	hands int
+	plays int
+}
+
+// readGame reads the rows of a game as the events which have made the game and rebuilds it folding them - it
+// returns also the number of changes of the seats, see writeSeatsLeft
+func (store *Store) readGame(ctx context.Context, gameCreated storeevents.Event) (*scopone.Game, int, error) {
+	gName := gameCreated.GameName
+	err := store.query(ctx, `SELECT player_name FROM invitations WHERE game_name = ? ORDER BY player_name`,
+		func(rows *sql.Rows) error {
+			var pName string
+			err := rows.Scan(&pName)
+			gameCreated.Options.Invited = append(gameCreated.Options.Invited, pName)
+			return err
+		}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	events := []storeevents.Event{gameCreated}
+	add := func(e storeevents.Event) {
+		e.GameName = gName
+		e.Seq = len(events) + 1
+		events = append(events, e)
+	}
+	changes := make([]seatChange, 0)
+	err = store.query(ctx, `SELECT kind, player_name, bot, replaced_player, hands, plays FROM seat_changes
+		WHERE game_name = ? ORDER BY change_index`, func(rows *sql.Rows) error {
+		c := seatChange{}
+		var kind string
+		err := rows.Scan(&kind, &c.event.PlayerName, &c.event.Bot, &c.event.ReplacedPlayer, &c.hands, &c.plays)
+		c.event.Kind = storeevents.Kind(kind)
+		changes = append(changes, c)
+		return err
+	}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	// addChangesBefore adds the seats taken before the play, with the index passed in, of the hand with the number
+	// of hands dealt passed in
+	addChangesBefore := func(hands int, plays int) {
+		for len(changes) > 0 && (changes[0].hands < hands || changes[0].hands == hands && changes[0].plays <= plays) {
+			add(changes[0].event)
+			changes = changes[1:]
+		}
+	}
+	total := len(changes)
+	ready := make([]string, 0)
+	err = store.query(ctx, `SELECT seat, player_name, bot, ready FROM seats WHERE game_name = ? ORDER BY seat`,
+		func(rows *sql.Rows) error {
+			var seat int
+			var isReady bool
+			e := storeevents.Event{Kind: storeevents.PlayerJoined}
+			err := rows.Scan(&seat, &e.PlayerName, &e.Bot, &isReady)
+			e.Seat = &seat
+			add(e)
+			if isReady {
+				ready = append(ready, e.PlayerName)
+			}
+			return err
+		}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, pName := range ready {
+		add(storeevents.Event{Kind: storeevents.PlayerReady, PlayerName: pName})
+	}
+	err = store.query(ctx, `SELECT player_name FROM observers WHERE game_name = ? ORDER BY player_name`, func(rows *sql.Rows) error {
+		e := storeevents.Event{Kind: storeevents.ObserverJoined}
+		err := rows.Scan(&e.PlayerName)
+		add(e)
+		return err
+	}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	// the plays are read together with their hands, which have no plays when they have just been dealt
+	err = store.query(ctx, `SELECT h.hand_index, h.seed, h.deck, p.play_index, p.player_name, p.card_type, p.card_suit, p.cards_taken
+		FROM hands h LEFT JOIN card_plays p ON p.game_name = h.game_name AND p.hand_index = h.hand_index
+		WHERE h.game_name = ? ORDER BY h.hand_index, p.play_index`, func(rows *sql.Rows) error {
+		var handIndex int
+		var seed int64
+		var deckDealt string
+		var playIndex sql.NullInt64
+		var pName, cardType, cardSuit, cardsTaken sql.NullString
+		err := rows.Scan(&handIndex, &seed, &deckDealt, &playIndex, &pName, &cardType, &cardSuit, &cardsTaken)
+		if err != nil {
+			return err
+		}
+		if !playIndex.Valid || playIndex.Int64 == 0 {
+			cards, err := decodeCards(deckDealt)
+			if err != nil {
+				return err
+			}
+			addChangesBefore(handIndex, math.MaxInt32)
+			add(storeevents.Event{Kind: storeevents.HandDealt, Deck: cards, Seed: seed})
+		}
+		// the cards left on the table at the end of the hand are taken without any card played and the take
+		// happens again when the last card is played
+		if !playIndex.Valid || cardType.String == "" {
+			return nil
+		}
+		taken, err := decodeCards(cardsTaken.String)
+		if err != nil {
+			return err
+		}
+		addChangesBefore(handIndex+1, int(playIndex.Int64))
+		add(storeevents.Event{Kind: storeevents.CardPlayed, PlayerName: pName.String,
+			CardPlayed: deck.Card{Type: cardType.String, Suit: cardSuit.String}, CardsTaken: taken})
+		return nil
+	}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	addChangesBefore(math.MaxInt32, 0)
+	// the votes to abandon the game are cast when no card can be played any more by the players who vote
+	err = store.query(ctx, `SELECT player_name FROM abandon_votes WHERE game_name = ? ORDER BY player_name`,
+		func(rows *sql.Rows) error {
+			e := storeevents.Event{Kind: storeevents.AbandonVoted}
+			err := rows.Scan(&e.PlayerName)
+			add(e)
+			return err
+		}, gName)
+	if err != nil {
+		return nil, 0, err
+	}
+	g, err := storeevents.Fold(nil, events)
+	return g, total, err
+}
+
+// seatNames returns the names of the players in the seats of the game, with an empty name for the seats not taken
+func seatNames(g *scopone.Game) []string {
+	names := make([]string, 0)
+	for _, t := range g.Teams {
+		for _, p := range t.Players {
+			if p == nil {
+				names = append(names, "")
+				continue
+			}
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// query runs a query and passes its rows, one at a time, to the function
+func (store *Store) query(ctx context.Context, query string, scan func(rows *sql.Rows) error, args ...interface{}) error {
+	rows, err := store.db.QueryContext(ctx, store.dialect.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = scan(rows)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package storesql
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scopone/src/game-logic/scopone"
+	"go-scopone/src/store/storetest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openStore opens the store on the database file passed in and closes it at the end of the test
+func openStore(t *testing.T, dbFile string) *Store {
+	store, err := Open("sqlite3", dbFile)
+	if err != nil {
+		t.Fatalf("The store could not be opened: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestMigrations(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "scopone.db")
+	openStore(t, dbFile)
+	store := openStore(t, dbFile)
+	var versions int
+	store.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&versions)
+	if versions != len(migrations) {
+		t.Errorf("Each migration should be applied once but there are %v migrations applied", versions)
+	}
+}
+
+func TestGamesWrittenInParallel(t *testing.T) {
+	store := openStore(t, filepath.Join(t.TempDir(), "scopone.db"))
+	s := scopone.New(context.Background(), &scopone.DoNothingStore{}, &scopone.DoNothingStore{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		g := storetest.NewGameOfBots(t, s, fmt.Sprintf("game %v", i), scopone.GameOptions{}, 4)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.WriteGame(context.Background(), g); err != nil {
+				t.Errorf("Game %v could not be written: %v", g.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	games, _, err := store.ReadOpenGames(context.Background())
+	if err != nil || len(games) != 8 {
+		t.Errorf("8 games should be read but %v are read with error %v", len(games), err)
+	}
+}
+
+func TestPasswordOfPrivateGames(t *testing.T) {
+	store := openStore(t, filepath.Join(t.TempDir(), "scopone.db"))
+	s := scopone.New(context.Background(), &scopone.DoNothingStore{}, store)
+	storetest.NewGameOfBots(t, s, "game", scopone.GameOptions{Password: "secret"}, 2)
+	var password, passwordHash string
+	store.db.QueryRow(`SELECT password, password_hash FROM games WHERE name = 'game'`).Scan(&password, &passwordHash)
+	if password != "" || passwordHash == "" || strings.Contains(passwordHash, "secret") {
+		t.Errorf("Only the hash of the password should be written but the password is %q and the hash %q", password,
+			passwordHash)
+	}
+
+	// the password written by the previous versions is hashed when the game is read and emptied when it is written
+	store.db.Exec(`UPDATE games SET password = 'secret', password_hash = '' WHERE name = 'game'`)
+	s = scopone.New(context.Background(), &scopone.DoNothingStore{}, store)
+	g := s.Games["game"]
+	if err := g.Admits("p1", "secret"); err != nil {
+		t.Errorf("The game read should admit the players who know its password but the error is %v", err)
+	}
+	store.WriteGame(context.Background(), g)
+	store.db.QueryRow(`SELECT password, password_hash FROM games WHERE name = 'game'`).Scan(&password, &passwordHash)
+	if password != "" || passwordHash == "" {
+		t.Errorf("The password should be replaced by its hash but the password is %q and the hash %q", password,
+			passwordHash)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `INSERT INTO t (a, b) VALUES (?, ?)`
+	if q := postgres.rebind(query); q != `INSERT INTO t (a, b) VALUES ($1, $2)` {
+		t.Errorf("The placeholders of postgres should be numbered but the query is %v", q)
+	}
+	if q := sqlite.rebind(query); q != query {
+		t.Errorf("The placeholders of sqlite should not change but the query is %v", q)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		dbFile := filepath.Join(t.TempDir(), "scopone.db")
+		return func(t *testing.T) storetest.Store {
+			return openStore(t, dbFile)
+		}
+	})
+}
+
+func TestAccounts(t *testing.T) {
+	storetest.RunAccounts(t, openStore(t, filepath.Join(t.TempDir(), "scopone.db")))
+}
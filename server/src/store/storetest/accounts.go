@@ -0,0 +1,37 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-scopone/src/auth"
+)
+
+// RunAccounts checks that a store keeps the accounts added and does not add two accounts with the same name - the
+// store passed in has to have no accounts
+func RunAccounts(t *testing.T, store auth.AccountStore) {
+	ctx := context.Background()
+	account := auth.Account{Name: "p1", PasswordHash: []byte("$2a$10$hash"), Created: time.Now().Truncate(time.Second)}
+	if err := store.AddAccount(ctx, account); err != nil {
+		t.Fatalf("The account could not be added: %v", err)
+	}
+	again := account
+	again.PasswordHash = []byte("$2a$10$another hash")
+	if err := store.AddAccount(ctx, again); !errors.Is(err, auth.ErrAccountAlreadyExists) {
+		t.Errorf("Adding an account twice should return ErrAccountAlreadyExists but returns %v", err)
+	}
+
+	read, err := store.Account(ctx, "p1")
+	if err != nil {
+		t.Fatalf("The account could not be read: %v", err)
+	}
+	if read.Name != account.Name || string(read.PasswordHash) != string(account.PasswordHash) ||
+		!read.Created.Equal(account.Created) {
+		t.Errorf("The account read %v should be the account added %v", read, account)
+	}
+	if _, err := store.Account(ctx, "p2"); !errors.Is(err, auth.ErrAccountNotFound) {
+		t.Errorf("Reading an account which does not exist should return ErrAccountNotFound but returns %v", err)
+	}
+}
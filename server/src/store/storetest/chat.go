@@ -0,0 +1,65 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-scopone/src/server/chat"
+)
+
+// RunChat checks that a store keeps the messages of each channel of the chat in the order they are sent, counts the
+// messages sent by each player and clears the channels of a game - the store passed in has to have no messages
+func RunChat(t *testing.T, store chat.Store) {
+	ctx := context.Background()
+	table := chat.Channel{Kind: chat.Table, GameName: "game"}
+	team := chat.Channel{Kind: chat.Team, GameName: "game", Team: 1}
+	start := time.Now().Truncate(time.Millisecond)
+	for i := 0; i < 3; i++ {
+		for _, channel := range []chat.Channel{chat.LobbyChannel, table, team} {
+			msg := chat.Message{Channel: channel, Sender: fmt.Sprintf("p%v", i), Text: fmt.Sprintf("message %v", i),
+				Sent: start.Add(time.Duration(i) * time.Second)}
+			if err := store.AddMessage(ctx, msg); err != nil {
+				t.Fatalf("The message could not be added: %v", err)
+			}
+		}
+	}
+
+	messages, err := store.Messages(ctx, table, 2)
+	if err != nil {
+		t.Fatalf("The messages could not be read: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Text != "message 1" || messages[1].Text != "message 2" {
+		t.Errorf("The last 2 messages of the table should be read from the oldest one but are %v", messages)
+	}
+	if messages[0].Channel != table || messages[0].Sender != "p1" || !messages[0].Sent.Equal(start.Add(time.Second)) {
+		t.Errorf("The message read %v should be the message added", messages[0])
+	}
+	if messages, _ := store.Messages(ctx, chat.Channel{Kind: chat.Team, GameName: "game"}, 10); len(messages) != 0 {
+		t.Errorf("The channel of another team should have no messages but has %v", messages)
+	}
+
+	sent, err := store.SentSince(ctx, "p2", start.Add(time.Second))
+	if err != nil {
+		t.Fatalf("The messages sent could not be counted: %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("p2 should have sent 3 messages but has sent %v", sent)
+	}
+	if sent, _ := store.SentSince(ctx, "p0", start.Add(time.Second)); sent != 0 {
+		t.Errorf("p0 should have sent no messages since then but has sent %v", sent)
+	}
+
+	if err := store.ClearGame(ctx, "game"); err != nil {
+		t.Fatalf("The channels of the game could not be cleared: %v", err)
+	}
+	for _, channel := range []chat.Channel{table, team} {
+		if messages, _ := store.Messages(ctx, channel, 10); len(messages) != 0 {
+			t.Errorf("The channel %v of the game cleared should have no messages but has %v", channel, messages)
+		}
+	}
+	if messages, _ := store.Messages(ctx, chat.LobbyChannel, 10); len(messages) != 3 {
+		t.Errorf("The lobby should keep its 3 messages when a game is cleared but has %v", messages)
+	}
+}
@@ -0,0 +1,490 @@
+// Package storetest checks that a store behaves as the Osteria expects, so that every implementation of
+// scopone.PlayerWriter and scopone.GameReadWriter can run the same tests
+// A store passes the tests if the games it reads back are the games written, with the same hands, cards and scores,
+// without the closed games, and with players which
+// - have status PlayerLeftOsteria, since they have to enter the Osteria again
+// - are the same instances in Game.Players, in Game.Teams and in the players returned, observers included
+// The helpers used by the tests, such as NewGameOfBots, PlayCards and CheckGame, are exported so that the tests of
+// each store can play and check its games the same way
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go-scopone/src/game-logic/bot"
+	"go-scopone/src/game-logic/player"
+	"go-scopone/src/game-logic/scopone"
+)
+
+// Store is a store under test
+type Store interface {
+	scopone.PlayerWriter
+	scopone.GameReadWriter
+}
+
+// Opener opens the store under test on its data - it is called more than once on the same data, as a server does
+// when it is restarted
+type Opener func(t *testing.T) Store
+
+// NewStore creates a new empty data set for the store under test and returns the function which opens the store on it
+type NewStore func(t *testing.T) Opener
+
+// Run runs all the tests of the package, each one on a new store
+func Run(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, open Opener)
+	}{
+		{"GameMidHand", testGameMidHand},
+		{"GameWaitingForPlayers", testGameWaitingForPlayers},
+		{"ClosedGames", testClosedGames},
+		{"Observers", testObservers},
+		{"PlayerEntries", testPlayerEntries},
+		{"GameReadIsPlayedOn", testGameReadIsPlayedOn},
+		{"SeatTaken", testSeatTaken},
+		{"TeamsForming", testTeamsForming},
+		{"SeatLeftAndAbandon", testSeatLeftAndAbandon},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore(t))
+		})
+	}
+}
+
+// Osteria returns an Osteria which uses the store opened - the bots of the games read are given back their
+// strategies so that they can go on playing
+func Osteria(t *testing.T, open Opener) *scopone.Scopone {
+	store := open(t)
+	s := scopone.New(context.Background(), store, store)
+	bot.RestoreBots(s)
+	return s
+}
+
+// ReadOpenGames opens the store again and reads its games
+func ReadOpenGames(t *testing.T, open Opener) (map[string]*scopone.Game, map[string]*player.Player) {
+	games, players, err := open(t).ReadOpenGames(context.Background())
+	if err != nil {
+		t.Fatalf("The games could not be read: %v", err)
+	}
+	if games == nil || players == nil {
+		t.Fatalf("The games and the players read should not be nil")
+	}
+	return games, players
+}
+
+// NewGameOfBots creates a game whose first seats are taken by bots
+func NewGameOfBots(t *testing.T, s *scopone.Scopone, gName string, options scopone.GameOptions, bots int) *scopone.Game {
+	t.Helper()
+	g, err := s.NewGame(context.Background(), gName, options)
+	if err != nil {
+		t.Fatalf("Game %v could not be created: %v", gName, err)
+	}
+	for i := 0; i < bots; i++ {
+		if _, err := s.AddBotToGame(context.Background(), gName, &bot.Heuristic{}); err != nil {
+			t.Fatalf("A bot could not be added to game %v: %v", gName, err)
+		}
+	}
+	return g
+}
+
+// PlayCards makes the bots of the game play the number of cards passed in, starting a new hand when needed
+func PlayCards(t *testing.T, s *scopone.Scopone, g *scopone.Game, cards int) {
+	t.Helper()
+	for i := 0; i < cards; i++ {
+		if !s.IsBotTurn(g) {
+			if _, _, err := s.NewHand(context.Background(), g); err != nil {
+				t.Fatalf("A new hand of game %v could not be started: %v", g.Name, err)
+			}
+		}
+		if _, move, _, _, err := s.PlayBot(context.Background(), g); err != nil {
+			t.Fatalf("The move %v of game %v returns an error %v", move, g.Name, err)
+		}
+	}
+}
+
+// CheckGame checks that the game read is the game played and that its players are the players read
+func CheckGame(t *testing.T, games map[string]*scopone.Game, players map[string]*player.Player, played *scopone.Game) {
+	t.Helper()
+	read, found := games[played.Name]
+	if !found {
+		t.Fatalf("Game %v should be read", played.Name)
+	}
+	if read.State != played.State || read.Variant != played.Variant || read.TargetScore != played.TargetScore ||
+		!reflect.DeepEqual(read.Score, played.Score) {
+		t.Errorf("Game %v read has state %v, variant %v, target %v and score %v but the game played %v, %v, %v and %v",
+			played.Name, read.State, read.Variant, read.TargetScore, read.Score, played.State, played.Variant,
+			played.TargetScore, played.Score)
+	}
+	if read.MoveTimeLimit != played.MoveTimeLimit {
+		t.Errorf("Game %v read has move time limit %v but the game played %v", played.Name, read.MoveTimeLimit,
+			played.MoveTimeLimit)
+	}
+	if read.ObserverMode != played.ObserverMode || read.ObserverDelay != played.ObserverDelay {
+		t.Errorf("Game %v read has observer mode %v with delay %v but the game played %v with delay %v", played.Name,
+			read.ObserverMode, read.ObserverDelay, played.ObserverMode, played.ObserverDelay)
+	}
+	if read.NoTeamChatInHands != played.NoTeamChatInHands {
+		t.Errorf("Game %v read has no team chat in the hands %v but the game played %v", played.Name,
+			read.NoTeamChatInHands, played.NoTeamChatInHands)
+	}
+	if read.Private != played.Private || !bytes.Equal(read.PasswordHash, played.PasswordHash) ||
+		!reflect.DeepEqual(sortedNames(read.Invited), sortedNames(played.Invited)) {
+		t.Errorf("Game %v read is private %v with password hash %q and players invited %v but the game played %v, %q and %v",
+			played.Name, read.Private, read.PasswordHash, read.Invited, played.Private, played.PasswordHash, played.Invited)
+	}
+	if !reflect.DeepEqual(read.Seats(), played.Seats()) {
+		t.Errorf("Game %v read has seats %v but the game played %v", played.Name, read.Seats(), played.Seats())
+	}
+	if len(read.SeatsLeft) != len(played.SeatsLeft) || len(read.AbandonVotes) != len(played.AbandonVotes) {
+		t.Errorf("Game %v read has seats left %v and votes to abandon %v but the game played %v and %v", played.Name,
+			read.SeatsLeft, read.AbandonVotes, played.SeatsLeft, played.AbandonVotes)
+	}
+	for pName := range played.Players {
+		if read.SeatsLeft[pName] != played.SeatsLeft[pName] || read.AbandonVotes[pName] != played.AbandonVotes[pName] {
+			t.Errorf("Player %v of game %v read has left the seat %v and voted to abandon %v but in the game played %v and %v",
+				pName, played.Name, read.SeatsLeft[pName], read.AbandonVotes[pName], played.SeatsLeft[pName],
+				played.AbandonVotes[pName])
+		}
+	}
+	if read.AbandonedBy != played.AbandonedBy || !reflect.DeepEqual(read.Winners, played.Winners) {
+		t.Errorf("Game %v read is abandoned by %v with winners %v but the game played by %v with winners %v",
+			played.Name, read.AbandonedBy, read.Winners, played.AbandonedBy, played.Winners)
+	}
+	for pName := range played.Players {
+		if read.IsReady(pName) != played.IsReady(pName) {
+			t.Errorf("Player %v of game %v read is ready %v but in the game played %v", pName, played.Name,
+				read.IsReady(pName), played.IsReady(pName))
+		}
+	}
+	if len(read.Hands) != len(played.Hands) {
+		t.Fatalf("Game %v read has %v hands but the game played %v", played.Name, len(read.Hands), len(played.Hands))
+	}
+	for i, h := range played.Hands {
+		r := read.Hands[i]
+		if r.State != h.State || !reflect.DeepEqual(r.Table, h.Table) || r.CurrentPlayer.Name != h.CurrentPlayer.Name {
+			t.Errorf("Hand %v of game %v read has state %v, table %v and current player %v but the hand played %v, %v and %v",
+				i, played.Name, r.State, r.Table, r.CurrentPlayer.Name, h.State, h.Table, h.CurrentPlayer.Name)
+		}
+	}
+	if len(read.Players) != len(played.Players) {
+		t.Errorf("Game %v read has %v players but the game played %v", played.Name, len(read.Players), len(played.Players))
+	}
+	for pName, p := range played.Players {
+		r, found := read.Players[pName]
+		if !found {
+			t.Errorf("Player %v of game %v should be read", pName, played.Name)
+			continue
+		}
+		if !reflect.DeepEqual(r.Cards, p.Cards) || r.Bot != p.Bot {
+			t.Errorf("Player %v of game %v read has cards %v and bot %v but the player played %v and %v", pName,
+				played.Name, r.Cards, r.Bot, p.Cards, p.Bot)
+		}
+	}
+	checkPlayers(t, read, players)
+}
+
+// checkPlayers checks that the players of the teams and the observers of the game read are the same instances of the
+// players read and that they have all left the Osteria
+func checkPlayers(t *testing.T, g *scopone.Game, players map[string]*player.Player) {
+	t.Helper()
+	seated := 0
+	for _, tm := range g.Teams {
+		for _, p := range tm.Players {
+			// a seat not taken yet is nil
+			if p == nil {
+				continue
+			}
+			seated++
+			if p != g.Players[p.Name] || p != players[p.Name] {
+				t.Errorf("Player %v of the teams of game %v should be the same instance of the players of the game and of the players read",
+					p.Name, g.Name)
+			}
+			if p.Status != player.PlayerLeftOsteria {
+				t.Errorf("Player %v of game %v read should have left the Osteria but has status %v", p.Name, g.Name, p.Status)
+			}
+		}
+	}
+	if seated != len(g.Players) {
+		t.Errorf("Game %v read has %v players but %v of them are seated in the teams", g.Name, len(g.Players), seated)
+	}
+	for oName, o := range g.Observers {
+		if o != players[oName] {
+			t.Errorf("Observer %v of game %v should be the same instance of the players read", oName, g.Name)
+		}
+		if o.Status != player.PlayerLeftOsteria {
+			t.Errorf("Observer %v of game %v read should have left the Osteria but has status %v", oName, g.Name, o.Status)
+		}
+	}
+}
+
+// sortedNames returns the names sorted, since the stores may read the players invited in another order
+func sortedNames(names []string) []string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func testGameMidHand(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	g1 := NewGameOfBots(t, s, "game 1", scopone.GameOptions{TargetScore: 21, Seed: 1, MoveTimeLimit: 30,
+		ObserverMode: scopone.ObserveDelayed, ObserverDelay: 2, NoTeamChatInHands: true}, 4)
+	g2 := NewGameOfBots(t, s, "game/2", scopone.GameOptions{NumberOfPlayers: 3, Variant: scopone.Scopa, Seed: 3}, 3)
+	// the first game is in its second hand
+	PlayCards(t, s, g1, 45)
+	PlayCards(t, s, g2, 7)
+
+	games, players := ReadOpenGames(t, open)
+	if len(games) != 2 {
+		t.Errorf("2 games should be read but %v are read", len(games))
+	}
+	CheckGame(t, games, players, g1)
+	CheckGame(t, games, players, g2)
+}
+
+func testGameWaitingForPlayers(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 1)
+	s.PlayerEnters(context.Background(), "p1", "")
+	if err := s.AddPlayerToGame(context.Background(), "p1", "game"); err != nil {
+		t.Fatalf("p1 could not join the game: %v", err)
+	}
+	empty := NewGameOfBots(t, s, "empty game", scopone.GameOptions{}, 0)
+	private := NewGameOfBots(t, s, "private game", scopone.GameOptions{Password: "secret", Invited: []string{"p2", "p1"}}, 2)
+
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+	CheckGame(t, games, players, empty)
+	CheckGame(t, games, players, private)
+	if err := games["private game"].Admits("p3", "secret"); err != nil {
+		t.Errorf("The private game read should admit the players who know its password but the error is %v", err)
+	}
+}
+
+func testClosedGames(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "closed game", scopone.GameOptions{}, 4)
+	PlayCards(t, s, g, 5)
+	if err := s.Close(context.Background(), "closed game", "Bot 1"); err != nil {
+		t.Fatalf("The game could not be closed: %v", err)
+	}
+	open1 := NewGameOfBots(t, s, "open game", scopone.GameOptions{}, 2)
+
+	games, players := ReadOpenGames(t, open)
+	if _, found := games["closed game"]; found {
+		t.Errorf("A closed game should not be read")
+	}
+	CheckGame(t, games, players, open1)
+
+	// a game can be created with the name of a game which has been closed
+	s = Osteria(t, open)
+	reused := NewGameOfBots(t, s, "closed game", scopone.GameOptions{NumberOfPlayers: 2, Variant: scopone.Scopa}, 2)
+	PlayCards(t, s, reused, 3)
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, reused)
+}
+
+func testObservers(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 4)
+	for _, oName := range []string{"o1", "o2"} {
+		s.PlayerEnters(context.Background(), oName, "")
+		if _, err := s.AddObserverToGame(context.Background(), oName, "game"); err != nil {
+			t.Fatalf("%v could not observe the game: %v", oName, err)
+		}
+	}
+	PlayCards(t, s, g, 3)
+
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+	read := games["game"]
+	if len(read.Observers) != 2 || read.Observers["o1"] == nil || read.Observers["o2"] == nil {
+		t.Errorf("The observers o1 and o2 should be read but the observers read are %v", read.Observers)
+	}
+}
+
+func testPlayerEntries(t *testing.T, open Opener) {
+	store := open(t)
+	for _, pName := range []string{"p1", "p2", "p1"} {
+		if err := store.AddPlayerEntry(context.Background(), player.New(pName)); err != nil {
+			t.Errorf("The entry of %v could not be added: %v", pName, err)
+		}
+	}
+	// the players who are not in any game are not read
+	_, players := ReadOpenGames(t, open)
+	if len(players) != 0 {
+		t.Errorf("No player should be read but %v are read", len(players))
+	}
+}
+
+func testGameReadIsPlayedOn(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 4)
+	PlayCards(t, s, g, 6)
+
+	s = Osteria(t, open)
+	g = s.Games["game"]
+	if g == nil {
+		t.Fatalf("The game should be read")
+	}
+	// the game read goes on to its next hand
+	PlayCards(t, s, g, 40)
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+}
+
+// playUntilTurnOf makes the bots of the game play until it is the turn of the player passed in
+func playUntilTurnOf(t *testing.T, s *scopone.Scopone, g *scopone.Game, pName string) {
+	for s.IsBotTurn(g) {
+		if _, move, _, _, err := s.PlayBot(context.Background(), g); err != nil {
+			t.Fatalf("The move %v of game %v returns an error %v", move, g.Name, err)
+		}
+	}
+	if current := g.Hands[len(g.Hands)-1].CurrentPlayer.Name; current != pName {
+		t.Fatalf("It should be the turn of %v but it is the turn of %v", pName, current)
+	}
+}
+
+// playFirstLegalMove makes the player passed in play the first of its legal moves
+func playFirstLegalMove(t *testing.T, s *scopone.Scopone, g *scopone.Game, pName string) {
+	move := s.CurrentHandViews(g)[pName].LegalMoves[0]
+	if _, _, err := s.Play(context.Background(), g, pName, move.CardPlayed, move.CardsTaken); err != nil {
+		t.Fatalf("%v could not play %v: %v", pName, move, err)
+	}
+}
+
+func testSeatTaken(t *testing.T, open Opener) {
+	s := Osteria(t, open)
+	// the seats left are taken at once
+	s.ReconnectGracePeriod = 0
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 3)
+	for _, pName := range []string{"p1", "p2"} {
+		s.PlayerEnters(context.Background(), pName, "")
+	}
+	if err := s.AddPlayerToGame(context.Background(), "p1", "game"); err != nil {
+		t.Fatalf("p1 could not join the game: %v", err)
+	}
+	s.PlayerReady(context.Background(), "p1", "game")
+	if _, _, err := s.NewHand(context.Background(), g); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	playUntilTurnOf(t, s, g, "p1")
+	playFirstLegalMove(t, s, g, "p1")
+	s.RemovePlayer("p1")
+	if err := s.AddPlayerToGame(context.Background(), "p2", "game"); err != nil {
+		t.Fatalf("p2 could not take the seat of p1: %v", err)
+	}
+	playUntilTurnOf(t, s, g, "p2")
+
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+	if _, found := games["game"].Players["p1"]; found {
+		t.Errorf("p1, whose seat has been taken, should not be a player of the game read")
+	}
+
+	// the game read is played on by the player who has taken the seat
+	s = Osteria(t, open)
+	g = s.Games["game"]
+	s.PlayerEnters(context.Background(), "p2", "")
+	playFirstLegalMove(t, s, g, "p2")
+	playUntilTurnOf(t, s, g, "p2")
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+}
+
+func testTeamsForming(t *testing.T, open Opener) {
+	ctx := context.Background()
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 2)
+	for _, pName := range []string{"p1", "p2", "p3"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	if err := s.AddPlayerToSeat(ctx, "p1", "game", 3); err != nil {
+		t.Fatalf("p1 could not join the game in seat 3: %v", err)
+	}
+	s.AddPlayerToGame(ctx, "p2", "game")
+	s.PlayerReady(ctx, "p1", "game")
+	s.PlayerReady(ctx, "p2", "game")
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+
+	// p1 moves to the seat of a bot and p2 kicks out the other bot, whose seat is taken by p3, so that p1 and p2
+	// have to confirm again to be ready
+	if err := s.SwapSeats(ctx, "game", "p1", 0); err != nil {
+		t.Fatalf("p1 could not move to seat 0: %v", err)
+	}
+	if _, err := s.KickFromSeat(ctx, "game", "p2", 1); err != nil {
+		t.Fatalf("p2 could not kick out the bot in seat 1: %v", err)
+	}
+	s.AddPlayerToSeat(ctx, "p3", "game", 1)
+	s.PlayerReady(ctx, "p3", "game")
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+
+	// the game read starts when all the players are ready again
+	s = Osteria(t, open)
+	g = s.Games["game"]
+	for _, pName := range []string{"p1", "p2", "p3"} {
+		s.PlayerEnters(ctx, pName, "")
+	}
+	if _, _, err := s.NewHand(ctx, g); !errors.Is(err, scopone.ErrPlayersNotReady) {
+		t.Errorf("The first hand of the game read should wait for p1 and p2 to be ready but the error is %v", err)
+	}
+	s.PlayerReady(ctx, "p1", "game")
+	s.PlayerReady(ctx, "p2", "game")
+	if _, _, err := s.NewHand(ctx, g); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+}
+
+func testSeatLeftAndAbandon(t *testing.T, open Opener) {
+	ctx := context.Background()
+	s := Osteria(t, open)
+	g := NewGameOfBots(t, s, "game", scopone.GameOptions{}, 2)
+	for _, pName := range []string{"p1", "p2"} {
+		s.PlayerEnters(ctx, pName, "")
+		s.AddPlayerToGame(ctx, pName, "game")
+		s.PlayerReady(ctx, pName, "game")
+	}
+	if _, _, err := s.NewHand(ctx, g); err != nil {
+		t.Fatalf("The hand could not be started: %v", err)
+	}
+	playUntilTurnOf(t, s, g, "p1")
+	if err := s.LeaveSeat(ctx, "game", "p2"); err != nil {
+		t.Fatalf("p2 could not leave the seat: %v", err)
+	}
+	// the bots stop when it is the turn of the seat left
+	playFirstLegalMove(t, s, g, "p1")
+	playUntilTurnOf(t, s, g, "p2")
+	games, players := ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+
+	// p2 takes back the seat of the game read and then p1 and p2 abandon the game
+	s = Osteria(t, open)
+	g = s.Games["game"]
+	s.PlayerEnters(ctx, "p1", "")
+	s.PlayerEnters(ctx, "p2", "")
+	if err := s.AddPlayerToGame(ctx, "p2", "game"); err != nil {
+		t.Fatalf("p2 could not take back the seat left: %v", err)
+	}
+	playFirstLegalMove(t, s, g, "p2")
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+	for _, pName := range []string{"p1", "p2"} {
+		if _, err := s.VoteToAbandon(ctx, "game", pName); err != nil {
+			t.Fatalf("%v could not vote to abandon the game: %v", pName, err)
+		}
+	}
+	if g.State != scopone.GameFinished || g.AbandonedBy == "" {
+		t.Fatalf("The game should be abandoned but is %v", g.State)
+	}
+	games, players = ReadOpenGames(t, open)
+	CheckGame(t, games, players, g)
+}